@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/yourname/cleanhttp/internal/config"
 	"github.com/yourname/cleanhttp/internal/httpserver"
@@ -17,13 +22,30 @@ func main() {
 	}
 
 	addr := ":" + cfg.Port
-	h := httpserver.New(cfg)
+	h, closeDeps := httpserver.New(cfg)
+	defer closeDeps()
+	srv := &http.Server{Addr: addr, Handler: h}
+
+	// SIGINT/SIGTERM — дожидаемся текущих запросов, потом закрываем зависимости.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), cfg.RequestTimeout+5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(sctx); err != nil {
+			slog.Error("server shutdown", slog.String("error", err.Error()))
+		}
+	}()
+
 	slog.Info("starting http server", slog.String("addr", addr), slog.String("env", string(cfg.Env)), slog.Duration("timeout", cfg.RequestTimeout))
-	// Стартуем блокирующе. Если сервер завершился с ошибкой — фатал.
-	// (Позже заменим на http.Server + Shutdown(ctx) для graceful).
-	if err := http.ListenAndServe(addr, h); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slog.Error("server stopped", slog.String("error", err.Error()))
+		closeDeps()
 		os.Exit(1)
 	}
-
+	<-done
+	slog.Info("server stopped")
 }
@@ -4,7 +4,9 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
@@ -16,13 +18,17 @@ import (
 	"github.com/hibiken/asynq"
 
 	"github.com/yourname/cleanhttp/internal/artifacts"
+	"github.com/yourname/cleanhttp/internal/auth"
 	"github.com/yourname/cleanhttp/internal/config"
 	"github.com/yourname/cleanhttp/internal/exporter"
+	"github.com/yourname/cleanhttp/internal/filters"
 	"github.com/yourname/cleanhttp/internal/githubclient"
 	"github.com/yourname/cleanhttp/internal/jobs"
 	"github.com/yourname/cleanhttp/internal/secrets"
 	"github.com/yourname/cleanhttp/internal/store"
 	"github.com/yourname/cleanhttp/internal/storepg"
+	"github.com/yourname/cleanhttp/internal/submodules"
+	"github.com/yourname/cleanhttp/internal/treecache"
 )
 
 func env(key, def string) string {
@@ -34,19 +40,31 @@ func env(key, def string) string {
 
 type exportPayload struct {
 	ExportID        string   `json:"exportId"`
+	UserID          string   `json:"userId"` // токен берём из БД по ID — в Redis его нет
 	Owner           string   `json:"owner"`
 	Repo            string   `json:"repo"`
 	Ref             string   `json:"ref"`
-	Format          string   `json:"format"`  // zip | txt | promptpack
+	Format          string   `json:"format"`  // zip | txt | promptpack | pr
 	Profile         string   `json:"profile"` // для promptpack
 	IncludeGlobs    []string `json:"includeGlobs"`
 	ExcludeGlobs    []string `json:"excludeGlobs"`
 	SecretScan      bool     `json:"secretScan"`
-	SecretStrategy  string   `json:"secretStrategy"` // redacted|strip|mark
+	SecretStrategy  string   `json:"secretStrategy"` // redacted|strip|mark|pseudonymize
+	DropSecretFiles bool     `json:"dropSecretFiles"`
+	PIIScan         bool     `json:"piiScan"`
+	SecretBaseline  string   `json:"secretBaseline"` // отпечатки принятых находок (провалидировано в API)
+	NoRepoIgnore    bool     `json:"noRepoIgnore"`
+	SkipGenerated   bool     `json:"skipGenerated"`
 	TokenModel      string   `json:"tokenModel"`
 	MaxBinarySizeMB int      `json:"maxBinarySizeMB"`
 	TTLHours        int      `json:"ttlHours"`
 	IdempotencyKey  string   `json:"idempotencyKey"`
+	RootPath        string   `json:"rootPath"`          // только поддерево (провалидировано в API)
+	Submodules      bool     `json:"includeSubmodules"` // докачать сабмодули с GitHub и вклеить по их путям
+	ResolveLFS      bool     `json:"resolveLfs"`        // скачать объекты Git LFS вместо pointer'ов
+	PR              int      `json:"pr"`                // номер PR для format=pr
+	DiffBase        string   `json:"diffBase"`          // diff-режим: только изменённое в diffBase...ref
+	DiffHunks       bool     `json:"diffHunks"`         // в diff-режиме — с unified diff
 }
 
 func main() {
@@ -72,6 +90,30 @@ func main() {
 
 	gh := githubclient.New(cfg)
 
+	// правила .gitignore по SHA коммита — в том же кэше, что и деревья у API
+	var rulesCache treecache.TreeCache
+	switch cfg.TreeCacheBackend {
+	case "redis":
+		rc := treecache.NewRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.TreeCacheTTL)
+		defer rc.Close()
+		rulesCache = rc
+	default:
+		rulesCache = treecache.NewMemory(cfg.TreeCacheTTL, cfg.TreeCacheMaxItems)
+	}
+
+	// токены пользователей (экспорт от имени вошедшего через GitHub OAuth)
+	users := pg.Users()
+	var tokenCipher *auth.Cipher
+	if cfg.AuthEncryptionKey != "" {
+		key, err := auth.ParseKey(cfg.AuthEncryptionKey)
+		if err == nil {
+			tokenCipher, err = auth.NewCipher(key)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	var artStore artifacts.ArtifactsStore
 	switch strings.ToLower(cfg.ArtifactsBackend) {
 	case "s3":
@@ -161,9 +203,86 @@ func main() {
 		)
 		jobLog.Info("export task received")
 
-		// 1) скачать tarball из GitHub
+		// 0) экспорт пользователя — его токен (приватные репозитории, его лимит)
+		if p.UserID != "" {
+			var tok string
+			err := errors.New("AUTH_ENCRYPTION_KEY is not configured")
+			if tokenCipher != nil {
+				_, tok, err = auth.UserToken(ctx, users, tokenCipher, p.UserID)
+			}
+			if err != nil {
+				msg := "GitHub authorization is unavailable, sign in again"
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("user token lookup failed", slog.String("user_id", p.UserID), slog.Any("error", err))
+				return nil
+			}
+			ctx = githubclient.WithToken(ctx, tok)
+		}
+
+		// 1) скачать tarball из GitHub. Архива поддерева API не отдаёт — при rootPath
+		// качаем весь, а файлы вне поддерева билдеры пропускают, не разбирая
 		dctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
-		rc, err := gh.GetTarball(dctx, owner, repo, ref)
+		// PR pack: сначала сам PR — архив берём по head-коммиту, а не по ref
+		var (
+			pull         githubclient.PullRequest
+			pullFiles    []githubclient.PullFile
+			pullComments []githubclient.PullComment
+			err          error
+		)
+		if format == "pr" {
+			pull, pullFiles, pullComments, err = fetchPull(dctx, gh, owner, repo, p.PR)
+			if err != nil {
+				cancel()
+				msg := friendlyGhError(err, owner, repo, fmt.Sprintf("#%d", p.PR))
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("github pull request fetch failed", slog.Int("pr", p.PR), slog.Any("error", err))
+				if retryableGhError(err) {
+					return err
+				}
+				return nil
+			}
+			ref = pull.Head.SHA
+		}
+		// ref → SHA один раз: compare, архив и повторные проходы (promptpack, PR pack)
+		// должны видеть один и тот же коммит, даже если ветка сдвинется посреди экспорта
+		commit := ref
+		if !githubclient.IsCommitSHA(ref) {
+			cr, _, err := gh.ResolveCommit(dctx, owner, repo, ref, "")
+			if err != nil {
+				cancel()
+				msg := friendlyGhError(err, owner, repo, ref)
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("github ref resolve failed", slog.Any("error", err))
+				if retryableGhError(err) {
+					return err
+				}
+				return nil
+			}
+			commit = cr.SHA
+		}
+		// diff-режим: список изменённых файлов до скачивания архива
+		var changes *exporter.ChangeSet
+		if p.DiffBase != "" {
+			cmp, err := gh.CompareRefs(dctx, owner, repo, p.DiffBase, commit)
+			if err != nil {
+				cancel()
+				msg := friendlyGhError(err, owner, repo, p.DiffBase+"..."+ref)
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("github compare failed", slog.String("base", p.DiffBase), slog.Any("error", err))
+				if retryableGhError(err) {
+					return err
+				}
+				return nil
+			}
+			changes = &exporter.ChangeSet{
+				Base:      p.DiffBase,
+				Head:      ref,
+				Files:     cmp.Files,
+				Hunks:     p.DiffHunks,
+				Truncated: cmp.Truncated(),
+			}
+		}
+		rc, err := gh.GetTarball(dctx, owner, repo, commit)
 		if err != nil {
 			cancel()
 		} else {
@@ -177,13 +296,35 @@ func main() {
 			jobLog.Error("github tarball download failed", slog.Any("error", err))
 
 			// 429/апстрим — возвращаем ошибку, чтобы asynq ретраил
-			if _, ok := err.(*githubclient.RateLimitedError); ok || err == githubclient.ErrUpstream {
+			if retryableGhError(err) {
 				return err
 			}
 			// остальные ошибки без ретраев
 			return nil
 		}
+		// сабмодулей в tarball нет — резолвим gitlink'и и вклеиваем их архивы в поток.
+		// Merge — на контексте задачи, а не dctx: у каждого сабмодуля свой
+		// таймаут (submodules.DefaultModuleTimeout), общий бюджет скачивания им не делим
+		var subReport *submodules.Report
+		sr := &submodules.Resolver{GH: gh}
+		if p.Submodules {
+			if subReport, err = sr.Resolve(dctx, owner, repo, commit); err != nil {
+				jobLog.Warn("submodules resolve failed, exporting without them", slog.Any("error", err))
+			} else {
+				rc = sr.Merge(ctx, rc, subReport)
+			}
+		}
 		defer rc.Close()
+		// reopen — тот же архив ещё раз (второй проход promptpack): с теми же
+		// сабмодулями, но отчёт первого прохода не трогаем
+		reopen := func() (io.ReadCloser, error) {
+			rc2, err := gh.GetTarball(dctx, owner, repo, commit)
+			if err != nil || subReport == nil {
+				return rc2, err
+			}
+			again := &submodules.Report{Included: append([]submodules.Module(nil), subReport.Included...)}
+			return sr.Merge(ctx, rc2, again), nil
+		}
 
 		// 2) собрать артефакт
 		var (
@@ -191,6 +332,34 @@ func main() {
 			meta    artifacts.ArtifactMeta
 			outName string
 		)
+		// baseline уже провалидирован в API — здесь ошибки не ждём
+		baseline, _ := secrets.ParseBaseline(strings.NewReader(p.SecretBaseline))
+		skipped := exporter.NewSkippedFiles()
+		kinds := exporter.FileKinds{}
+		// pointer'ы Git LFS помечаем всегда; объекты качаем по запросу (текст, до EXPORT_LFS_MAX_MB)
+		lfsFiles := exporter.NewLFSFiles()
+		// правила репозитория — заранее по дереву: в tar часть файлов идёт
+		// раньше своего .gitignore; не вышло — билдер соберёт их по ходу чтения
+		var repoRules *filters.RepoIgnore
+		if !p.NoRepoIgnore {
+			if repoRules, err = exporter.FetchRepoRules(dctx, gh, rulesCache, owner, repo, commit); err != nil {
+				jobLog.Warn("repo ignore rules prefetch failed", slog.Any("error", err))
+			}
+		}
+		// отчёт экспорта едет и в самом пакете, не только в метаданных артефакта
+		manifest := &exporter.Manifest{Skipped: skipped, Kinds: kinds, LFS: lfsFiles}
+		if subReport != nil {
+			manifest.Submodules = subReport
+		}
+		var lfs *exporter.LFSResolver
+		if p.ResolveLFS {
+			lfs = &exporter.LFSResolver{
+				Fetch: func(oid string, size int64) (io.ReadCloser, error) {
+					return gh.OpenLFSObject(dctx, owner, repo, oid, size)
+				},
+				MaxBytes: cfg.LFSMaxBytes,
+			}
+		}
 
 		switch format {
 		case "zip":
@@ -209,6 +378,20 @@ func main() {
 				MaxExportMB:     200,
 				MaxFilenameLen:  255,
 				StripFirstDir:   true,
+				RootPath:        p.RootPath,
+				SecretScan:      p.SecretScan,
+				SecretStrategy:  secrets.ParseStrategy(p.SecretStrategy),
+				DropSecretFiles: p.DropSecretFiles,
+				PIIScan:         p.PIIScan,
+				SecretBaseline:  baseline,
+				UseRepoIgnore:   !p.NoRepoIgnore,
+				RepoRules:       repoRules,
+				SkipGenerated:   p.SkipGenerated,
+				Skipped:         skipped,
+				Kinds:           kinds,
+				LFS:             lfs,
+				LFSFiles:        lfsFiles,
+				Manifest:        manifest,
 			}
 			if err := exporter.BuildZipFromTarGz(rc, aw, opts); err != nil {
 				_ = aw.Close()
@@ -227,24 +410,29 @@ func main() {
 				jobLog.Error("create txt artifact failed", slog.Any("error", err))
 				return nil
 			}
-			strat := secrets.StrategyRedacted
-			switch strings.ToLower(p.SecretStrategy) {
-			case "strip":
-				strat = secrets.StrategyStrip
-			case "mark":
-				strat = secrets.StrategyMark
-			}
 			topts := exporter.TxtOptions{
 				IncludeGlobs:    p.IncludeGlobs,
 				ExcludeGlobs:    p.ExcludeGlobs,
 				StripFirstDir:   true,
+				RootPath:        p.RootPath,
 				LineNumbers:     true,
 				HeaderTemplate:  "=== FILE: {path} (first {n} lines) ===",
 				MaxLinesPerFile: 10000,
 				MaxExportMB:     200,
 				SkipBinaries:    true,
 				SecretScan:      p.SecretScan,
-				SecretStrategy:  strat,
+				SecretStrategy:  secrets.ParseStrategy(p.SecretStrategy),
+				PIIScan:         p.PIIScan,
+				SecretBaseline:  baseline,
+				UseRepoIgnore:   !p.NoRepoIgnore,
+				RepoRules:       repoRules,
+				SkipGenerated:   p.SkipGenerated,
+				Skipped:         skipped,
+				Kinds:           kinds,
+				LFS:             lfs,
+				LFSFiles:        lfsFiles,
+				Changes:         changes,
+				Manifest:        manifest,
 			}
 			if err := exporter.BuildTxtFromTarGz(rc, aw, topts); err != nil {
 				_ = aw.Close()
@@ -273,13 +461,60 @@ func main() {
 				ExcludeGlobs:    p.ExcludeGlobs,
 				MaxLinesPerFile: 0,
 				MaskSecrets:     p.SecretScan,
+				MaskPII:         p.PIIScan,
+				SecretBaseline:  baseline,
+				UseRepoIgnore:   !p.NoRepoIgnore,
+				RepoRules:       repoRules,
+				SkipGenerated:   p.SkipGenerated,
+				Skipped:         skipped,
+				Kinds:           kinds,
+				SecretStrategy:  secrets.ParseStrategy(p.SecretStrategy),
 				StripFirstDir:   true,
+				RootPath:        p.RootPath,
+				LFSFiles:        lfsFiles,
+				Changes:         changes,
+				Manifest:        manifest,
 			}
-			if err := exporter.BuildPromptPackFromTarGz(rc, aw, pp); err != nil {
+			if err := buildPromptPack(rc, reopen, aw, pp); err != nil {
 				_ = aw.Close()
 				msg := err.Error()
 				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
 				jobLog.Error("build promptpack artifact failed", slog.Any("error", err))
+				if retryableGhError(err) {
+					return err
+				}
+				return nil
+			}
+
+		case "pr":
+			outName = fmt.Sprintf("pr-%d.zip", p.PR)
+			aw, meta, err = artStore.CreateArtifact(p.ExportID, "zip", outName)
+			if err != nil {
+				msg := err.Error()
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("create pr pack artifact failed", slog.Any("error", err))
+				return nil
+			}
+			prOpts := exporter.PRPackOptions{
+				Owner:          owner,
+				Repo:           repo,
+				PR:             pull,
+				Files:          pullFiles,
+				Comments:       pullComments,
+				ModelID:        p.TokenModel,
+				MaskSecrets:    p.SecretScan,
+				MaskPII:        p.PIIScan,
+				SecretStrategy: secrets.ParseStrategy(p.SecretStrategy),
+				SecretBaseline: baseline,
+				StripFirstDir:  true,
+				// контекст (импорты изменённых файлов) — вторым проходом по тому же коммиту
+				Reopen: reopen,
+			}
+			if err := exporter.BuildPRPackFromTarGz(rc, aw, prOpts); err != nil {
+				_ = aw.Close()
+				msg := err.Error()
+				expStore.UpdateStatus(p.ExportID, jobs.StatusError, 0, &msg)
+				jobLog.Error("build pr pack artifact failed", slog.Any("error", err))
 				return nil
 			}
 
@@ -307,6 +542,10 @@ func main() {
 			Size:        meta.Size,
 			ID:          meta.ID,
 			Kind:        meta.Kind,
+			Meta:        map[string]any{"skipped": skipped, "kinds": kinds, "lfs": lfsFiles},
+		}
+		if subReport != nil {
+			art.Meta["submodules"] = subReport
 		}
 		expStore.AddArtifact(p.ExportID, art)
 
@@ -333,13 +572,53 @@ func contentTypeFor(format string) string {
 	switch strings.ToLower(format) {
 	case "txt":
 		return "text/plain; charset=utf-8"
-	case "promptpack", "zip":
+	case "promptpack", "zip", "pr":
 		return "application/zip"
 	default:
 		return "application/octet-stream"
 	}
 }
 
+// buildPromptPack — promptpack в два прохода: первый собирает разделы,
+// второй (по заново открытому архиву) — врезки файлов.
+func buildPromptPack(src io.Reader, reopen func() (io.ReadCloser, error), dst io.Writer, opts exporter.PromptPackOptions) error {
+	err := exporter.BuildPromptPackFromTarGz(src, dst, opts)
+	var need *exporter.NeedSecondPassError
+	if !errors.As(err, &need) {
+		return err
+	}
+	rc, err := reopen()
+	if err != nil {
+		return fmt.Errorf("promptpack second pass: %w", err)
+	}
+	defer rc.Close()
+	return exporter.FillSecondPassExcerpts(rc, need)
+}
+
+// fetchPull — PR, его файлы и обсуждение для PR pack.
+func fetchPull(ctx context.Context, gh *githubclient.Client, owner, repo string, number int) (githubclient.PullRequest, []githubclient.PullFile, []githubclient.PullComment, error) {
+	pull, err := gh.GetPullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return pull, nil, nil, err
+	}
+	files, err := gh.GetPullFiles(ctx, owner, repo, number)
+	if err != nil {
+		return pull, nil, nil, err
+	}
+	comments, err := gh.GetPullComments(ctx, owner, repo, number)
+	if err != nil {
+		return pull, nil, nil, err
+	}
+	return pull, files, comments, nil
+}
+
+// retryableGhError — 429/вторичный лимит/апстрим: отдаём ошибку asynq, пусть ретраит.
+func retryableGhError(err error) bool {
+	var rl *githubclient.RateLimitedError
+	var sl *githubclient.SecondaryRateLimitError
+	return errors.As(err, &rl) || errors.As(err, &sl) || errors.Is(err, githubclient.ErrUpstream)
+}
+
 // normalizeRef: "refs/heads/main" → "main", пусто → "HEAD"
 func normalizeRef(ref string) string {
 	r := strings.TrimSpace(ref)
@@ -364,8 +643,17 @@ func normalizeFormat(format string) string {
 
 // Преобразуем ошибки GitHub в дружелюбные сообщения на RU
 func friendlyGhError(err error, owner, repo, ref string) string {
-	// если в твоём githubclient есть типы ошибок — используй их.
-	// Здесь просто текстом:
+	var sl *githubclient.SecondaryRateLimitError
+	var fe *githubclient.ForbiddenError
+	switch {
+	case errors.Is(err, githubclient.ErrNotFound):
+		return fmt.Sprintf("Ресурс не найден: %s/%s@%s", owner, repo, ref)
+	case errors.As(err, &sl):
+		return "GitHub временно ограничил частоту запросов, повторим позже"
+	case errors.As(err, &fe):
+		return "Нет доступа к репозиторию: " + fe.Message
+	}
+	// прочее — по тексту ошибки
 	e := strings.ToLower(err.Error())
 	switch {
 	case strings.Contains(e, "404"), strings.Contains(e, "not found"):
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/exporter"
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func tarGz(files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for p, content := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: "repo-sha/" + p, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// Воркерный путь promptpack: первый проход всегда просит второй, и он должен
+// пройти по заново открытому архиву, а не уронить задачу.
+func TestBuildPromptPack_DiffModeSecondPass(t *testing.T) {
+	src := tarGz(map[string]string{
+		"app/main.go":   "package main\n\nfunc main() { run() }\n",
+		"app/unused.go": "package main\n",
+		"README.md":     "# demo\n",
+	})
+	reopened := 0
+	reopen := func() (io.ReadCloser, error) {
+		reopened++
+		return io.NopCloser(bytes.NewReader(src)), nil
+	}
+	opts := exporter.PromptPackOptions{
+		Owner: "o", Repo: "r", Ref: "abc123",
+		StripFirstDir: true,
+		RootPath:      "app",
+		Changes: &exporter.ChangeSet{
+			Base: "v1.0.0", Head: "abc123", Hunks: true,
+			Files: []githubclient.PullFile{
+				{Filename: "app/main.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -3 +3 @@\n-func main() {}\n+func main() { run() }"},
+			},
+		},
+	}
+	var out bytes.Buffer
+	if err := buildPromptPack(bytes.NewReader(src), reopen, &out, opts); err != nil {
+		t.Fatalf("build promptpack: %v", err)
+	}
+	if reopened != 1 {
+		t.Fatalf("second pass reopened the archive %d times", reopened)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	var all strings.Builder
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		_, _ = io.Copy(&all, rc)
+		_ = rc.Close()
+	}
+	text := all.String()
+	for _, want := range []string{"main.go", "#### DIFF", "+func main() { run() }"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("no %q in pack:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "unused.go") {
+		t.Fatalf("unchanged file in diff-mode pack:\n%s", text)
+	}
+}
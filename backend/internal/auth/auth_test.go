@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+func testCipher(t *testing.T) *Cipher {
+	t.Helper()
+	key, err := ParseKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := testCipher(t)
+	sealed, err := c.Seal([]byte("gho_secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("gho_secret")) {
+		t.Fatal("token stored in plain text")
+	}
+	plain, err := c.Open(sealed)
+	if err != nil || string(plain) != "gho_secret" {
+		t.Fatalf("open = %q, %v", plain, err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.Open(sealed); err == nil {
+		t.Error("tampered ciphertext must fail")
+	}
+}
+
+func TestSessionsRejectTamperedAndExpired(t *testing.T) {
+	s := &Sessions{Cipher: testCipher(t), TTL: time.Hour}
+	rec := httptest.NewRecorder()
+	s.Issue(rec, "gh:1", 0)
+	cookie := rec.Result().Cookies()[0]
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	if id, _, ok := s.UserID(req); !ok || id != "gh:1" {
+		t.Fatalf("UserID = %q, %v", id, ok)
+	}
+
+	// подменили пользователя — подпись не сходится
+	forged := *cookie
+	forged.Value = "Z2g6Mg" + cookie.Value[strings.IndexByte(cookie.Value, '.'):]
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&forged)
+	if _, _, ok := s.UserID(req); ok {
+		t.Error("forged cookie accepted")
+	}
+
+	expired := &Sessions{Cipher: s.Cipher, TTL: -time.Minute}
+	rec = httptest.NewRecorder()
+	expired.Issue(rec, "gh:1", 0)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(rec.Result().Cookies()[0])
+	if _, _, ok := s.UserID(req); ok {
+		t.Error("expired cookie accepted")
+	}
+}
+
+func TestMiddlewarePutsUserTokenInContext(t *testing.T) {
+	c := testCipher(t)
+	users := store.NewUsersMem()
+	enc, _ := c.Seal([]byte("gho_user"))
+	_ = users.PutUser(context.Background(), store.User{ID: "gh:7", Login: "octo", TokenEnc: enc})
+	s := &Sessions{Cipher: c, TTL: time.Hour}
+
+	var gotLogin, gotToken string
+	h := Middleware(s, users)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, _ := UserFromContext(r.Context())
+		gotLogin, gotToken = u.Login, githubclient.TokenFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	s.Issue(rec, "gh:7", 0)
+	cookie := rec.Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if gotLogin != "octo" || gotToken != "gho_user" {
+		t.Errorf("login=%q token=%q", gotLogin, gotToken)
+	}
+
+	// после выхода (RevokeSessions) та же cookie уже не пускает
+	_ = users.RevokeSessions(context.Background(), "gh:7")
+	gotLogin, gotToken = "", ""
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if gotLogin != "" || gotToken != "" {
+		t.Errorf("revoked session accepted: login=%q token=%q", gotLogin, gotToken)
+	}
+}
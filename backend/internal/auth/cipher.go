@@ -0,0 +1,73 @@
+// Package auth — вход через GitHub OAuth: шифрование токенов пользователей,
+// подписанная сессионная cookie и middleware, кладущий пользователя в контекст.
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Cipher — AES-256-GCM для токенов в БД. Ключ один на API и воркер (AUTH_ENCRYPTION_KEY).
+type Cipher struct {
+	aead   cipher.AEAD
+	macKey []byte // производный ключ для подписи cookie
+}
+
+// ParseKey — ключ из base64 (std или url, 32 байта).
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == 32 {
+			return b, nil
+		}
+	}
+	return nil, errors.New("encryption key must be 32 bytes in base64")
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("rep2prompt session"))
+	return &Cipher{aead: aead, macKey: mac.Sum(nil)}, nil
+}
+
+// Seal — nonce || ciphertext.
+func (c *Cipher) Seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (c *Cipher) Open(sealed []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return c.aead.Open(nil, sealed[:n], sealed[n:], nil)
+}
+
+// sign — HMAC-SHA256 для значений cookie.
+func (c *Cipher) sign(msg string) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+type userKey struct{}
+
+// UserFromContext — вошедший пользователь (ok=false — анонимный запрос).
+func UserFromContext(ctx context.Context) (store.User, bool) {
+	u, ok := ctx.Value(userKey{}).(store.User)
+	return u, ok
+}
+
+// WithUser — контекст с вошедшим пользователем (см. UserFromContext).
+func WithUser(ctx context.Context, u store.User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// UserToken — расшифрованный OAuth-токен пользователя.
+func UserToken(ctx context.Context, users store.UsersStore, c *Cipher, userID string) (store.User, string, error) {
+	u, err := users.GetUser(ctx, userID)
+	if err != nil {
+		return store.User{}, "", err
+	}
+	tok, err := c.Open(u.TokenEnc)
+	if err != nil {
+		return store.User{}, "", err
+	}
+	return u, string(tok), nil
+}
+
+// Middleware — по сессионной cookie кладёт в контекст пользователя и его токен
+// (githubclient.WithToken): все вызовы GitHub в хендлерах идут от его имени.
+// Без cookie, с битой или отозванной (старая SessionVersion) cookie запрос анонимный.
+func Middleware(s *Sessions, users store.UsersStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, version, ok := s.UserID(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			u, tok, err := UserToken(r.Context(), users, s.Cipher, id)
+			if err != nil {
+				slog.Warn("session user lookup failed", slog.String("user_id", id), slog.String("error", err.Error()))
+				next.ServeHTTP(w, r)
+				return
+			}
+			if u.SessionVersion != version {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := WithUser(r.Context(), u)
+			ctx = githubclient.WithToken(ctx, tok)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultScopes — repo нужен для приватных репозиториев, read:user — для профиля.
+const DefaultScopes = "repo read:user user:email"
+
+// HTTPDoer — как githubclient.HTTPDoer; отдельно, чтобы не тянуть клиент.
+type HTTPDoer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// GitHubOAuth — OAuth App (web flow): authorize → code → access token.
+type GitHubOAuth struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       string
+
+	WebURL string // https://github.com
+	APIURL string // https://api.github.com
+	Doer   HTTPDoer
+}
+
+// GitHubUser — профиль из GET /user.
+type GitHubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// AuthCodeURL — куда отправить браузер за согласием пользователя.
+func (o *GitHubOAuth) AuthCodeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", o.ClientID)
+	q.Set("redirect_uri", o.RedirectURL)
+	q.Set("scope", o.Scopes)
+	q.Set("state", state)
+	q.Set("allow_signup", "false")
+	return o.WebURL + "/login/oauth/authorize?" + q.Encode()
+}
+
+// Exchange — code → access token и выданные scopes.
+func (o *GitHubOAuth) Exchange(ctx context.Context, code string) (string, string, error) {
+	form := url.Values{}
+	form.Set("client_id", o.ClientID)
+	form.Set("client_secret", o.ClientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", o.RedirectURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.WebURL+"/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := o.Doer.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+	var out struct {
+		AccessToken string `json:"access_token"`
+		Scope       string `json:"scope"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return "", "", fmt.Errorf("oauth token response: %w", err)
+	}
+	// GitHub отвечает 200 и на ошибки — смотрим поле error
+	if out.Error != "" || out.AccessToken == "" {
+		return "", "", fmt.Errorf("oauth exchange failed: %s %s", out.Error, out.Description)
+	}
+	return out.AccessToken, out.Scope, nil
+}
+
+// FetchUser — профиль владельца токена.
+func (o *GitHubOAuth) FetchUser(ctx context.Context, token string) (GitHubUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.APIURL+"/user", nil)
+	if err != nil {
+		return GitHubUser{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := o.Doer.Do(req)
+	if err != nil {
+		return GitHubUser{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return GitHubUser{}, fmt.Errorf("github /user: unexpected status %d", res.StatusCode)
+	}
+	var u GitHubUser
+	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
+		return GitHubUser{}, err
+	}
+	if u.ID == 0 {
+		return GitHubUser{}, errors.New("github /user: empty id")
+	}
+	return u, nil
+}
+
+// NewState — случайный state для OAuth-запроса.
+func NewState() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// stateTTL — сколько даём пользователю на согласие на github.com.
+const stateTTL = 10 * time.Minute
+
+// SetState / CheckState — state в короткоживущей cookie.
+func (s *Sessions) SetState(w http.ResponseWriter, state string) {
+	c := s.cookie(stateCookie, state, time.Now().Add(stateTTL))
+	c.Path = "/api/auth/"
+	http.SetCookie(w, c)
+}
+
+func (s *Sessions) CheckState(w http.ResponseWriter, r *http.Request, state string) bool {
+	c, err := r.Cookie(stateCookie)
+	// state одноразовый — удаляем в любом случае
+	del := s.cookie(stateCookie, "", time.Now())
+	del.Path = "/api/auth/"
+	http.SetCookie(w, del)
+	return err == nil && state != "" && c.Value == state
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionCookie — сессия: base64(userID).version.expiry.base64(hmac). Подпись проверяется
+	// без похода в базу; version сверяется с store.User.SessionVersion (отзыв при выходе).
+	SessionCookie = "r2p_session"
+	// stateCookie — state OAuth-запроса (защита от CSRF на callback).
+	stateCookie = "r2p_oauth_state"
+)
+
+// Sessions — выдача и проверка сессионной cookie.
+type Sessions struct {
+	Cipher *Cipher
+	TTL    time.Duration
+	Secure bool // cookie только по HTTPS (prod)
+}
+
+// Issue — выставить cookie сессии для userID с текущей версией его сессий.
+func (s *Sessions) Issue(w http.ResponseWriter, userID string, version int64) {
+	exp := time.Now().Add(s.TTL)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID)) + "." + strconv.FormatInt(version, 10) +
+		"." + strconv.FormatInt(exp.Unix(), 10)
+	value := payload + "." + base64.RawURLEncoding.EncodeToString(s.Cipher.sign(payload))
+	http.SetCookie(w, s.cookie(SessionCookie, value, exp))
+}
+
+// UserID — ID пользователя и версия сессии из валидной непросроченной cookie.
+// Версию вызывающий сверяет с store.User.SessionVersion.
+func (s *Sessions) UserID(r *http.Request) (string, int64, bool) {
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(c.Value, '.')
+	if i < 0 {
+		return "", 0, false
+	}
+	payload := c.Value[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(c.Value[i+1:])
+	if err != nil || !hmac.Equal(sig, s.Cipher.sign(payload)) {
+		return "", 0, false
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return "", 0, false
+	}
+	version, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return "", 0, false
+	}
+	id, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(id) == 0 {
+		return "", 0, false
+	}
+	return string(id), version, true
+}
+
+// Clear — удалить cookie сессии.
+func (s *Sessions) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, s.cookie(SessionCookie, "", time.Unix(0, 0)))
+}
+
+func (s *Sessions) cookie(name, value string, exp time.Time) *http.Cookie {
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  exp,
+		HttpOnly: true,
+		Secure:   s.Secure,
+		SameSite: http.SameSiteLaxMode, // Lax — cookie приходит и на redirect с github.com
+	}
+	if value == "" {
+		c.MaxAge = -1
+	}
+	return c
+}
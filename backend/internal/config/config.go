@@ -17,31 +17,74 @@ const (
 )
 
 type Config struct {
-	Port              string
-	GitHubToken       string
-	RequestTimeout    time.Duration
-	Env               Env
-	DatabaseURL       string
-	ArtifactsDir      string
-	ArtifactsBackend  string
-	ArtifactsTTLHours int
-	S3Endpoint        string
-	S3Region          string
-	S3Bucket          string
-	S3AccessKey       string
-	S3SecretKey       string
-	S3UseSSL          bool
-	S3Prefix          string
+	Port                    string
+	GitHubToken             string
+	GitHubTokens            []string // дополнительные токены пула (GITHUB_TOKENS через запятую)
+	GitHubTokenReserve      int      // сколько запросов оставлять у токена про запас
+	GitHubAppID             int64    // GitHub App; 0 — не используется
+	GitHubAppPrivateKey     string   // PEM ключа приложения
+	GitHubAppPrivateKeyFile string   // или путь к нему
+	RequestTimeout          time.Duration
+	Env                     Env
+	DatabaseURL             string
+	ArtifactsDir            string
+	ArtifactsBackend        string
+	ArtifactsTTLHours       int
+	S3Endpoint              string
+	S3Region                string
+	S3Bucket                string
+	S3AccessKey             string
+	S3SecretKey             string
+	S3UseSSL                bool
+	S3Prefix                string
+	RedisAddr               string
+	RedisPassword           string
+	TreeCacheBackend        string        // memory|redis
+	TreeCacheMaxItems       int           // ёмкость memory-кэша в элементах деревьев
+	TreeCacheTTL            time.Duration // срок жизни деревьев в кэше (по SHA — неизменяемы)
+	TreeRefTTL              time.Duration // через сколько ревалидировать ref → SHA (условным запросом)
+	GitHubOAuthClientID     string        // OAuth App для входа пользователей
+	GitHubOAuthClientSecret string
+	GitHubOAuthRedirectURL  string // .../api/auth/github/callback
+	AuthSuccessRedirect     string // куда вернуть браузер после входа
+	AuthEncryptionKey       string // base64(32 байта): шифрование токенов и подпись сессий
+	SessionTTL              time.Duration
+	GitHubCacheBackend      string        // off|memory|disk|redis — HTTP-кэш ответов GitHub (ETag)
+	GitHubCacheDir          string        // для disk
+	GitHubCacheMaxBytes     int64         // ёмкость memory/disk; 0 — умолчание хранилища
+	GitHubCacheTTL          time.Duration // срок хранения для disk
+	GitHubCacheRedisAddr    string        // Redis HTTP-кэша; пусто — REDIS_ADDR
+	GitHubCacheRedisDB      int           // отдельная база, не та, что у очереди asynq (0)
+	GitHubCacheRedisTTL     time.Duration // срок хранения для redis — короче, чем на диске
+	GitHubMaxRetries        int           // повторы запросов к GitHub (5xx, сеть, вторичный лимит); -1 — выкл.
+	GitHubRetryBudget       time.Duration // суммарное ожидание повторов на один запрос
+	LFSMaxBytes             int64         // объекты Git LFS крупнее в экспорт не скачиваем
+	AdminToken              string        // Bearer для /metrics и /api/github/ratelimit; пусто — закрыты
 }
 
 func Load() (Config, error) {
 	cfg := Config{
-		Port:              "8080",
-		RequestTimeout:    15 * time.Second,
-		Env:               EnvDev,
-		ArtifactsDir:      "./data/artifacts",
-		ArtifactsBackend:  "fs",
-		ArtifactsTTLHours: 72,
+		Port:                "8080",
+		RequestTimeout:      15 * time.Second,
+		Env:                 EnvDev,
+		ArtifactsDir:        "./data/artifacts",
+		ArtifactsBackend:    "fs",
+		ArtifactsTTLHours:   72,
+		RedisAddr:           "redis:6379",
+		TreeCacheBackend:    "memory",
+		TreeCacheTTL:        24 * time.Hour,
+		TreeRefTTL:          time.Minute,
+		GitHubTokenReserve:  50,
+		AuthSuccessRedirect: "/",
+		SessionTTL:          30 * 24 * time.Hour,
+		GitHubCacheBackend:  "memory",
+		GitHubCacheDir:      "./data/ghcache",
+		GitHubCacheTTL:      7 * 24 * time.Hour,
+		GitHubCacheRedisDB:  1,
+		GitHubCacheRedisTTL: time.Hour,
+		GitHubMaxRetries:    3,
+		GitHubRetryBudget:   30 * time.Second,
+		LFSMaxBytes:         10 << 20,
 	}
 	cfg.DatabaseURL = os.Getenv("DATABASE_URL")
 	if v := os.Getenv("PORT"); v != "" {
@@ -52,6 +95,53 @@ func Load() (Config, error) {
 		cfg.GitHubToken = v
 	}
 
+	if v := os.Getenv("GITHUB_TOKENS"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				cfg.GitHubTokens = append(cfg.GitHubTokens, t)
+			}
+		}
+	}
+	if v := os.Getenv("GITHUB_TOKEN_RESERVE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, errors.New("invalid GITHUB_TOKEN_RESERVE (must be non-negative integer)")
+		}
+		cfg.GitHubTokenReserve = n
+	}
+
+	if v := os.Getenv("GITHUB_APP_ID"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return Config{}, errors.New("invalid GITHUB_APP_ID (must be positive integer)")
+		}
+		cfg.GitHubAppID = n
+	}
+	cfg.GitHubAppPrivateKey = os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	cfg.GitHubAppPrivateKeyFile = os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+	if cfg.GitHubAppID != 0 && cfg.GitHubAppPrivateKey == "" && cfg.GitHubAppPrivateKeyFile == "" {
+		return Config{}, errors.New("GITHUB_APP_ID requires GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_FILE")
+	}
+
+	cfg.GitHubOAuthClientID = os.Getenv("GITHUB_OAUTH_CLIENT_ID")
+	cfg.GitHubOAuthClientSecret = os.Getenv("GITHUB_OAUTH_CLIENT_SECRET")
+	cfg.GitHubOAuthRedirectURL = os.Getenv("GITHUB_OAUTH_REDIRECT_URL")
+	cfg.AuthEncryptionKey = os.Getenv("AUTH_ENCRYPTION_KEY")
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+	if v := os.Getenv("AUTH_SUCCESS_REDIRECT"); v != "" {
+		cfg.AuthSuccessRedirect = v
+	}
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid SESSION_TTL (use Go duration, e.g. 720h)")
+		}
+		cfg.SessionTTL = d
+	}
+	if cfg.GitHubOAuthClientID != "" && (cfg.GitHubOAuthClientSecret == "" || cfg.AuthEncryptionKey == "") {
+		return Config{}, errors.New("GITHUB_OAUTH_CLIENT_ID requires GITHUB_OAUTH_CLIENT_SECRET and AUTH_ENCRYPTION_KEY")
+	}
+
 	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
 		d, err := time.ParseDuration(v)
 		if err != nil {
@@ -101,6 +191,105 @@ func Load() (Config, error) {
 		cfg.S3UseSSL = b
 	}
 
+	if v := os.Getenv("REDIS_ADDR"); v != "" {
+		cfg.RedisAddr = v
+	}
+	cfg.RedisPassword = os.Getenv("REDIS_PASSWORD")
+	if v := os.Getenv("TREE_CACHE_BACKEND"); v != "" {
+		switch strings.ToLower(v) {
+		case "memory", "redis":
+			cfg.TreeCacheBackend = strings.ToLower(v)
+		default:
+			return Config{}, errors.New("invalid TREE_CACHE_BACKEND (memory|redis)")
+		}
+	}
+	if v := os.Getenv("TREE_CACHE_MAX_ITEMS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Config{}, errors.New("invalid TREE_CACHE_MAX_ITEMS (must be positive integer)")
+		}
+		cfg.TreeCacheMaxItems = n
+	}
+	if v := os.Getenv("TREE_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid TREE_CACHE_TTL (use Go duration, e.g. 24h)")
+		}
+		cfg.TreeCacheTTL = d
+	}
+	if v := os.Getenv("TREE_REF_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, errors.New("invalid TREE_REF_TTL (use Go duration, e.g. 1m)")
+		}
+		cfg.TreeRefTTL = d
+	}
+
+	if v := os.Getenv("GITHUB_CACHE_BACKEND"); v != "" {
+		switch strings.ToLower(v) {
+		case "off", "memory", "disk", "redis":
+			cfg.GitHubCacheBackend = strings.ToLower(v)
+		default:
+			return Config{}, errors.New("invalid GITHUB_CACHE_BACKEND (off|memory|disk|redis)")
+		}
+	}
+	if v := os.Getenv("GITHUB_CACHE_DIR"); v != "" {
+		cfg.GitHubCacheDir = v
+	}
+	if v := os.Getenv("GITHUB_CACHE_MAX_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Config{}, errors.New("invalid GITHUB_CACHE_MAX_MB (must be positive integer)")
+		}
+		cfg.GitHubCacheMaxBytes = int64(n) << 20
+	}
+	if v := os.Getenv("GITHUB_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid GITHUB_CACHE_TTL (use Go duration, e.g. 168h)")
+		}
+		cfg.GitHubCacheTTL = d
+	}
+	cfg.GitHubCacheRedisAddr = os.Getenv("GITHUB_CACHE_REDIS_ADDR")
+	if v := os.Getenv("GITHUB_CACHE_REDIS_DB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, errors.New("invalid GITHUB_CACHE_REDIS_DB (must be non-negative integer)")
+		}
+		cfg.GitHubCacheRedisDB = n
+	}
+	if v := os.Getenv("GITHUB_CACHE_REDIS_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid GITHUB_CACHE_REDIS_TTL (use Go duration, e.g. 1h)")
+		}
+		cfg.GitHubCacheRedisTTL = d
+	}
+	if v := os.Getenv("GITHUB_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, errors.New("invalid GITHUB_MAX_RETRIES (must be non-negative integer)")
+		}
+		cfg.GitHubMaxRetries = n
+		if n == 0 {
+			cfg.GitHubMaxRetries = -1 // 0 в RetryDoer — значение по умолчанию
+		}
+	}
+	if v := os.Getenv("GITHUB_RETRY_BUDGET"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid GITHUB_RETRY_BUDGET (use Go duration, e.g. 30s)")
+		}
+		cfg.GitHubRetryBudget = d
+	}
+	if v := os.Getenv("EXPORT_LFS_MAX_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Config{}, errors.New("invalid EXPORT_LFS_MAX_MB (must be positive integer)")
+		}
+		cfg.LFSMaxBytes = int64(n) << 20
+	}
+
 	if err := validatePort(cfg.Port); err != nil {
 		return Config{}, err
 	}
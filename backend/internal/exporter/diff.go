@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"strings"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/secrets"
+)
+
+// Diff-режим экспорта: только файлы, изменённые между двумя ref'ами
+// (githubclient.CompareRefs), и по желанию — их unified diff рядом с содержимым.
+
+// ChangeSet — изменения base...head. nil — обычный экспорт всего дерева.
+type ChangeSet struct {
+	Base, Head string
+	Files      []githubclient.PullFile // пути от корня репозитория, не от RootPath
+	Hunks      bool                    // добавлять unified diff к каждому файлу
+	Truncated  bool                    // GitHub отдал не все файлы (githubclient.MaxCompareFiles)
+
+	byPath map[string]githubclient.PullFile
+}
+
+// changedFile — строка сводки изменений; пути уже относительно RootPath.
+type changedFile struct {
+	Path, From, Status   string
+	Additions, Deletions int
+}
+
+// Has — файл (путь от корня репозитория) изменён. У nil ChangeSet — любой файл.
+func (c *ChangeSet) Has(full string) bool {
+	if c == nil {
+		return true
+	}
+	_, ok := c.file(full)
+	return ok
+}
+
+func (c *ChangeSet) file(full string) (githubclient.PullFile, bool) {
+	if c.byPath == nil {
+		c.byPath = make(map[string]githubclient.PullFile, len(c.Files))
+		for _, f := range c.Files {
+			c.byPath[f.Filename] = f
+		}
+	}
+	f, ok := c.byPath[full]
+	return f, ok
+}
+
+// list — изменения внутри rootPath и суммы строк по ним.
+func (c *ChangeSet) list(rootPath string) (files []changedFile, add, del int) {
+	for _, f := range c.Files {
+		rel, ok := reRoot(f.Filename, rootPath)
+		if !ok {
+			continue
+		}
+		from := ""
+		if f.PreviousFilename != "" {
+			if from, ok = reRoot(f.PreviousFilename, rootPath); !ok {
+				from = f.PreviousFilename
+			}
+		}
+		files = append(files, changedFile{Path: rel, From: from, Status: f.Status, Additions: f.Additions, Deletions: f.Deletions})
+		add += f.Additions
+		del += f.Deletions
+	}
+	return files, add, del
+}
+
+// hunk — unified diff файла (маскированный) или "", если дифф не просили.
+// Второе значение — сколько строк замаскировано.
+func (c *ChangeSet) hunk(sc *secrets.Scanner, full string) (string, int) {
+	if c == nil || !c.Hunks {
+		return "", 0
+	}
+	f, ok := c.file(full)
+	if !ok {
+		return "", 0
+	}
+	return maskText(sc, f.Filename, fileDiff(f))
+}
+
+// removed — удалённые файлы внутри rootPath: в архиве head их нет,
+// так что их дифф выводим отдельно, в конце.
+func (c *ChangeSet) removed(rootPath string) []githubclient.PullFile {
+	if c == nil || !c.Hunks {
+		return nil
+	}
+	var out []githubclient.PullFile
+	for _, f := range c.Files {
+		if _, ok := reRoot(f.Filename, rootPath); ok && f.Status == "removed" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// hunk — дифф файла для врезок promptpack (маскированные строки учитываются).
+func (st *packState) hunk(full string) string {
+	d, n := st.changes.hunk(st.scanner, full)
+	st.maskedLines += n
+	return d
+}
+
+// maskText — текст через сканер секретов: структурные конфиги целиком, прочее построчно.
+// Второе значение — сколько строк изменилось. sc == nil — текст как есть.
+func maskText(sc *secrets.Scanner, rel, text string) (string, int) {
+	if sc == nil || text == "" {
+		return text, 0
+	}
+	masked := 0
+	byLine := blobFindings(sc, rel, []byte(text))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		finds := byLine[i+1]
+		if byLine == nil {
+			finds = sc.ScanLine(rel, line, i+1)
+		}
+		if out := sc.ApplyStrategy(line, finds); out != line {
+			lines[i] = strings.TrimSuffix(out, "\n")
+			masked++
+		}
+	}
+	return strings.Join(lines, "\n"), masked
+}
@@ -0,0 +1,225 @@
+package exporter
+
+import (
+	"bufio"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Импорты для контекста PR pack: кто кого импортирует напрямую.
+// Разбор регулярками по строкам — без AST, зато одинаково для Go, JS/TS и Python.
+// Учитываются только импорты внутри репозитория (внешние пакеты отбрасываются при резолве).
+
+var (
+	reGoImportLine = regexp.MustCompile(`^import\s+(?:[\w.]+\s+)?"([^"]+)"`)
+	reGoImportSpec = regexp.MustCompile(`^(?:[\w.]+\s+)?"([^"]+)"`)
+	reGoModule     = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+	reJSImport = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*\(?\s*|\brequire\s*\(\s*)['"]([^'"]+)['"]`)
+
+	rePyFrom   = regexp.MustCompile(`^\s*from\s+(\.*[\w.]*)\s+import\s+(.+)`)
+	rePyImport = regexp.MustCompile(`^\s*import\s+(.+)`)
+)
+
+// расширения, которые пробуем для относительных импортов JS/TS (в порядке приоритета)
+var jsExts = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte"}
+
+// importLang — язык для разбора импортов; "" — не разбираем.
+func importLang(p string) string {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".go":
+		return "go"
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte":
+		return "js"
+	case ".py":
+		return "py"
+	}
+	return ""
+}
+
+// parseImports — спецификаторы импортов файла как есть ("./x", "pkg/y", ".mod").
+func parseImports(lang, content string) []string {
+	var out []string
+	sc := bufio.NewScanner(strings.NewReader(content))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	inGoBlock := false
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch lang {
+		case "go":
+			switch {
+			case inGoBlock && strings.HasPrefix(line, ")"):
+				inGoBlock = false
+			case inGoBlock:
+				if m := reGoImportSpec.FindStringSubmatch(line); m != nil {
+					out = append(out, m[1])
+				}
+			case strings.HasPrefix(line, "import ("):
+				inGoBlock = true
+			default:
+				if m := reGoImportLine.FindStringSubmatch(line); m != nil {
+					out = append(out, m[1])
+				}
+			}
+		case "js":
+			if !strings.Contains(line, "import") && !strings.Contains(line, "require") && !strings.Contains(line, "from") {
+				continue
+			}
+			for _, m := range reJSImport.FindAllStringSubmatch(line, -1) {
+				out = append(out, m[1])
+			}
+		case "py":
+			if m := rePyFrom.FindStringSubmatch(line); m != nil {
+				mod := m[1]
+				// from . import a, b — это модули a и b пакета
+				if strings.Trim(mod, ".") == "" {
+					for _, name := range strings.Split(strings.Trim(m[2], "() "), ",") {
+						if f := strings.Fields(name); len(f) > 0 {
+							out = append(out, mod+f[0])
+						}
+					}
+					continue
+				}
+				out = append(out, mod)
+			} else if m := rePyImport.FindStringSubmatch(line); m != nil {
+				for _, name := range strings.Split(m[1], ",") {
+					if f := strings.Fields(name); len(f) > 0 {
+						out = append(out, f[0])
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// importGraph — прямые связи между файлами репозитория.
+type importGraph struct {
+	files    map[string]bool     // все файлы архива
+	dirs     map[string][]string // каталог → .go-файлы пакета (без _test)
+	modules  map[string]string   // каталог go.mod → module path
+	specs    map[string][]string // файл → импорты как есть
+	imports  map[string][]string // файл → файлы, которые он импортирует
+	importer map[string][]string // файл → файлы, которые импортируют его
+}
+
+func newImportGraph() *importGraph {
+	return &importGraph{
+		files:   map[string]bool{},
+		dirs:    map[string][]string{},
+		modules: map[string]string{},
+		specs:   map[string][]string{},
+	}
+}
+
+// addFile — файл архива; content — для кода и go.mod (иначе пусто).
+func (g *importGraph) addFile(rel, content string) {
+	g.files[rel] = true
+	if path.Base(rel) == "go.mod" {
+		if m := reGoModule.FindStringSubmatch(content); m != nil {
+			g.modules[dirOf(rel)] = m[1]
+		}
+		return
+	}
+	lang := importLang(rel)
+	if lang == "go" && !strings.HasSuffix(rel, "_test.go") {
+		g.dirs[dirOf(rel)] = append(g.dirs[dirOf(rel)], rel)
+	}
+	if lang != "" && content != "" {
+		if specs := parseImports(lang, content); len(specs) > 0 {
+			g.specs[rel] = specs
+		}
+	}
+}
+
+// resolve — после addFile всех файлов: спецификаторы → файлы репозитория.
+func (g *importGraph) resolve() {
+	g.imports = map[string][]string{}
+	g.importer = map[string][]string{}
+	for from, specs := range g.specs {
+		seen := map[string]bool{from: true}
+		for _, spec := range specs {
+			for _, to := range g.resolveSpec(from, spec) {
+				if seen[to] {
+					continue
+				}
+				seen[to] = true
+				g.imports[from] = append(g.imports[from], to)
+				g.importer[to] = append(g.importer[to], from)
+			}
+		}
+	}
+	for _, m := range []map[string][]string{g.imports, g.importer} {
+		for k := range m {
+			sort.Strings(m[k])
+		}
+	}
+}
+
+func (g *importGraph) resolveSpec(from, spec string) []string {
+	switch importLang(from) {
+	case "go":
+		// вложенные go.mod: берём модуль с самым длинным подходящим путём
+		best, bestDir := "", ""
+		for dir, mod := range g.modules {
+			rest, ok := strings.CutPrefix(spec, mod)
+			if ok && (rest == "" || rest[0] == '/') && len(mod) > len(best) {
+				best, bestDir = mod, dir
+			}
+		}
+		if best != "" {
+			return g.dirs[path.Join(bestDir, strings.TrimPrefix(spec[len(best):], "/"))]
+		}
+	case "js":
+		if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+			return nil // пакеты и алиасы сборщика не резолвим
+		}
+		base := path.Join(dirOf(from), spec)
+		if g.files[base] {
+			return []string{base}
+		}
+		for _, ext := range jsExts {
+			if g.files[base+ext] {
+				return []string{base + ext}
+			}
+		}
+		for _, ext := range jsExts {
+			if p := path.Join(base, "index"+ext); g.files[p] {
+				return []string{p}
+			}
+		}
+	case "py":
+		dots := len(spec) - len(strings.TrimLeft(spec, "."))
+		mod := strings.ReplaceAll(spec[dots:], ".", "/")
+		var roots []string
+		if dots > 0 {
+			dir := dirOf(from)
+			for i := 1; i < dots; i++ {
+				dir = dirOf(dir)
+			}
+			roots = []string{dir}
+		} else {
+			roots = []string{"", "src", dirOf(from)}
+		}
+		for _, root := range roots {
+			p := path.Join(root, mod)
+			for _, cand := range []string{p + ".py", path.Join(p, "__init__.py")} {
+				if g.files[cand] {
+					return []string{cand}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dirOf — каталог пути ("" для корня, в отличие от path.Dir).
+func dirOf(p string) string {
+	d := path.Dir(p)
+	if d == "." || d == "/" {
+		return ""
+	}
+	return d
+}
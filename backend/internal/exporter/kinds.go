@@ -0,0 +1,13 @@
+package exporter
+
+// FileKinds — сколько файлов каждого вида (filters.Kind*) попало в экспорт.
+// Уходит в Manifest пакета рядом со SkippedFiles. nil — не собираем.
+type FileKinds map[string]int
+
+// Add — учесть файл вида kind.
+func (k FileKinds) Add(kind string) {
+	if k == nil {
+		return
+	}
+	k[kind]++
+}
@@ -0,0 +1,137 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+)
+
+// DefaultLFSMaxBytes — объекты Git LFS крупнее не скачиваем (LFSResolver.MaxBytes == 0).
+const DefaultLFSMaxBytes = 10 << 20
+
+// Почему pointer Git LFS не подменён содержимым (LFSObject.Reason).
+const (
+	LFSDisabled    = "disabled"     // скачивание LFS не запрошено
+	LFSNotText     = "not-text"     // по имени не текст: картинки, архивы, бинарники
+	LFSTooLarge    = "too-large"    // больше LFSResolver.MaxBytes
+	LFSFetchFailed = "fetch-failed" // нет доступа, объект удалён или не сошёлся sha256
+)
+
+// LFSResolver — скачивание объектов Git LFS вместо pointer-файлов
+// (в воркере — githubclient.OpenLFSObject). nil — только помечаем pointer'ы.
+type LFSResolver struct {
+	Fetch    func(oid string, size int64) (io.ReadCloser, error)
+	MaxBytes int64 // 0 — DefaultLFSMaxBytes
+}
+
+// LFSObject — файл, который в репозитории лежит в Git LFS.
+type LFSObject struct {
+	Path   string `json:"path"`
+	OID    string `json:"oid"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"` // только у неразрешённых pointer'ов
+	Error  string `json:"error,omitempty"`  // для fetch-failed: что именно не получилось
+}
+
+// Label — пометка вместо содержимого: lfs-pointer (oid sha256:…, size N).
+func (o LFSObject) Label() string {
+	return fmt.Sprintf("%s (oid sha256:%s, size %d)", filters.KindLFSPointer, o.OID, o.Size)
+}
+
+// LFSFiles — раздел manifest'а про Git LFS: скачанные объекты и pointer'ы,
+// которые так и остались pointer'ами. Списки ограничены, счётчики — полные. nil — не собираем.
+type LFSFiles struct {
+	Resolved      []LFSObject `json:"resolved"`
+	Pointers      []LFSObject `json:"pointers"`
+	ResolvedCount int         `json:"resolvedCount"`
+	PointerCount  int         `json:"pointerCount"`
+}
+
+func NewLFSFiles() *LFSFiles {
+	return &LFSFiles{Resolved: []LFSObject{}, Pointers: []LFSObject{}}
+}
+
+func (l *LFSFiles) add(o LFSObject) {
+	if l == nil {
+		return
+	}
+	if o.Reason == "" {
+		l.ResolvedCount++
+		if len(l.Resolved) < maxSkippedPerReason {
+			l.Resolved = append(l.Resolved, o)
+		}
+		return
+	}
+	l.PointerCount++
+	if len(l.Pointers) < maxSkippedPerReason {
+		l.Pointers = append(l.Pointers, o)
+	}
+}
+
+// substLFS — если файл rel (size байт в tar) — pointer Git LFS, подменяет его
+// скачанным объектом: новое тело и размер. ptr != nil — pointer не разрешён
+// (уже учтён в rep): тело — сам pointer-файл, билдер помечает его как есть.
+// Не pointer — тело и размер как были. Ошибка — только чтение архива.
+func substLFS(r *LFSResolver, rep *LFSFiles, rel string, body io.Reader, size int64) (io.Reader, int64, *LFSObject, error) {
+	if size > filters.MaxLFSPointerSize {
+		return body, size, nil, nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	oid, osize, ok := filters.ParseLFSPointer(buf)
+	if !ok {
+		return bytes.NewReader(buf), size, nil, nil
+	}
+	obj := LFSObject{Path: rel, OID: oid, Size: osize}
+	content, reason, ferr := r.fetch(rel, oid, osize)
+	obj.Reason = reason
+	if ferr != nil {
+		obj.Error = ferr.Error()
+	}
+	rep.add(obj)
+	if reason != "" {
+		return bytes.NewReader(buf), size, &obj, nil
+	}
+	return bytes.NewReader(content), int64(len(content)), nil, nil
+}
+
+// fetch — содержимое объекта или причина, почему его не скачали
+// (для LFSFetchFailed — ещё и сама ошибка, она уходит в отчёт).
+// Качаем только текстовое по имени: картинки и бинарники в TXT/prompt бесполезны.
+func (r *LFSResolver) fetch(rel, oid string, size int64) ([]byte, string, error) {
+	if r == nil || r.Fetch == nil {
+		return nil, LFSDisabled, nil
+	}
+	limit := r.MaxBytes
+	if limit <= 0 {
+		limit = DefaultLFSMaxBytes
+	}
+	switch {
+	case filters.KindByName(rel) != filters.KindText:
+		return nil, LFSNotText, nil
+	case size > limit:
+		return nil, LFSTooLarge, nil
+	}
+	rc, err := r.Fetch(oid, size)
+	if err != nil {
+		return nil, LFSFetchFailed, err
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(io.LimitReader(rc, size+1))
+	if err != nil {
+		return nil, LFSFetchFailed, err
+	}
+	if int64(len(content)) != size {
+		return nil, LFSFetchFailed, fmt.Errorf("object size %d, pointer says %d", len(content), size)
+	}
+	if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != oid {
+		return nil, LFSFetchFailed, fmt.Errorf("object sha256 mismatch")
+	}
+	return content, "", nil
+}
@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+
+	"github.com/yourname/cleanhttp/internal/submodules"
+)
+
+// ManifestName — отчёт экспорта внутри самого пакета (zip и promptpack — отдельным
+// файлом, txt — последним блоком), рядом с secrets-report.json.
+const ManifestName = "rep2prompt-manifest.json"
+
+// Manifest — что вошло в экспорт и что нет. Метаданных артефакта мало: пакет
+// уходит в чат или коллеге без нашего API, и отчёт должен ехать вместе с ним.
+// Билдер пишет его после последнего файла архива, когда отчёты уже полные
+// (Merge сабмодулей дописывает свой к концу потока). nil — не пишем.
+type Manifest struct {
+	Skipped    *SkippedFiles      `json:"skipped,omitempty"` // что не вошло и почему
+	Kinds      FileKinds          `json:"kinds,omitempty"`
+	LFS        *LFSFiles          `json:"lfs,omitempty"`
+	Submodules *submodules.Report `json:"submodules,omitempty"`
+}
+
+func (m *Manifest) encode() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// writeManifest — m отдельным файлом в zip (nil — ничего).
+func writeManifest(zw *zip.Writer, m *Manifest) error {
+	if m == nil {
+		return nil
+	}
+	b, err := m.encode()
+	if err != nil {
+		return err
+	}
+	return writeZipEntry(zw, ManifestName, b)
+}
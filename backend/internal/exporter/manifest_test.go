@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/submodules"
+)
+
+func TestManifest_WrittenIntoEveryFormat(t *testing.T) {
+	src := makeTarGz(map[string]string{"main.go": "package main\n"})
+	m := &Manifest{Submodules: &submodules.Report{Included: []submodules.Module{{Path: "libs/ui", Partial: submodules.SkipSizeLimit}}}}
+	check := func(format, body string) {
+		t.Helper()
+		var got struct {
+			Submodules struct {
+				Included []struct{ Path, Partial string }
+			}
+		}
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("%s: manifest is not JSON: %v\n%s", format, err, body)
+		}
+		if len(got.Submodules.Included) != 1 || got.Submodules.Included[0].Partial != "size-limit" {
+			t.Fatalf("%s: manifest %s", format, body)
+		}
+	}
+
+	var zipOut bytes.Buffer
+	if err := BuildZipFromTarGz(bytes.NewReader(src), &zipOut, Options{StripFirstDir: true, Manifest: m}); err != nil {
+		t.Fatalf("build zip: %v", err)
+	}
+	check("zip", zipContents(t, zipOut.Bytes())[ManifestName])
+
+	var txtOut bytes.Buffer
+	if err := BuildTxtFromTarGz(bytes.NewReader(src), &txtOut, TxtOptions{StripFirstDir: true, Manifest: m}); err != nil {
+		t.Fatalf("build txt: %v", err)
+	}
+	_, tail, ok := strings.Cut(txtOut.String(), "=== MANIFEST: "+ManifestName+" ===\n")
+	if !ok {
+		t.Fatalf("txt: no manifest block:\n%s", txtOut.String())
+	}
+	check("txt", tail)
+
+	files := buildPromptPack(t, src, PromptPackOptions{Owner: "o", Repo: "r", Ref: "main", StripFirstDir: true, Manifest: m})
+	check("promptpack", files[ManifestName])
+}
+
+func TestManifest_ListsSkippedFiles(t *testing.T) {
+	src := makeTarGz(map[string]string{
+		"main.go":           "package main\n",
+		"vendor/lib/lib.go": "package lib\n",
+		"data/items.json":   strings.Repeat(`{"id":1},`, 400), // одна длинная строка — но это данные
+	})
+	skipped := NewSkippedFiles()
+	var out bytes.Buffer
+	opts := Options{StripFirstDir: true, SkipGenerated: true, Skipped: skipped, Manifest: &Manifest{Skipped: skipped}}
+	if err := BuildZipFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+		t.Fatalf("build zip: %v", err)
+	}
+	files := zipContents(t, out.Bytes())
+	if _, ok := files["data/items.json"]; !ok {
+		t.Fatal("one-line JSON was dropped as minified")
+	}
+	var got Manifest
+	if err := json.Unmarshal([]byte(files[ManifestName]), &got); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if got.Skipped == nil || got.Skipped.Counts["vendored"] != 1 || got.Skipped.Paths["vendored"][0] != "vendor/lib/lib.go" {
+		t.Fatalf("skipped in manifest: %+v", got.Skipped)
+	}
+}
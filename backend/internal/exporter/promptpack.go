@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
 	"github.com/yourname/cleanhttp/internal/secrets"
 	"github.com/yourname/cleanhttp/internal/tokenest"
 )
@@ -40,7 +41,19 @@ type PromptPackOptions struct {
 	ExcludeGlobs     []string
 	MaxLinesPerFile  int
 	MaskSecrets      bool
-	StripFirstDir    bool // отрезать первый сегмент (owner-repo-<hash>/)
+	MaskPII          bool                // маскировать персональные данные (email, IP, телефоны, карты, IBAN)
+	SecretStrategy   secrets.Strategy    // как маскировать (дефолт: REDACTED)
+	SecretBaseline   secrets.Baseline    // принятые находки — не маскируем
+	UseRepoIgnore    bool                // учитывать .gitignore, .gitattributes и .rep2promptignore из репозитория
+	RepoRules        *filters.RepoIgnore // правила, собранные заранее (FetchRepoRules); nil — только по ходу чтения архива
+	SkipGenerated    bool                // пропускать сгенерированное, vendored, минифицированное и lockfiles
+	Skipped          *SkippedFiles       // куда записывать пропущенные файлы с причинами (nil — не собираем)
+	Kinds            FileKinds           // сколько файлов какого вида экспортировано (nil — не собираем)
+	StripFirstDir    bool                // отрезать первый сегмент (owner-repo-<hash>/)
+	RootPath         string              // только это поддерево: дерево, deps, env и врезки — относительно него
+	LFSFiles         *LFSFiles           // куда записывать pointer'ы Git LFS (nil — не собираем); объекты не качаем
+	Changes          *ChangeSet          // diff-режим: дерево и врезки только по изменённым файлам (nil — всё дерево)
+	Manifest         *Manifest           // отчёт экспорта — отдельным файлом пакета (nil — не пишем)
 
 	TokenBudget   int
 	ReservePct    int
@@ -62,6 +75,8 @@ type packState struct {
 	profile          Profile
 	nowUTC           time.Time
 	stripFirstDir    bool
+	rootPath         string
+	changes          *ChangeSet
 	// секции
 	summary, treeMD, depsMD, envMD, prompts bytes.Buffer
 
@@ -76,6 +91,10 @@ type packState struct {
 	// кандидаты на врезки
 	excerptCandidates []excerptRef
 
+	// pointer'ы Git LFS (содержимого в пакете нет — только пометка)
+	lfsPointers []LFSObject
+	lfsCount    int
+
 	// бюджет и оценка
 	modelID       string
 	est           *tokenest.Estimator
@@ -194,11 +213,18 @@ func BuildPromptPackFromTarGz(src io.Reader, dst io.Writer, opts PromptPackOptio
 		usableTokens:    usable,
 		overlapTokens:   opts.OverlapTokens,
 		maxLinesPerFile: opts.MaxLinesPerFile,
-		maskSecrets:     opts.MaskSecrets,
+		maskSecrets:     opts.MaskSecrets || opts.MaskPII,
 		stripFirstDir:   opts.StripFirstDir,
+		rootPath:        opts.RootPath,
+		changes:         opts.Changes,
 	}
 	if st.maskSecrets {
-		st.scanner = secrets.NewScanner(secrets.Config{Strategy: secrets.StrategyRedacted})
+		st.scanner = secrets.NewScanner(secrets.Config{
+			Strategy:  opts.SecretStrategy,
+			PII:       opts.MaskPII,
+			NoSecrets: !opts.MaskSecrets,
+			Baseline:  opts.SecretBaseline,
+		})
 	}
 
 	// 1) скан
@@ -209,6 +235,8 @@ func BuildPromptPackFromTarGz(src io.Reader, dst io.Writer, opts PromptPackOptio
 
 	// 2) секции
 	st.renderSummary()
+	st.renderLFS()
+	st.renderChanges()
 	st.renderDeps()
 	st.renderEnv()
 	st.renderPrompts()
@@ -252,6 +280,10 @@ func FillSecondPassExcerpts(src io.Reader, e *NeedSecondPassError) error {
 	defer gz.Close()
 	tr := tar.NewReader(gz)
 	err = e.state.renderExcerptsAndWriteZip(tr, e.zw)
+	if err == nil {
+		// отчёты собраны первым проходом — второй их не трогает
+		err = writeManifest(e.zw, e.opts.Manifest)
+	}
 	// Закрываем zip ЗДЕСЬ, чтобы финализировать архив.
 	cerr := e.zw.Close()
 	if err != nil {
@@ -275,13 +307,14 @@ func (st *packState) scanTar(tr *tar.Reader, opts PromptPackOptions) error {
 		pat  string
 		prio int
 	}{
-		{"readme*", 1},
+		// маски в синтаксисе .gitignore: "/" в начале — только корень репозитория
+		{"/readme*", 1},
 		{"cmd/**/main.go", 1}, {"internal/server/**", 2},
-		{"apps/**/app/**", 2}, {"pages/**", 2}, {"next.config.*", 2},
-		{"program.cs", 1}, {"startup.cs", 1}, {"controllers/**", 2},
-		{"makefile", 2}, {"dockerfile*", 2}, {"docker-compose*.yml", 2}, {"k8s/**", 3},
+		{"apps/**/app/**", 2}, {"pages/**", 2}, {"/next.config.*", 2},
+		{"/program.cs", 1}, {"/startup.cs", 1}, {"controllers/**", 2},
+		{"/makefile", 2}, {"/dockerfile*", 2}, {"/docker-compose*.yml", 2}, {"k8s/**", 3},
 		{"internal/**", 3}, {"src/**", 3},
-		{"package.json", 1}, {"go.mod", 1}, {"*.csproj", 1}, {"pyproject.toml", 1}, {"requirements.txt", 1},
+		{"/package.json", 1}, {"/go.mod", 1}, {"/*.csproj", 1}, {"/pyproject.toml", 1}, {"/requirements.txt", 1},
 	}
 
 	addEnv := func(name, src, usage, note string, secret bool) {
@@ -309,6 +342,23 @@ func (st *packState) scanTar(tr *tar.Reader, opts PromptPackOptions) error {
 
 	const sampleN = 4096
 
+	matcher, err := filters.NewMatcher(opts.IncludeGlobs, opts.ExcludeGlobs)
+	if err != nil {
+		return err
+	}
+	keyMatchers := make([]*filters.Matcher, len(keyGlobs))
+	for i, kg := range keyGlobs {
+		keyMatchers[i] = filters.MustMatcher([]string{kg.pat}, nil)
+	}
+
+	var repoIgn *filters.RepoIgnore
+	if opts.UseRepoIgnore {
+		repoIgn = opts.RepoRules
+		if repoIgn == nil {
+			repoIgn = filters.NewRepoIgnore()
+		}
+	}
+
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -331,33 +381,80 @@ func (st *packState) scanTar(tr *tar.Reader, opts PromptPackOptions) error {
 			drain(tr, hdr.Size)
 			continue
 		}
-		// применяем include/exclude к относительному пути
-		if !filters.Match(rel, opts.IncludeGlobs, opts.ExcludeGlobs) {
-			drain(tr, hdr.Size)
+		// правила самого репозитория (.gitignore и т.п.) — регистрируем до масок
+		body := readRepoRules(repoIgn, rel, tr)
+		// вне RootPath — мимо; дальше всё относительно поддерева
+		full := rel
+		var ok bool
+		if rel, ok = reRoot(full, st.rootPath); !ok {
+			drain(body, hdr.Size)
 			continue
 		}
+		// применяем include/exclude к относительному пути; в diff-режиме — только изменённое
+		if !matcher.Match(rel) || !opts.Changes.Has(full) {
+			drain(body, hdr.Size)
+			continue
+		}
+		if reason, ignored := repoIgn.Ignored(full); ignored {
+			opts.Skipped.Add(rel, reason)
+			drain(body, hdr.Size)
+			continue
+		}
+		// сгенерированное/стороннее по пути — ни в дерево, ни в выдержки
+		if opts.SkipGenerated {
+			if reason, ok := filters.DetectGeneratedPath(rel); ok {
+				opts.Skipped.Add(rel, reason)
+				drain(body, hdr.Size)
+				continue
+			}
+		}
 
 		// дерево
 		st.addToTree(rel)
 
+		// pointer'ы Git LFS только помечаем: врезки читаются вторым проходом
+		// по исходному архиву, подменённое содержимое туда бы не попало
+		body, size, ptr, err := substLFS(nil, opts.LFSFiles, rel, body, hdr.Size)
+		if err != nil {
+			return err
+		}
+		if ptr != nil {
+			opts.Kinds.Add(filters.KindLFSPointer)
+			st.lfsCount++
+			if len(st.lfsPointers) < maxSkippedPerReason {
+				st.lfsPointers = append(st.lfsPointers, *ptr)
+			}
+			continue
+		}
+
 		// сэмпл
-		sn := min64(hdr.Size, sampleN)
+		sn := min64(size, sampleN)
 		sample := make([]byte, sn)
 		if sn > 0 {
-			if _, err := io.ReadFull(tr, sample); err != nil {
+			if _, err := io.ReadFull(body, sample); err != nil {
 				continue
 			}
 		}
-		if filters.IsBinarySample(sample) {
-			drainN(tr, hdr.Size-sn)
+		// в дереве остаются все файлы; разбираем только текст
+		kind := filters.SniffKind(sample)
+		opts.Kinds.Add(kind)
+		if kind != filters.KindText {
+			drainN(body, size-sn)
 			continue
 		}
+		if opts.SkipGenerated {
+			if reason, ok := filters.DetectGeneratedContent(rel, sample); ok {
+				opts.Skipped.Add(rel, reason)
+				drainN(body, size-sn)
+				continue
+			}
+		}
 
 		lower := strings.ToLower(rel)
 
 		// README → SUMMARY
 		if isReadme(lower) {
-			lines := readFirstLines(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 30, int(hdr.Size-sn))
+			lines := readFirstLines(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 30, int(size-sn))
 			st.readmeFirstLines = pickSummaryLines(lines)
 			continue
 		}
@@ -365,29 +462,29 @@ func (st *packState) scanTar(tr *tar.Reader, opts PromptPackOptions) error {
 		// deps/env источники
 		switch {
 		case path.Base(lower) == "package.json":
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 512*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 512*1024, int(size-sn))
 			st.parseNpm(content)
 		case path.Base(lower) == "go.mod":
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 256*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 256*1024, int(size-sn))
 			st.parseGoMod(content)
 		case strings.HasSuffix(lower, ".csproj"):
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 512*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 512*1024, int(size-sn))
 			st.parseCsproj(content)
 		case path.Base(lower) == "pyproject.toml" || path.Base(lower) == "requirements.txt":
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 512*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 512*1024, int(size-sn))
 			st.parsePythonDeps(lower, content)
 		case strings.HasPrefix(path.Base(lower), "docker-compose") && (strings.HasSuffix(lower, ".yml") || strings.HasSuffix(lower, ".yaml")):
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 512*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 512*1024, int(size-sn))
 			for _, v := range grepEnvFromCompose(content) {
 				addEnv(v, "compose", "", maybeSecret(v), isSecret(v))
 			}
 		case strings.HasPrefix(path.Base(lower), ".env"):
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 256*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 256*1024, int(size-sn))
 			for _, v := range grepEnvFromDotenv(content) {
 				addEnv(v, ".env", "", maybeSecret(v), isSecret(v))
 			}
 		default:
-			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: tr}), 512*1024, int(hdr.Size-sn))
+			content := readWhole(io.MultiReader(bytes.NewReader(sample), &countReader{R: body}), 512*1024, int(size-sn))
 			usagePrefix := rel + ":"
 			for _, m := range reGo.FindAllStringSubmatch(content, -1) {
 				addEnv(m[1], "code", usagePrefix, maybeSecret(m[1]), isSecret(m[1]))
@@ -406,13 +503,20 @@ func (st *packState) scanTar(tr *tar.Reader, opts PromptPackOptions) error {
 			}
 		}
 
-		// кандидаты на EXCERPTS — регистронезависимо
-		for _, kg := range keyGlobs {
-			if filters.Match(lower, []string{kg.pat}, nil) {
-				st.excerptCandidates = append(st.excerptCandidates, excerptRef{Path: rel, Priority: kg.prio})
+		// кандидаты на EXCERPTS — регистронезависимо; в diff-режиме — все изменённые файлы
+		prio := 0
+		for i, kg := range keyGlobs {
+			if keyMatchers[i].Match(lower) {
+				prio = kg.prio
 				break
 			}
 		}
+		if prio == 0 && opts.Changes != nil {
+			prio = 4
+		}
+		if prio > 0 {
+			st.excerptCandidates = append(st.excerptCandidates, excerptRef{Path: rel, Priority: prio})
+		}
 	}
 
 	// сортировка
@@ -434,7 +538,11 @@ func (st *packState) renderSummary() {
 	fmt.Fprintf(sb, "Дата: %s (UTC)\n", st.nowUTC.Format("2006-01-02"))
 	fmt.Fprintf(sb, "Профиль: %s\n", st.profile)
 	fmt.Fprintf(sb, "Модель: %s, бюджет токенов: %d, резерв под вопросы: ~%d\n", st.modelID, st.totalTokens, st.reserveTokens)
-	fmt.Fprintf(sb, "Сгенерировано из путей: <repo-root/*> (свёрнуто)\n\n")
+	if st.rootPath != "" {
+		fmt.Fprintf(sb, "Сгенерировано из путей: <repo-root/%s/*> (свёрнуто, пути ниже — относительно %s/)\n\n", st.rootPath, st.rootPath)
+	} else {
+		fmt.Fprintf(sb, "Сгенерировано из путей: <repo-root/*> (свёрнуто)\n\n")
+	}
 
 	fmt.Fprintf(sb, "## 01_SUMMARY\n\n")
 	if len(st.readmeFirstLines) > 0 {
@@ -452,11 +560,59 @@ func (st *packState) renderSummary() {
 	fmt.Fprintln(sb)
 }
 
+// renderLFS — pointer'ы Git LFS с пометкой, как в TXT: содержимое объектов
+// в пакет не попадает (дописывается к SUMMARY).
+func (st *packState) renderLFS() {
+	if st.lfsCount == 0 {
+		return
+	}
+	sb := &st.summary
+	fmt.Fprintf(sb, "### Git LFS\n\nФайлов-pointer'ов: %d, их содержимого в пакете нет.\n\n", st.lfsCount)
+	for _, o := range st.lfsPointers {
+		fmt.Fprintf(sb, "- %s [%s]\n", o.Path, o.Label())
+	}
+	if st.lfsCount > len(st.lfsPointers) {
+		fmt.Fprintf(sb, "- … и ещё %d\n", st.lfsCount-len(st.lfsPointers))
+	}
+	fmt.Fprintln(sb)
+}
+
+// renderChanges — diff-режим: таблица изменений base...head (дописывается к SUMMARY).
+func (st *packState) renderChanges() {
+	if st.changes == nil {
+		return
+	}
+	sb := &st.summary
+	files, add, del := st.changes.list(st.rootPath)
+	fmt.Fprintf(sb, "### Изменения %s...%s\n\n", st.changes.Base, st.changes.Head)
+	fmt.Fprintf(sb, "Файлов: %d, строк: +%d −%d. Дерево и врезки ниже — только по изменённым файлам.\n\n", len(files), add, del)
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintln(sb, "| файл | статус | + | − |")
+	fmt.Fprintln(sb, "|------|--------|---|---|")
+	for _, f := range files {
+		p := f.Path
+		if f.From != "" {
+			p = f.From + " → " + f.Path
+		}
+		fmt.Fprintf(sb, "| %s | %s | %d | %d |\n", p, f.Status, f.Additions, f.Deletions)
+	}
+	if st.changes.Truncated {
+		fmt.Fprintf(sb, "\n_GitHub отдал только первые %d изменённых файлов_\n", githubclient.MaxCompareFiles)
+	}
+	fmt.Fprintln(sb)
+}
+
 func (st *packState) renderTree(depth, limit int) {
 	var b = &st.treeMD
 	fmt.Fprintln(b, "## 02_TREE")
 	fmt.Fprintln(b)
-	fmt.Fprintln(b, "repo-root/")
+	if st.rootPath != "" {
+		fmt.Fprintf(b, "%s/\n", st.rootPath)
+	} else {
+		fmt.Fprintln(b, "repo-root/")
+	}
 	renderDir(b, "repo-root", "", st.dirChildren, 0, depth, limit)
 	fmt.Fprintln(b)
 }
@@ -568,6 +724,11 @@ func (st *packState) renderExcerptsAndWriteZip(tr *tar.Reader, zw *zip.Writer) e
 			drain(tr, hdr.Size)
 			continue
 		}
+		rel, ok := reRoot(rel, st.rootPath)
+		if !ok {
+			drain(tr, hdr.Size)
+			continue
+		}
 		// интересен ли нам этот путь?
 		prio, ok := want[rel]
 		if !ok {
@@ -594,15 +755,19 @@ func (st *packState) renderExcerptsAndWriteZip(tr *tar.Reader, zw *zip.Writer) e
 		}
 
 		seg := buf.String()
-		lang := codeLangByExt(rel)
+		lang := filters.FenceByPath(rel)
 
 		// Маскирование секретов (построчно)
 		if st.maskSecrets && st.scanner != nil && seg != "" {
 			var b strings.Builder
+			byLine := blobFindings(st.scanner, rel, []byte(seg))
 			ln := 0
 			for _, line := range strings.Split(seg, "\n") {
 				ln++
-				finds := st.scanner.ScanLine(rel, line, ln)
+				finds := byLine[ln]
+				if byLine == nil {
+					finds = st.scanner.ScanLine(rel, line, ln)
+				}
 				out := st.scanner.ApplyStrategy(line, finds)
 				if out != line {
 					st.maskedLines++
@@ -631,12 +796,6 @@ func (st *packState) renderExcerptsAndWriteZip(tr *tar.Reader, zw *zip.Writer) e
 	})
 
 	// пишем в главный файл и чанки, учитывая бюджет
-	newChunk := func(title string) *chunk {
-		c := &chunk{title: title, maxTokens: st.usableTokens}
-		st.chunks = append(st.chunks, *c)
-		return &st.chunks[len(st.chunks)-1]
-	}
-
 	for _, it := range collected {
 		if it.Seg == "" || it.Lines == 0 {
 			continue
@@ -647,38 +806,16 @@ func (st *packState) renderExcerptsAndWriteZip(tr *tar.Reader, zw *zip.Writer) e
 		if st.maskedLines > 0 {
 			block += "_секреты замаскированы_\n\n"
 		}
-		blockTokens := st.est.CountTokens(block, st.modelID)
-
-		if st.mainUsedTokens+blockTokens <= st.mainMaxTokens {
-			write(block)
-			st.mainUsedTokens += blockTokens
-			continue
-		}
-
-		if st.curChunk == nil {
-			st.curChunk = newChunk("CHUNK 1")
-		}
-		need := blockTokens
-		if st.curChunk.usedTokens > 0 && st.overlapTokens > 0 {
-			need += st.overlapTokens
-		}
-		if st.curChunk.usedTokens > 0 && st.curChunk.usedTokens+need > st.curChunk.maxTokens {
-			st.curChunk = newChunk(fmt.Sprintf("CHUNK %d", len(st.chunks)+1))
-			need = blockTokens
-		}
-		if len(st.chunks) > 0 && st.curChunk.usedTokens > 0 && st.overlapTokens > 0 {
-			prev := &st.chunks[len(st.chunks)-1]
-			ov := st.lastNTokensFrom(prev.body.String(), st.overlapTokens)
-			st.curChunk.body.WriteString("> Overlap (previous):\n>\n")
-			for _, ln := range strings.Split(strings.TrimRight(ov, "\n"), "\n") {
-				st.curChunk.body.WriteString("> " + ln + "\n")
-			}
-			st.curChunk.body.WriteString("\n")
-			st.curChunk.usedTokens += st.overlapTokens
+		if d := st.hunk(path.Join(st.rootPath, it.Path)); d != "" {
+			block += "#### DIFF\n```diff\n" + d + "```\n\n"
 		}
-		st.curChunk.body.WriteString(block)
-		st.curChunk.files = append(st.curChunk.files, chunkFile{Path: it.Path, Lines: it.Lines, Language: it.Lang})
-		st.curChunk.usedTokens += blockTokens
+		st.placeBlock(&main, chunkFile{Path: it.Path, Lines: it.Lines, Language: it.Lang}, block)
+	}
+	// удалённых файлов в архиве нет — только их дифф
+	for _, f := range st.changes.removed(st.rootPath) {
+		rel, _ := reRoot(f.Filename, st.rootPath)
+		block := fmt.Sprintf("### DIFF: %s (удалён)\n```diff\n%s```\n\n", rel, st.hunk(f.Filename))
+		st.placeBlock(&main, chunkFile{Path: rel, Language: "diff"}, block)
 	}
 
 	// главный md
@@ -687,6 +824,59 @@ func (st *packState) renderExcerptsAndWriteZip(tr *tar.Reader, zw *zip.Writer) e
 		return err
 	}
 	// чанки
+	if err := st.writeChunks(zw); err != nil {
+		return err
+	}
+	// отчёт сканера (без самих значений) — чтобы было видно, что и где замаскировано
+	return writeSecretsReport(zw, st.scanner, nil)
+}
+
+// placeBlock — блок в главный файл, пока он влезает в mainMaxTokens, иначе —
+// в текущий чанк (новый, если и там не влезает) с перекрытием из предыдущего.
+// Порядок вызовов = приоритет: что раньше, то ближе к началу.
+func (st *packState) placeBlock(main *bytes.Buffer, f chunkFile, block string) {
+	blockTokens := st.est.CountTokens(block, st.modelID)
+
+	if st.mainUsedTokens+blockTokens <= st.mainMaxTokens {
+		main.WriteString(block)
+		st.mainUsedTokens += blockTokens
+		return
+	}
+
+	if st.curChunk == nil {
+		st.curChunk = st.newChunk("CHUNK 1")
+	}
+	need := blockTokens
+	if st.curChunk.usedTokens > 0 && st.overlapTokens > 0 {
+		need += st.overlapTokens
+	}
+	if st.curChunk.usedTokens > 0 && st.curChunk.usedTokens+need > st.curChunk.maxTokens {
+		st.curChunk = st.newChunk(fmt.Sprintf("CHUNK %d", len(st.chunks)+1))
+		need = blockTokens
+	}
+	if len(st.chunks) > 0 && st.curChunk.usedTokens > 0 && st.overlapTokens > 0 {
+		prev := &st.chunks[len(st.chunks)-1]
+		ov := st.lastNTokensFrom(prev.body.String(), st.overlapTokens)
+		st.curChunk.body.WriteString("> Overlap (previous):\n>\n")
+		for _, ln := range strings.Split(strings.TrimRight(ov, "\n"), "\n") {
+			st.curChunk.body.WriteString("> " + ln + "\n")
+		}
+		st.curChunk.body.WriteString("\n")
+		st.curChunk.usedTokens += st.overlapTokens
+	}
+	st.curChunk.body.WriteString(block)
+	st.curChunk.files = append(st.curChunk.files, f)
+	st.curChunk.usedTokens += blockTokens
+}
+
+func (st *packState) newChunk(title string) *chunk {
+	c := &chunk{title: title, maxTokens: st.usableTokens}
+	st.chunks = append(st.chunks, *c)
+	return &st.chunks[len(st.chunks)-1]
+}
+
+// writeChunks — непустые чанки в zip: chunk-001.md, chunk-002.md, …
+func (st *packState) writeChunks(zw *zip.Writer) error {
 	for i := range st.chunks {
 		ch := &st.chunks[i]
 		if ch.usedTokens == 0 {
@@ -879,8 +1069,7 @@ func isEnvKey(s string) bool {
 }
 
 func maybeSecret(name string) string {
-	u := strings.ToUpper(name)
-	if strings.Contains(u, "SECRET") || strings.Contains(u, "TOKEN") || strings.Contains(u, "PASSWORD") || strings.Contains(u, "API") || strings.Contains(u, "KEY") || strings.Contains(u, "PWD") || strings.Contains(u, "PRIVATE") {
+	if secrets.IsSecretKey(name) {
 		return "секрет"
 	}
 	return ""
@@ -919,37 +1108,11 @@ func extractHead(tr *tar.Reader, wantPath string, maxLines int) (segment string,
 				break
 			}
 		}
-		lang = codeLangByExt(wantPath)
+		lang = filters.FenceByPath(wantPath)
 		return buf.String(), i, lang, nil
 	}
 }
 
-func codeLangByExt(p string) string {
-	ext := strings.ToLower(path.Ext(p))
-	switch ext {
-	case ".ts", ".tsx":
-		return "tsx"
-	case ".js", ".jsx":
-		return "jsx"
-	case ".go":
-		return "go"
-	case ".cs":
-		return "csharp"
-	case ".py":
-		return "python"
-	case ".json":
-		return "json"
-	case ".yml", ".yaml":
-		return "yaml"
-	case ".toml":
-		return "toml"
-	case ".md", ".txt":
-		return ""
-	default:
-		return ""
-	}
-}
-
 func writeZipEntry(zw *zip.Writer, name string, data []byte) error {
 	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
 	if err != nil {
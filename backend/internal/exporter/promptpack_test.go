@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// buildPromptPack — оба прохода по одному архиву; содержимое пакета по именам файлов.
+func buildPromptPack(t *testing.T, src []byte, opts PromptPackOptions) map[string]string {
+	t.Helper()
+	var out bytes.Buffer
+	err := BuildPromptPackFromTarGz(bytes.NewReader(src), &out, opts)
+	var need *NeedSecondPassError
+	if !errors.As(err, &need) {
+		t.Fatalf("first pass: %v", err)
+	}
+	if err := FillSecondPassExcerpts(bytes.NewReader(src), need); err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	return zipContents(t, out.Bytes())
+}
+
+func TestBuildPromptPack_MarksLFSPointers(t *testing.T) {
+	src := makeTarGz(map[string]string{
+		"main.go":        "package main\n",
+		"data/users.csv": lfsPointer("id,name\n1,a\n"),
+	})
+	lfs := NewLFSFiles()
+	files := buildPromptPack(t, src, PromptPackOptions{Owner: "o", Repo: "r", Ref: "main", StripFirstDir: true, LFSFiles: lfs})
+
+	var all strings.Builder
+	for _, body := range files {
+		all.WriteString(body)
+	}
+	if !strings.Contains(all.String(), "- data/users.csv [lfs-pointer (oid sha256:") {
+		t.Fatalf("pointer is not marked in the pack:\n%s", all.String())
+	}
+	if lfs.PointerCount != 1 || lfs.Pointers[0].Reason != LFSDisabled {
+		t.Fatalf("lfs report: %+v", lfs)
+	}
+}
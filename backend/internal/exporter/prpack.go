@@ -0,0 +1,479 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/secrets"
+	"github.com/yourname/cleanhttp/internal/tokenest"
+)
+
+// Лимиты PR pack по умолчанию.
+const (
+	DefaultPRContextFiles = 20        // сколько файлов контекста (импорты/импортёры) берём
+	prContextLines        = 200       // у файлов контекста — только начало
+	maxPRFileBytes        = 1 << 20   // изменённый файл больше — обрезаем
+	maxImportScanBytes    = 256 << 10 // импорты ищем в первых N байтах файла
+	maxPRCommentBytes     = 4 << 10   // длинные комментарии обрезаем
+)
+
+// PRPackOptions — PR pack для ревью: дифф, изменённые файлы целиком, их прямые
+// импорты/импортёры и обсуждение. Архив — состояние head-коммита PR.
+type PRPackOptions struct {
+	Owner, Repo     string
+	PR              githubclient.PullRequest
+	Files           []githubclient.PullFile
+	Comments        []githubclient.PullComment
+	ModelID         string
+	TokenBudget     int              // 0 — по модели, иначе 200k
+	ReservePct      int              // 0 — 10%
+	OverlapTokens   int              // перекрытие чанков; 0 — 150
+	MaxContextFiles int              // 0 — DefaultPRContextFiles; <0 — без контекста
+	MaskSecrets     bool             // маскировать секреты в диффе и файлах
+	MaskPII         bool             // маскировать персональные данные
+	SecretStrategy  secrets.Strategy // как маскировать (дефолт: REDACTED)
+	SecretBaseline  secrets.Baseline // принятые находки — не маскируем
+	StripFirstDir   bool             // отрезать первый сегмент (owner-repo-<hash>/)
+	// Reopen — архив ещё раз: контекст известен только после полного прохода.
+	// nil — контекст только списком путей, без содержимого.
+	Reopen func() (io.ReadCloser, error)
+}
+
+// prContext — файл контекста и его связи с изменёнными файлами.
+type prContext struct {
+	Path       string   `json:"path"`
+	ImportedBy []string `json:"importedBy,omitempty"` // изменённые файлы, которые его импортируют
+	Imports    []string `json:"imports,omitempty"`    // изменённые файлы, которые он импортирует
+}
+
+// BuildPRPackFromTarGz — zip: PRPack-<n>.md (приоритет бюджета: дифф → изменённые
+// файлы → контекст; что не влезло — в chunk-NNN.md), pr.diff целиком и pr.json.
+func BuildPRPackFromTarGz(src io.Reader, dst io.Writer, opts PRPackOptions) error {
+	if opts.ReservePct == 0 {
+		opts.ReservePct = 10
+	}
+	if opts.OverlapTokens <= 0 {
+		opts.OverlapTokens = 150
+	}
+	if opts.MaxContextFiles == 0 {
+		opts.MaxContextFiles = DefaultPRContextFiles
+	}
+
+	pl := tokenest.NewPlanner(tokenest.DefaultRegistry())
+	total, _, usable := pl.Budget(string(ProfileFull), opts.ModelID)
+	if opts.TokenBudget > 0 {
+		total = opts.TokenBudget
+		usable = total - total*opts.ReservePct/100
+	}
+	st := &packState{
+		owner:         opts.Owner,
+		repo:          opts.Repo,
+		ref:           opts.PR.Head.SHA,
+		nowUTC:        time.Now().UTC(),
+		modelID:       opts.ModelID,
+		est:           tokenest.NewEstimator(),
+		planner:       pl,
+		totalTokens:   total,
+		usableTokens:  usable,
+		overlapTokens: opts.OverlapTokens,
+		mainMaxTokens: usable,
+		maskSecrets:   opts.MaskSecrets || opts.MaskPII,
+		stripFirstDir: opts.StripFirstDir,
+	}
+	if st.maskSecrets {
+		st.scanner = secrets.NewScanner(secrets.Config{
+			Strategy:  opts.SecretStrategy,
+			PII:       opts.MaskPII,
+			NoSecrets: !opts.MaskSecrets,
+			Baseline:  opts.SecretBaseline,
+		})
+	}
+
+	// изменённые файлы, которые есть в head (удалённые — только в диффе)
+	touched := map[string]bool{}
+	for _, f := range opts.Files {
+		if f.Status != "removed" {
+			touched[f.Filename] = true
+		}
+	}
+
+	// 1) проход по архиву: граф импортов + содержимое изменённых файлов
+	g := newImportGraph()
+	contents := map[string]string{}
+	if err := st.walkTar(src, func(rel string, size int64, r io.Reader) error {
+		if !touched[rel] && importLang(rel) == "" && path.Base(rel) != "go.mod" {
+			g.addFile(rel, "") // не код — только в список файлов для резолва
+			return nil
+		}
+		limit := int64(maxImportScanBytes)
+		if touched[rel] {
+			limit = maxPRFileBytes
+		}
+		sample, err := io.ReadAll(io.LimitReader(r, min(size, limit)))
+		if err != nil {
+			return err
+		}
+		text := filters.SniffKind(sample) == filters.KindText
+		if !text {
+			g.addFile(rel, "")
+			return nil
+		}
+		g.addFile(rel, string(sample))
+		if touched[rel] {
+			c := string(sample)
+			if size > limit {
+				c += "\n… (truncated)\n"
+			}
+			contents[rel] = c
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	g.resolve()
+	ctxFiles := prContextFiles(g, touched, opts.MaxContextFiles)
+
+	// 2) второй проход — начало файлов контекста
+	excerpts := map[string]string{}
+	if len(ctxFiles) > 0 && opts.Reopen != nil {
+		want := map[string]bool{}
+		for _, c := range ctxFiles {
+			want[c.Path] = true
+		}
+		rc, err := opts.Reopen()
+		if err != nil {
+			return err
+		}
+		err = st.walkTar(rc, func(rel string, size int64, r io.Reader) error {
+			if !want[rel] {
+				return nil
+			}
+			// отступы важны — readFirstLines (для README) тут не годится
+			var b strings.Builder
+			sc := bufio.NewScanner(r)
+			sc.Buffer(make([]byte, 0, 64*1024), 2*1024*1024)
+			for n := 0; n < prContextLines && sc.Scan(); n++ {
+				b.WriteString(strings.TrimSuffix(sc.Text(), "\r") + "\n")
+			}
+			if b.Len() > 0 {
+				excerpts[rel] = b.String()
+			}
+			return nil
+		})
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	// 3) сборка: заголовочные секции всегда в главном файле, дальше — по приоритету
+	zw := zip.NewWriter(dst)
+	var main bytes.Buffer
+	writeMain := func(s string) {
+		main.WriteString(s)
+		st.mainUsedTokens += st.est.CountTokens(s, st.modelID)
+	}
+	writeMain(renderPRHeader(opts, st.nowUTC))
+	writeMain(renderPRComments(opts.Comments))
+
+	var diff strings.Builder
+	writeMain("## 03_DIFF\n\n")
+	for _, f := range opts.Files {
+		d := st.mask(f.Filename, fileDiff(f))
+		diff.WriteString(d)
+		block := fmt.Sprintf("### DIFF: %s (%s, +%d −%d)\n```diff\n%s```\n\n", f.Filename, f.Status, f.Additions, f.Deletions, d)
+		st.placeBlock(&main, chunkFile{Path: f.Filename, Lines: strings.Count(d, "\n"), Language: "diff"}, block)
+	}
+
+	writeMain("## 04_CHANGED_FILES\n\n")
+	for _, f := range opts.Files {
+		c, ok := contents[f.Filename]
+		if !ok {
+			continue // удалён, бинарный или вне архива
+		}
+		c = st.mask(f.Filename, c)
+		lines := strings.Count(c, "\n")
+		lang := filters.FenceByPath(f.Filename)
+		block := fmt.Sprintf("### FILE: %s (%d lines)\n```%s\n%s```\n\n", f.Filename, lines, lang, c)
+		st.placeBlock(&main, chunkFile{Path: f.Filename, Lines: lines, Language: lang}, block)
+	}
+
+	writeMain(renderPRContextList(ctxFiles))
+	for _, c := range ctxFiles {
+		seg, ok := excerpts[c.Path]
+		if !ok {
+			continue
+		}
+		seg = st.mask(c.Path, seg)
+		lines := strings.Count(seg, "\n")
+		lang := filters.FenceByPath(c.Path)
+		block := fmt.Sprintf("### CONTEXT: %s (first %d lines)\n```%s\n%s```\n\n", c.Path, lines, lang, seg)
+		st.placeBlock(&main, chunkFile{Path: c.Path, Lines: lines, Language: lang}, block)
+	}
+
+	if err := writeZipEntry(zw, fmt.Sprintf("PRPack-%d.md", opts.PR.Number), main.Bytes()); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	if err := st.writeChunks(zw); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	if err := writeZipEntry(zw, "pr.diff", []byte(diff.String())); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	meta, err := json.MarshalIndent(map[string]any{
+		"pr":       opts.PR,
+		"files":    prFileList(opts.Files),
+		"comments": opts.Comments,
+		"context":  ctxFiles,
+	}, "", "  ")
+	if err != nil {
+		_ = zw.Close()
+		return err
+	}
+	if err := writeZipEntry(zw, "pr.json", meta); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	if err := writeSecretsReport(zw, st.scanner, nil); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// walkTar — обычные файлы архива с нормализованным путём.
+// Непрочитанный остаток файла tar пропустит сам на Next().
+func (st *packState) walkTar(src io.Reader, fn func(rel string, size int64, r io.Reader) error) error {
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := hdr.Name
+		if st.stripFirstDir {
+			name = stripFirstDir(name)
+		}
+		rel, err := filters.NormalizeRel(name)
+		if err != nil || rel == "" {
+			continue
+		}
+		if err := fn(rel, hdr.Size, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// mask — текст через сканер секретов (см. maskText), с учётом замаскированных строк.
+func (st *packState) mask(rel, text string) string {
+	out, n := maskText(st.scanner, rel, text)
+	st.maskedLines += n
+	return out
+}
+
+// prContextFiles — прямые импорты и импортёры изменённых файлов (кроме них самих).
+// Сначала те, что связаны с бо́льшим числом изменённых файлов.
+func prContextFiles(g *importGraph, touched map[string]bool, limit int) []prContext {
+	if limit < 0 {
+		return nil
+	}
+	byPath := map[string]*prContext{}
+	get := func(p string) *prContext {
+		c, ok := byPath[p]
+		if !ok {
+			c = &prContext{Path: p}
+			byPath[p] = c
+		}
+		return c
+	}
+	for t := range touched {
+		for _, p := range g.imports[t] {
+			if !touched[p] {
+				c := get(p)
+				c.ImportedBy = append(c.ImportedBy, t)
+			}
+		}
+		for _, p := range g.importer[t] {
+			if !touched[p] {
+				c := get(p)
+				c.Imports = append(c.Imports, t)
+			}
+		}
+	}
+	out := make([]prContext, 0, len(byPath))
+	for _, c := range byPath {
+		sort.Strings(c.ImportedBy)
+		sort.Strings(c.Imports)
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ni := len(out[i].ImportedBy) + len(out[i].Imports)
+		nj := len(out[j].ImportedBy) + len(out[j].Imports)
+		if ni != nj {
+			return ni > nj
+		}
+		return out[i].Path < out[j].Path
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// fileDiff — дифф файла в формате git (GitHub отдаёт только хунки).
+func fileDiff(f githubclient.PullFile) string {
+	from := f.Filename
+	if f.PreviousFilename != "" {
+		from = f.PreviousFilename
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", from, f.Filename)
+	switch f.Status {
+	case "added":
+		b.WriteString("new file\n")
+	case "removed":
+		b.WriteString("deleted file\n")
+	case "renamed":
+		fmt.Fprintf(&b, "rename from %s\nrename to %s\n", from, f.Filename)
+	}
+	if f.Patch == "" {
+		b.WriteString("(патча нет: бинарный файл, переименование без правок или слишком большой дифф)\n")
+		return b.String()
+	}
+	a, z := "a/"+from, "b/"+f.Filename
+	if f.Status == "added" {
+		a = "/dev/null"
+	}
+	if f.Status == "removed" {
+		z = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n%s", a, z, f.Patch)
+	if !strings.HasSuffix(f.Patch, "\n") {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func renderPRHeader(opts PRPackOptions, now time.Time) string {
+	pr := opts.PR
+	state := pr.State
+	switch {
+	case pr.Merged:
+		state = "merged"
+	case pr.Draft:
+		state += ", draft"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# PR #%d: %s\n\n", pr.Number, pr.Title)
+	fmt.Fprintf(&b, "- **Репозиторий**: %s/%s\n", opts.Owner, opts.Repo)
+	fmt.Fprintf(&b, "- **Автор**: @%s\n", pr.User.Login)
+	fmt.Fprintf(&b, "- **Ветки**: %s ← %s (head %s)\n", pr.Base.Label, pr.Head.Label, shortSHA(pr.Head.SHA))
+	fmt.Fprintf(&b, "- **Статус**: %s\n", state)
+	fmt.Fprintf(&b, "- **Изменения**: %d файлов, +%d −%d\n", len(opts.Files), pr.Additions, pr.Deletions)
+	if pr.HTMLURL != "" {
+		fmt.Fprintf(&b, "- **Ссылка**: %s\n", pr.HTMLURL)
+	}
+	fmt.Fprintf(&b, "- **Сгенерировано**: %s\n\n", now.Format(time.RFC3339))
+
+	b.WriteString("## 01_DESCRIPTION\n\n")
+	if body := strings.TrimSpace(pr.Body); body != "" {
+		b.WriteString(body + "\n\n")
+	} else {
+		b.WriteString("_Описание пустое_\n\n")
+	}
+	return b.String()
+}
+
+func renderPRComments(comments []githubclient.PullComment) string {
+	var b strings.Builder
+	b.WriteString("## 02_REVIEW_COMMENTS\n\n")
+	if len(comments) == 0 {
+		b.WriteString("_Комментариев нет_\n\n")
+		return b.String()
+	}
+	for _, c := range comments {
+		where := c.Kind
+		switch {
+		case c.Kind == githubclient.CommentReview && c.State != "":
+			where += ", " + c.State
+		case c.Kind == githubclient.CommentInline && c.Line > 0:
+			where = fmt.Sprintf("%s:%d", c.Path, c.Line)
+		case c.Kind == githubclient.CommentInline:
+			where = c.Path + " (устаревший дифф)"
+		}
+		body := strings.TrimSpace(c.Body)
+		if len(body) > maxPRCommentBytes {
+			body = body[:maxPRCommentBytes] + "…"
+		}
+		fmt.Fprintf(&b, "- **@%s** (%s, %s):\n", c.Author, where, c.CreatedAt.Format("2006-01-02"))
+		for _, ln := range strings.Split(body, "\n") {
+			b.WriteString("  > " + ln + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func renderPRContextList(ctx []prContext) string {
+	var b strings.Builder
+	b.WriteString("## 05_CONTEXT\n\n")
+	if len(ctx) == 0 {
+		b.WriteString("_Прямых импортов/импортёров в репозитории не найдено_\n\n")
+		return b.String()
+	}
+	for _, c := range ctx {
+		var rel []string
+		if len(c.ImportedBy) > 0 {
+			rel = append(rel, "импортируется из "+strings.Join(c.ImportedBy, ", "))
+		}
+		if len(c.Imports) > 0 {
+			rel = append(rel, "импортирует "+strings.Join(c.Imports, ", "))
+		}
+		fmt.Fprintf(&b, "- %s — %s\n", c.Path, strings.Join(rel, "; "))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// prFileList — файлы PR для pr.json без патчей (они в pr.diff).
+func prFileList(files []githubclient.PullFile) []githubclient.PullFile {
+	out := make([]githubclient.PullFile, len(files))
+	for i, f := range files {
+		f.Patch = ""
+		out[i] = f
+	}
+	return out
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func TestBuildPRPack_PriorityAndContext(t *testing.T) {
+	src := makeTarGzSorted(map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.22\n",
+		"cmd/app/main.go":      "package main\n\nimport \"example.com/app/internal/svc\"\n\nfunc main() { svc.Run() }\n",
+		"internal/svc/svc.go":  "package svc\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/util\"\n)\n\nfunc Run() { fmt.Println(util.X) }\n",
+		"internal/util/x.go":   "package util\n\nconst X = 1\n",
+		"web/src/app.ts":       "import { f } from './lib'\n",
+		"web/src/lib/index.ts": "export const f = 1\n",
+		"README.md":            "readme",
+	})
+	opts := PRPackOptions{
+		Owner: "o", Repo: "app",
+		PR: githubclient.PullRequest{Number: 7, Title: "Tweak svc", Body: "Makes Run louder."},
+		Files: []githubclient.PullFile{
+			{Filename: "internal/svc/svc.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -8 +8 @@\n-func Run() {}\n+func Run() { fmt.Println(util.X) }"},
+			{Filename: "old.txt", Status: "removed", Deletions: 1, Patch: "@@ -1 +0,0 @@\n-bye"},
+		},
+		Comments:      []githubclient.PullComment{{Kind: githubclient.CommentInline, Author: "rev", Body: "why?", Path: "internal/svc/svc.go", Line: 8}},
+		StripFirstDir: true,
+		Reopen:        func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(src)), nil },
+	}
+	var out bytes.Buffer
+	if err := BuildPRPackFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	c := zipContents(t, out.Bytes())
+	md := c["PRPack-7.md"]
+	for _, want := range []string{"# PR #7: Tweak svc", "Makes Run louder.", "@rev", "internal/svc/svc.go:8"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("PRPack-7.md has no %q:\n%s", want, md)
+		}
+	}
+	// дифф → изменённые файлы → контекст (импортёр и импортируемый пакет)
+	iDiff := strings.Index(md, "### DIFF: internal/svc/svc.go")
+	iFile := strings.Index(md, "### FILE: internal/svc/svc.go")
+	iCtx1 := strings.Index(md, "### CONTEXT: cmd/app/main.go")
+	iCtx2 := strings.Index(md, "### CONTEXT: internal/util/x.go")
+	if iDiff < 0 || iFile < iDiff || iCtx1 < iFile || iCtx2 < iFile {
+		t.Fatalf("sections out of order (%d %d %d %d):\n%s", iDiff, iFile, iCtx1, iCtx2, md)
+	}
+	if strings.Contains(md, "web/src") {
+		t.Fatalf("unrelated files in context:\n%s", md)
+	}
+	if d := c["pr.diff"]; !strings.Contains(d, "--- a/old.txt\n+++ /dev/null") || !strings.Contains(d, "diff --git a/internal/svc/svc.go b/internal/svc/svc.go") {
+		t.Fatalf("pr.diff:\n%s", d)
+	}
+	if _, ok := c["pr.json"]; !ok {
+		t.Fatal("no pr.json")
+	}
+}
+
+func TestImportGraph_JSAndPython(t *testing.T) {
+	g := newImportGraph()
+	g.addFile("web/app.ts", "import a from './lib'\nconst b = require('../shared/b.js')\nimport 'react'\n")
+	g.addFile("web/lib/index.tsx", "")
+	g.addFile("shared/b.js", "")
+	g.addFile("pkg/mod.py", "from . import helpers\nfrom pkg.sub import thing\nimport os\n")
+	g.addFile("pkg/helpers.py", "")
+	g.addFile("pkg/sub/__init__.py", "")
+	g.resolve()
+	if got := strings.Join(g.imports["web/app.ts"], ","); got != "shared/b.js,web/lib/index.tsx" {
+		t.Fatalf("js imports: %s", got)
+	}
+	if got := strings.Join(g.imports["pkg/mod.py"], ","); got != "pkg/helpers.py,pkg/sub/__init__.py" {
+		t.Fatalf("py imports: %s", got)
+	}
+}
@@ -0,0 +1,144 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/treecache"
+)
+
+// maxRuleFileBytes — файлы правил крупнее этого не разбираем (скорее всего, это не .gitignore).
+const maxRuleFileBytes = 256 * 1024
+
+// maxRuleFiles — сколько файлов правил FetchRepoRules скачивает заранее;
+// остальные билдер подхватит по ходу чтения архива.
+const maxRuleFiles = 100
+
+// ruleFetchWorkers — сколько файлов правил качаем параллельно.
+const ruleFetchWorkers = 8
+
+// RepoRulesSource — что нужно FetchRepoRules от GitHub-клиента (*githubclient.Client).
+type RepoRulesSource interface {
+	GetTree(ctx context.Context, owner, repo, ref string) ([]githubclient.TreeItem, error)
+	GetRawFile(ctx context.Context, owner, repo, pth, ref string, maxBytes int64) ([]byte, bool, error)
+}
+
+// RepoRulesCache — где FetchRepoRules хранит скачанные правила коммита
+// (treecache.TreeCache: общий Redis или память процесса).
+type RepoRulesCache interface {
+	GetFiles(ctx context.Context, key string) (map[string]string, bool)
+	SetFiles(ctx context.Context, key string, files map[string]string)
+}
+
+// FetchRepoRules — все файлы правил репозитория (.gitignore, .gitattributes,
+// .rep2promptignore) по дереву, до чтения архива: так правила действуют и на
+// файлы, которые в tar идут раньше своего .gitignore (см. filters.RepoIgnore).
+// Мелкими каталогами вперёд — глубже = позже = приоритетнее. Ошибка — только
+// если не удалось получить дерево; отдельные файлы, которые не скачались,
+// пропускаем: билдер прочтёт их из архива.
+//
+// Файлы качаются параллельно (ruleFetchWorkers). Если ref — SHA коммита и
+// cache не nil, правила кэшируются: повторный экспорт того же коммита не
+// тратит на них ни одного запроса к GitHub.
+func FetchRepoRules(ctx context.Context, src RepoRulesSource, cache RepoRulesCache, owner, repo, ref string) (*filters.RepoIgnore, error) {
+	var key string
+	if cache != nil && githubclient.IsCommitSHA(ref) {
+		key = treecache.RulesKey(githubclient.TokenScope(ctx), owner, repo, ref)
+		if files, ok := cache.GetFiles(ctx, key); ok {
+			return rulesFromFiles(files), nil
+		}
+	}
+
+	tree, err := src.GetTree(ctx, owner, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, it := range tree {
+		if it.Type == "file" && filters.IsRuleFile(it.Path) {
+			paths = append(paths, path.Clean(it.Path))
+		}
+	}
+	if len(paths) > maxRuleFiles {
+		sortRulePaths(paths)
+		paths = paths[:maxRuleFiles]
+	}
+
+	files := make(map[string]string, len(paths))
+	complete := true // все файлы скачаны целиком — можно кэшировать
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, ruleFetchWorkers)
+	for _, p := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			b, truncated, err := src.GetRawFile(ctx, owner, repo, p, ref, maxRuleFileBytes)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				complete = false
+			case !truncated: // обрезанный — не правила, и в следующий раз будет так же
+				files[p] = string(b)
+			}
+		}(p)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key != "" && complete {
+		cache.SetFiles(ctx, key, files)
+	}
+	return rulesFromFiles(files), nil
+}
+
+// rulesFromFiles — правила в порядке применения: мелкими каталогами вперёд.
+func rulesFromFiles(files map[string]string) *filters.RepoIgnore {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sortRulePaths(paths)
+	ign := filters.NewRepoIgnore()
+	for _, p := range paths {
+		ign.Add(p, []byte(files[p]))
+	}
+	return ign
+}
+
+// sortRulePaths — по глубине каталога, затем по пути.
+func sortRulePaths(paths []string) {
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "/"), strings.Count(paths[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return paths[i] < paths[j]
+	})
+}
+
+// readRepoRules — если rel — файл правил репозитория (.gitignore, .gitattributes,
+// .rep2promptignore), читает его, регистрирует правила в ign и возвращает reader,
+// который заново отдаёт прочитанное (сам файл экспортируется как обычно).
+// Иначе — r как есть.
+func readRepoRules(ign *filters.RepoIgnore, rel string, r io.Reader) io.Reader {
+	if ign == nil || !filters.IsRuleFile(rel) {
+		return r
+	}
+	buf, err := io.ReadAll(io.LimitReader(r, maxRuleFileBytes))
+	if err == nil && len(buf) < maxRuleFileBytes {
+		ign.Add(rel, buf)
+	}
+	return io.MultiReader(bytes.NewReader(buf), r)
+}
@@ -0,0 +1,129 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/treecache"
+)
+
+// fakeRules — дерево и файлы правил в памяти (RepoRulesSource).
+type fakeRules map[string]string
+
+func (f fakeRules) GetTree(context.Context, string, string, string) ([]githubclient.TreeItem, error) {
+	var out []githubclient.TreeItem
+	for p := range f {
+		out = append(out, githubclient.TreeItem{Path: p, Type: "file"})
+	}
+	return out, nil
+}
+
+func (f fakeRules) GetRawFile(_ context.Context, _, _, pth, _ string, _ int64) ([]byte, bool, error) {
+	return []byte(f[pth]), false, nil
+}
+
+// git archive кладёт ".github/" и ".editorconfig" раньше ".gitignore": без
+// заранее собранных правил они проскакивают, с FetchRepoRules — нет.
+func TestFetchRepoRules_AppliesToFilesBeforeTheirGitignore(t *testing.T) {
+	files := map[string]string{
+		".editorconfig":            "root = true\n",
+		".github/workflows/ci.yml": "on: push\n",
+		".gitignore":               ".github/\n.editorconfig\n",
+		"main.go":                  "package main\n",
+		"web/-draft.md":            "draft\n",
+		"web/.gitignore":           "-draft.md\n",
+		"web/app.ts":               "export {}\n",
+	}
+	src := makeTarGzSorted(files)
+	build := func(opts Options) []string {
+		var out bytes.Buffer
+		opts.StripFirstDir, opts.UseRepoIgnore = true, true
+		if err := BuildZipFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+			t.Fatal(err)
+		}
+		return zipEntries(t, out.Bytes())
+	}
+
+	// по ходу чтения архива правила опаздывают — это и чинит FetchRepoRules
+	if got := strings.Join(build(Options{}), " "); !strings.Contains(got, ".github/workflows/ci.yml") {
+		t.Fatalf("expected the streaming-only limitation to hold, got %s", got)
+	}
+
+	rules, err := FetchRepoRules(context.Background(), fakeRules{".gitignore": files[".gitignore"], "web/.gitignore": files["web/.gitignore"]}, nil, "o", "r", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(build(Options{RepoRules: rules}), " ")
+	want := ".gitignore main.go web/.gitignore web/app.ts"
+	if got != want {
+		t.Fatalf("entries %q, want %q", got, want)
+	}
+}
+
+// countingRules — fakeRules со счётчиком запросов и сбоем на одном файле.
+type countingRules struct {
+	fakeRules
+	calls   atomic.Int32
+	failing string
+}
+
+func (f *countingRules) GetTree(ctx context.Context, owner, repo, ref string) ([]githubclient.TreeItem, error) {
+	f.calls.Add(1)
+	return f.fakeRules.GetTree(ctx, owner, repo, ref)
+}
+
+func (f *countingRules) GetRawFile(ctx context.Context, owner, repo, pth, ref string, maxBytes int64) ([]byte, bool, error) {
+	f.calls.Add(1)
+	if pth == f.failing {
+		return nil, false, errors.New("upstream")
+	}
+	return f.fakeRules.GetRawFile(ctx, owner, repo, pth, ref, maxBytes)
+}
+
+func TestFetchRepoRules_CachedByCommit(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	ctx := context.Background()
+	cache := treecache.NewMemory(time.Hour, 0)
+	src := &countingRules{fakeRules: fakeRules{".gitignore": "*.log\n", "web/.gitignore": "dist/\n", "main.go": ""}}
+	fetch := func(ref string) {
+		t.Helper()
+		ign, err := FetchRepoRules(ctx, src, cache, "o", "r", ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := ign.Ignored("web/dist/app.js"); !ok {
+			t.Fatal("web/.gitignore not applied")
+		}
+	}
+
+	fetch(sha)
+	if n := src.calls.Load(); n != 3 {
+		t.Fatalf("first fetch: %d requests, want 3 (tree + 2 rule files)", n)
+	}
+	fetch(sha)
+	if n := src.calls.Load(); n != 3 {
+		t.Fatalf("cached fetch: %d requests, want none", n)
+	}
+	// ветку (не SHA) не кэшируем — она может сдвинуться
+	fetch("main")
+	fetch("main")
+	if n := src.calls.Load(); n != 9 {
+		t.Fatalf("branch fetches: %d requests, want 9", n)
+	}
+
+	// файл не скачался — неполные правила не кэшируем
+	src.failing = ".gitignore"
+	src.calls.Store(0)
+	other := "fedcba9876543210fedcba9876543210fedcba98"
+	fetch(other)
+	fetch(other)
+	if n := src.calls.Load(); n != 6 {
+		t.Fatalf("partial fetch: %d requests, want 6 (not cached)", n)
+	}
+}
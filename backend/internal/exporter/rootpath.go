@@ -0,0 +1,15 @@
+package exporter
+
+import "strings"
+
+// reRoot — путь rel относительно каталога root (уже нормализованного, без "/" по краям).
+// false — файл вне поддерева. Пустой root — весь репозиторий как есть.
+func reRoot(rel, root string) (string, bool) {
+	if root == "" {
+		return rel, true
+	}
+	if !strings.HasPrefix(rel, root+"/") {
+		return "", false
+	}
+	return rel[len(root)+1:], true
+}
@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+
+	"github.com/yourname/cleanhttp/internal/secrets"
+)
+
+// SecretsReportName — отчёт сканера (без самих значений): что и где замаскировано,
+// сколько подавлено allow-комментариями и baseline, какие файлы выброшены
+// целиком. В zip и паках — отдельным файлом, в txt — блоком перед манифестом.
+const SecretsReportName = "secrets-report.json"
+
+func encodeSecretsReport(sc *secrets.Scanner) ([]byte, error) {
+	return json.MarshalIndent(sc.Report(), "", "  ")
+}
+
+// writeSecretsReport — отчёт отдельным файлом в zip; dropped — пути, выброшенные
+// DropSecretFiles (отчёт пишем и без сканера). Нечего сообщить — ничего не пишем.
+func writeSecretsReport(zw *zip.Writer, sc *secrets.Scanner, dropped []string) error {
+	if sc == nil && len(dropped) == 0 {
+		return nil
+	}
+	rep := secrets.SecretReport{ByKind: map[secrets.Kind]int{}}
+	if sc != nil {
+		rep = sc.Report()
+	}
+	rep.DroppedFiles = dropped
+	b, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipEntry(zw, SecretsReportName, b)
+}
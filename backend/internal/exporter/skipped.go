@@ -0,0 +1,42 @@
+package exporter
+
+// SkipSecretFile — причина пропуска для DropSecretFiles (.env, id_rsa, *.pem …).
+const SkipSecretFile = "secret-file"
+
+// maxSkippedPerReason — сколько путей на причину храним поимённо (счётчик — всегда полный).
+const maxSkippedPerReason = 200
+
+// SkippedFiles — сводка файлов, пропущенных экспортом, по причинам
+// (generated/vendored/minified/lockfile, gitignore, export-ignore, ...).
+// Уходит в Manifest пакета и в метаданные артефакта. nil — не собираем.
+type SkippedFiles struct {
+	Counts map[string]int      `json:"counts"`
+	Paths  map[string][]string `json:"paths"`
+}
+
+func NewSkippedFiles() *SkippedFiles {
+	return &SkippedFiles{Counts: map[string]int{}, Paths: map[string][]string{}}
+}
+
+// Add — учесть пропуск файла rel по причине reason.
+func (s *SkippedFiles) Add(rel, reason string) {
+	if s == nil {
+		return
+	}
+	s.Counts[reason]++
+	if len(s.Paths[reason]) < maxSkippedPerReason {
+		s.Paths[reason] = append(s.Paths[reason], rel)
+	}
+}
+
+// Total — всего пропущено файлов.
+func (s *SkippedFiles) Total() int {
+	if s == nil {
+		return 0
+	}
+	n := 0
+	for _, c := range s.Counts {
+		n += c
+	}
+	return n
+}
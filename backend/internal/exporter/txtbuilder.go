@@ -11,21 +11,34 @@ import (
 	"strings"
 
 	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
 	"github.com/yourname/cleanhttp/internal/secrets"
 )
 
 // TxtOptions — параметры экспорта TXT.
 type TxtOptions struct {
-	IncludeGlobs    []string         // маски include
-	ExcludeGlobs    []string         // маски exclude
-	StripFirstDir   bool             // срезать первый сегмент (у GitHub tar это repo-<sha>/...)
-	LineNumbers     bool             // печатать "N\tстрока"
-	HeaderTemplate  string           // заголовок перед каждым файлом: поддерживает {path} и {n}
-	MaxLinesPerFile int              // 0 = без обрезки; иначе ограничиваем строки на файл
-	MaxExportMB     int              // общий лимит выходного TXT (в мегабайтах); 0 = без лимита
-	SkipBinaries    bool             // пропускать «бинарные» файлы (эвристика)
-	SecretScan      bool             // включить сканирование (дефолт: true)
-	SecretStrategy  secrets.Strategy // стратегия (дефолт: REDACTED)
+	IncludeGlobs    []string            // маски include
+	ExcludeGlobs    []string            // маски exclude
+	StripFirstDir   bool                // срезать первый сегмент (у GitHub tar это repo-<sha>/...)
+	RootPath        string              // только это поддерево; в заголовках пути относительно него
+	LineNumbers     bool                // печатать "N\tстрока"
+	HeaderTemplate  string              // заголовок перед каждым файлом: поддерживает {path} и {n}
+	MaxLinesPerFile int                 // 0 = без обрезки; иначе ограничиваем строки на файл
+	MaxExportMB     int                 // общий лимит выходного TXT (в мегабайтах); 0 = без лимита
+	SkipBinaries    bool                // пропускать «бинарные» файлы (эвристика)
+	SecretScan      bool                // включить сканирование (дефолт: true)
+	SecretStrategy  secrets.Strategy    // стратегия (дефолт: REDACTED)
+	PIIScan         bool                // маскировать персональные данные (тем же SecretStrategy)
+	SecretBaseline  secrets.Baseline    // принятые находки — не маскируем
+	UseRepoIgnore   bool                // учитывать .gitignore, .gitattributes и .rep2promptignore из репозитория
+	RepoRules       *filters.RepoIgnore // правила, собранные заранее (FetchRepoRules); nil — только по ходу чтения архива
+	SkipGenerated   bool                // пропускать сгенерированное, vendored, минифицированное и lockfiles
+	Skipped         *SkippedFiles       // куда записывать пропущенные файлы с причинами (nil — не собираем)
+	Kinds           FileKinds           // сколько файлов какого вида экспортировано (nil — не собираем)
+	LFS             *LFSResolver        // скачивать объекты Git LFS вместо pointer'ов (nil — нет)
+	LFSFiles        *LFSFiles           // куда записывать pointer'ы Git LFS и скачанные объекты (nil — не собираем)
+	Changes         *ChangeSet          // diff-режим: только изменённые файлы, сводка и дифф (nil — всё дерево)
+	Manifest        *Manifest           // отчёт экспорта — последним блоком (nil — не пишем)
 }
 
 // BuildTxtFromTarGz — конвертит tar.gz поток в «плоский» TXT.
@@ -39,17 +52,35 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 	if opts.MaxLinesPerFile < 0 {
 		opts.MaxLinesPerFile = 0
 	}
+	matcher, err := filters.NewMatcher(opts.IncludeGlobs, opts.ExcludeGlobs)
+	if err != nil {
+		return err
+	}
 	// Управление сканированием секретов: если опция включена — создаём сканер.
-	doScan := opts.SecretScan
+	doScan := opts.SecretScan || opts.PIIScan
 	strategy := opts.SecretStrategy
 	if strategy != secrets.StrategyRedacted &&
 		strategy != secrets.StrategyStrip &&
-		strategy != secrets.StrategyMark {
+		strategy != secrets.StrategyMark &&
+		strategy != secrets.StrategyPseudonymize {
 		strategy = secrets.StrategyRedacted
 	}
 	var scanner *secrets.Scanner
 	if doScan {
-		scanner = secrets.NewScanner(secrets.Config{Strategy: strategy})
+		scanner = secrets.NewScanner(secrets.Config{
+			Strategy:  strategy,
+			PII:       opts.PIIScan,
+			NoSecrets: !opts.SecretScan,
+			Baseline:  opts.SecretBaseline,
+		})
+	}
+
+	var repoIgn *filters.RepoIgnore
+	if opts.UseRepoIgnore {
+		repoIgn = opts.RepoRules
+		if repoIgn == nil {
+			repoIgn = filters.NewRepoIgnore()
+		}
 	}
 
 	// распаковка gzip → tar.Reader
@@ -77,6 +108,13 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 		return err
 	}
 
+	// diff-режим: сводка изменений перед файлами
+	if opts.Changes != nil {
+		if err := write([]byte(renderTxtChanges(opts.Changes, opts.RootPath))); err != nil {
+			return err
+		}
+	}
+
 	// сколько байт берём для эвристики «бинарности»
 	const sampleN = 4096
 
@@ -110,37 +148,97 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 			_, _ = io.CopyN(io.Discard, tr, hdr.Size)
 			continue
 		}
-		// маски include/exclude
-		if !filters.Match(rel, opts.IncludeGlobs, opts.ExcludeGlobs) {
-			_, _ = io.CopyN(io.Discard, tr, hdr.Size)
+		// правила самого репозитория (.gitignore и т.п.) — регистрируем до масок
+		body := readRepoRules(repoIgn, rel, tr)
+		// вне RootPath — мимо; правила репозитория сверяем с полным путём
+		full := rel
+		var ok bool
+		if rel, ok = reRoot(full, opts.RootPath); !ok {
+			_, _ = io.CopyN(io.Discard, body, hdr.Size)
+			continue
+		}
+		// маски include/exclude; в diff-режиме — только изменённые файлы
+		if !matcher.Match(rel) || !opts.Changes.Has(full) {
+			_, _ = io.CopyN(io.Discard, body, hdr.Size)
+			continue
+		}
+		// игнорируемое/сгенерированное по мнению самого репозитория
+		if reason, ignored := repoIgn.Ignored(full); ignored {
+			opts.Skipped.Add(rel, reason)
+			_, _ = io.CopyN(io.Discard, body, hdr.Size)
+			continue
+		}
+		// сгенерированное/стороннее по пути (vendor/, *.pb.go, lockfiles …)
+		if opts.SkipGenerated {
+			if reason, ok := filters.DetectGeneratedPath(rel); ok {
+				opts.Skipped.Add(rel, reason)
+				_, _ = io.CopyN(io.Discard, body, hdr.Size)
+				continue
+			}
+		}
+
+		// pointer Git LFS: подменяем скачанным объектом, иначе — только заголовок с пометкой
+		body, sz, ptr, err := substLFS(opts.LFS, opts.LFSFiles, rel, body, hdr.Size)
+		if err != nil {
+			return err
+		}
+		if ptr != nil {
+			header := strings.ReplaceAll(opts.HeaderTemplate, "{path}", rel+" ["+ptr.Label()+"]")
+			header = strings.ReplaceAll(header, "{n}", "0")
+			if err := write([]byte(header + "\n\n")); err != nil {
+				return err
+			}
+			opts.Kinds.Add(filters.KindLFSPointer)
 			continue
 		}
 
 		// возьмём сэмпл для детекции бинарников
-		sz := hdr.Size
 		sn := int64(sampleN)
 		if sz < sn {
 			sn = sz
 		}
 		sample := make([]byte, sn)
 		if sn > 0 {
-			if _, err := io.ReadFull(tr, sample); err != nil {
+			if _, err := io.ReadFull(body, sample); err != nil {
 				// не смогли прочитать — пропускаем файл
 				continue
 			}
 		}
-		if opts.SkipBinaries && filters.IsBinarySample(sample) {
+		// вид по сигнатуре; pointer-файлы Git LFS для текста бесполезны — тоже пропускаем
+		kind := filters.SniffKind(sample)
+		if opts.SkipBinaries && kind != filters.KindText {
+			opts.Skipped.Add(rel, kind)
 			// слить остаток файла
 			if remain := sz - sn; remain > 0 {
-				_, _ = io.CopyN(io.Discard, tr, remain)
+				_, _ = io.CopyN(io.Discard, body, remain)
 			}
 			continue
 		}
+		// ...и по содержимому: "Code generated … DO NOT EDIT", минифицированные бандлы
+		if opts.SkipGenerated {
+			if reason, ok := filters.DetectGeneratedContent(rel, sample); ok {
+				opts.Skipped.Add(rel, reason)
+				if remain := sz - sn; remain > 0 {
+					_, _ = io.CopyN(io.Discard, body, remain)
+				}
+				continue
+			}
+		}
 
 		// Теперь готовим построчное чтение:
 		//   объединяем (сначала sample, потом остальное тело файла)
-		rest := &countReader{R: tr}                             // считаем, сколько дочитали из tar после sample
+		rest := &countReader{R: body}                           // считаем, сколько дочитали из tar после sample
 		reader := io.MultiReader(bytes.NewReader(sample), rest) // повторим sample перед «хвостом»
+		// структурированные конфиги сканируем целиком (ключи password:/token: и т.п.)
+		var byLine map[int][]secrets.Finding
+		if scanner != nil && secrets.DetectFormat(rel) != secrets.FormatNone && sz <= secrets.MaxBlobBytes {
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				continue
+			}
+			byLine = blobFindings(scanner, rel, content)
+			reader = bytes.NewReader(content)
+		}
 		sc := bufio.NewScanner(reader)
 		// позволим длинные строки (до ~10 МБ)
 		sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
@@ -159,7 +257,10 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 			outLine := raw
 			if scanner != nil {
 				lineno := lines + 1
-				finds := scanner.ScanLine(rel, raw, lineno)
+				finds := byLine[lineno]
+				if byLine == nil {
+					finds = scanner.ScanLine(rel, raw, lineno)
+				}
 				outLine = scanner.ApplyStrategy(raw, finds)
 			}
 
@@ -181,7 +282,7 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 		// чтобы корректно перейти к следующему заголовку
 		consumedFromTar := sn + rest.N // сколько байтов тела файла съели у tar
 		if remain := sz - consumedFromTar; remain > 0 {
-			_, _ = io.CopyN(io.Discard, tr, remain)
+			_, _ = io.CopyN(io.Discard, body, remain)
 		}
 		// если сканер упал с ошибкой — пропустим файл
 		if err := sc.Err(); err != nil {
@@ -204,14 +305,66 @@ func BuildTxtFromTarGz(src io.Reader, dst io.Writer, opts TxtOptions) error {
 				return err
 			}
 		}
+		// diff-режим: что именно поменялось в файле
+		if d, _ := opts.Changes.hunk(scanner, full); d != "" {
+			if err := write([]byte("\n=== DIFF: " + rel + " ===\n" + d)); err != nil {
+				return err
+			}
+		}
 		// пустая строка между файлами
 		if err := write([]byte("\n")); err != nil {
 			return err
 		}
+		opts.Kinds.Add(kind)
+	}
+	// удалённых файлов в архиве нет — только их дифф
+	for _, f := range opts.Changes.removed(opts.RootPath) {
+		d, _ := opts.Changes.hunk(scanner, f.Filename)
+		rel, _ := reRoot(f.Filename, opts.RootPath)
+		if err := write([]byte("=== DIFF: " + rel + " (removed) ===\n" + d + "\n")); err != nil {
+			return err
+		}
+	}
+	if scanner != nil {
+		b, err := encodeSecretsReport(scanner)
+		if err != nil {
+			return err
+		}
+		if err := write([]byte("=== SECRETS REPORT: " + SecretsReportName + " ===\n" + string(b) + "\n\n")); err != nil {
+			return err
+		}
+	}
+	if opts.Manifest != nil {
+		b, err := opts.Manifest.encode()
+		if err != nil {
+			return err
+		}
+		if err := write([]byte("=== MANIFEST: " + ManifestName + " ===\n" + string(b) + "\n")); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// renderTxtChanges — сводка diff-режима: статус, строки и путь каждого изменённого файла.
+func renderTxtChanges(c *ChangeSet, rootPath string) string {
+	files, add, del := c.list(rootPath)
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== CHANGES: %s...%s (%d files, +%d -%d) ===\n", c.Base, c.Head, len(files), add, del)
+	for _, f := range files {
+		p := f.Path
+		if f.From != "" {
+			p = f.From + " -> " + f.Path
+		}
+		fmt.Fprintf(&b, "%-9s +%d -%d\t%s\n", f.Status, f.Additions, f.Deletions, p)
+	}
+	if c.Truncated {
+		fmt.Fprintf(&b, "… GitHub returned only the first %d changed files\n", githubclient.MaxCompareFiles)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 // countReader — считает, сколько байт было прочитано (нужно, чтобы потом докатать остаток файла).
 type countReader struct {
 	R io.Reader
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/secrets"
 )
 
 func TestBuildTxt_TruncateAndStrip(t *testing.T) {
@@ -42,3 +45,97 @@ func TestBuildTxt_TooLargeLimit(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestBuildTxt_PseudonymizeIsStableAcrossFiles(t *testing.T) {
+	src := makeTarGz(map[string]string{
+		"a.env.txt": "API_TOKEN=s3cr3t-value-1\nOTHER_TOKEN=s3cr3t-value-2\n",
+		"b.cfg.txt": "API_TOKEN=s3cr3t-value-1\n",
+	})
+	var out bytes.Buffer
+	opts := TxtOptions{
+		StripFirstDir:  true,
+		SecretScan:     true,
+		SecretStrategy: secrets.StrategyPseudonymize,
+	}
+	if err := BuildTxtFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+		t.Fatalf("build txt: %v", err)
+	}
+	result := out.String()
+	if strings.Contains(result, "s3cr3t") {
+		t.Fatalf("secret leaked: %s", result)
+	}
+	if n := strings.Count(result, "API_TOKEN=<SECRET_"); n != 2 {
+		t.Fatalf("expected key to be kept and value replaced twice, got %d: %s", n, result)
+	}
+	first := result[strings.Index(result, "API_TOKEN=")+len("API_TOKEN="):]
+	first = first[:strings.IndexByte(first, '>')+1]
+	if strings.Count(result, first) != 2 {
+		t.Fatalf("placeholder %q must repeat for the same value: %s", first, result)
+	}
+	if strings.Count(result, "<SECRET_") != 3 {
+		t.Fatalf("expected 3 placeholders: %s", result)
+	}
+}
+
+func TestBuildTxt_SkipGeneratedListsReasons(t *testing.T) {
+	src := makeTarGz(map[string]string{
+		"main.go":                  "package main\n",
+		"api/svc.pb.go":            "package api\n",
+		"gen/enum.go":              "// Code generated by stringer; DO NOT EDIT.\n\npackage gen\n",
+		"vendor/lib/lib.go":        "package lib\n",
+		"web/app.js":               strings.Repeat("var a=1;", 600), // одна длинная строка
+		"web/package-lock.json":    "{}\n",
+		"web/static/jquery.min.js": "!function(){}\n",
+	})
+	var out bytes.Buffer
+	skipped := NewSkippedFiles()
+	opts := TxtOptions{StripFirstDir: true, SkipGenerated: true, Skipped: skipped}
+	if err := BuildTxtFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+		t.Fatalf("build txt: %v", err)
+	}
+	if !strings.Contains(out.String(), "main.go") || strings.Contains(out.String(), "package api") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+	want := map[string]int{"generated": 2, "vendored": 1, "minified": 2, "lockfile": 1}
+	for reason, n := range want {
+		if skipped.Counts[reason] != n {
+			t.Fatalf("reason %s: got %d, want %d (%+v)", reason, skipped.Counts[reason], n, skipped.Paths)
+		}
+	}
+	if skipped.Total() != 6 {
+		t.Fatalf("total: %d", skipped.Total())
+	}
+}
+
+func TestBuildTxt_DiffModeOnlyChangedFiles(t *testing.T) {
+	src := makeTarGz(map[string]string{
+		"app/main.go":   "package main\n\nfunc main() { run() }\n",
+		"app/unused.go": "package main\n",
+	})
+	changes := &ChangeSet{
+		Base: "v1.0.0", Head: "v1.1.0", Hunks: true,
+		Files: []githubclient.PullFile{
+			{Filename: "app/main.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -3 +3 @@\n-func main() {}\n+func main() { run() }"},
+			{Filename: "app/old.go", Status: "removed", Deletions: 1, Patch: "@@ -1 +0,0 @@\n-package main"},
+		},
+	}
+	var out bytes.Buffer
+	opts := TxtOptions{StripFirstDir: true, RootPath: "app", Changes: changes}
+	if err := BuildTxtFromTarGz(bytes.NewReader(src), &out, opts); err != nil {
+		t.Fatalf("build txt: %v", err)
+	}
+	result := out.String()
+	for _, want := range []string{
+		"=== CHANGES: v1.0.0...v1.1.0 (2 files, +1 -2) ===",
+		"=== FILE: main.go (first 3 lines) ===",
+		"=== DIFF: main.go ===\ndiff --git a/app/main.go b/app/main.go",
+		"=== DIFF: old.go (removed) ===",
+	} {
+		if !strings.Contains(result, want) {
+			t.Fatalf("no %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "unused.go") {
+		t.Fatalf("unchanged file exported:\n%s", result)
+	}
+}
@@ -3,6 +3,8 @@ package exporter
 import (
 	"archive/tar"     // чтение TAR
 	"archive/zip"     // запись ZIP
+	"bufio"           // построчное чтение текстовых файлов при сканировании секретов
+	"bytes"
 	"compress/gzip"   // распаковка .tar.gz
 	"errors"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/secrets"
 )
 
 // Опции сборки
@@ -20,6 +23,21 @@ type Options struct {
 	MaxExportMB      int      // общий лимит экспортируемых ДАННЫХ (по размерам файлов из tar)
 	MaxFilenameLen   int      // лимит длины имени файла внутри zip (напр. 255)
 	StripFirstDir    bool     // срезать первый сегмент (GitHub кладёт repo-<sha>/...)
+	RootPath         string   // экспортировать только это поддерево; пути в архиве — относительно него
+
+	SecretScan      bool                // прогонять текстовые файлы через secrets.Scanner
+	SecretStrategy  secrets.Strategy    // стратегия маскирования (дефолт: REDACTED)
+	PIIScan         bool                // маскировать персональные данные (email, IP, телефоны, карты, IBAN)
+	DropSecretFiles bool                // не класть в архив .env, id_rsa, *.pem, *.p12 и т.п.
+	SecretBaseline  secrets.Baseline    // принятые находки — не маскируем (см. secrets.ParseBaseline)
+	UseRepoIgnore   bool                // учитывать .gitignore, .gitattributes и .rep2promptignore из репозитория
+	RepoRules       *filters.RepoIgnore // правила, собранные заранее (FetchRepoRules); nil — только по ходу чтения архива
+	SkipGenerated   bool                // пропускать сгенерированное, vendored, минифицированное и lockfiles
+	Skipped         *SkippedFiles       // куда записывать пропущенные файлы с причинами (nil — не собираем)
+	Kinds           FileKinds           // сколько файлов какого вида экспортировано (nil — не собираем)
+	LFS             *LFSResolver        // скачивать объекты Git LFS вместо pointer'ов (nil — нет)
+	LFSFiles        *LFSFiles           // куда записывать pointer'ы Git LFS и скачанные объекты (nil — не собираем)
+	Manifest        *Manifest           // отчёт экспорта — последним файлом архива (nil — не пишем)
 }
 
 // Ошибки верхнего уровня
@@ -31,6 +49,12 @@ var (
 // BuildZipFromTarGz конвертит .tar.gz поток в ZIP, применяя фильтры.
 // Память O(1): читаем файл из tar по кускам и сразу пишем в zip.
 func BuildZipFromTarGz(src io.Reader, dst io.Writer, opts Options) error {
+	// маски компилируем один раз на весь архив
+	matcher, err := filters.NewMatcher(opts.IncludeGlobs, opts.ExcludeGlobs)
+	if err != nil {
+		return err
+	}
+
 	// распаковываем gzip
 	gz, err := gzip.NewReader(src)
 	if err != nil {
@@ -44,6 +68,28 @@ func BuildZipFromTarGz(src io.Reader, dst io.Writer, opts Options) error {
 
 	var total int64 // суммарный объём экспортируемых файлов (по hdr.Size)
 
+	// Сканер секретов — один на весь архив (корреляции внутри файла живут в нём).
+	var scanner *secrets.Scanner
+	if opts.SecretScan || opts.PIIScan {
+		scanner = secrets.NewScanner(secrets.Config{
+			Strategy:  opts.SecretStrategy,
+			PII:       opts.PIIScan,
+			NoSecrets: !opts.SecretScan,
+			Baseline:  opts.SecretBaseline,
+		})
+	}
+
+	// Правила исключения из самого репозитория (.gitignore/.gitattributes/.rep2promptignore).
+	var repoIgn *filters.RepoIgnore
+	if opts.UseRepoIgnore {
+		repoIgn = opts.RepoRules
+		if repoIgn == nil {
+			repoIgn = filters.NewRepoIgnore()
+		}
+	}
+
+	var dropped []string // выброшено DropSecretFiles — попадёт в secrets-report.json
+
 	const sampleN = 8192 // сколько байт читаем для эвристики бинарности
 
 	for {
@@ -81,46 +127,115 @@ func BuildZipFromTarGz(src io.Reader, dst io.Writer, opts Options) error {
 			continue // можно считать ErrBadName, но безопаснее просто пропустить
 		}
 
+		// Правила самого репозитория (.gitignore и т.п.) регистрируем до фильтров:
+		// они действуют, даже если сам файл правил в экспорт не попадает.
+		body := readRepoRules(repoIgn, rel, tr)
+
+		// Вне RootPath — пропускаем; дальше пути относительно поддерева
+		// (правила репозитория по-прежнему сверяем с полным путём).
+		full := rel
+		var ok bool
+		if rel, ok = reRoot(full, opts.RootPath); !ok {
+			if _, err := io.CopyN(io.Discard, body, hdr.Size); err != nil && err != io.EOF {
+				return err
+			}
+			continue
+		}
+
 		// Фильтры include/exclude.
-		if !filters.Match(rel, opts.IncludeGlobs, opts.ExcludeGlobs) {
+		if !matcher.Match(rel) {
 			// не проходит по маскам
 			// даже если tar огромный — просто «перелистываем» этот файл
-			if _, err := io.CopyN(io.Discard, tr, hdr.Size); err != nil && err != io.EOF {
+			if _, err := io.CopyN(io.Discard, body, hdr.Size); err != nil && err != io.EOF {
 				return err
 			}
 			continue
 		}
+		// ...и то, что репозиторий сам помечает как игнорируемое/сгенерированное.
+		if reason, ignored := repoIgn.Ignored(full); ignored {
+			opts.Skipped.Add(rel, reason)
+			if _, err := io.CopyN(io.Discard, body, hdr.Size); err != nil && err != io.EOF {
+				return err
+			}
+			continue
+		}
+
+		// Файлы-контейнеры секретов (.env, id_rsa, *.pem …) — выкидываем целиком.
+		if opts.DropSecretFiles && secrets.IsSecretFile(rel) {
+			dropped = append(dropped, rel)
+			opts.Skipped.Add(rel, SkipSecretFile)
+			if _, err := io.CopyN(io.Discard, body, hdr.Size); err != nil && err != io.EOF {
+				return err
+			}
+			continue
+		}
+
+		// Сгенерированное/стороннее по пути (vendor/, *.pb.go, lockfiles …).
+		if opts.SkipGenerated {
+			if reason, ok := filters.DetectGeneratedPath(rel); ok {
+				opts.Skipped.Add(rel, reason)
+				if _, err := io.CopyN(io.Discard, body, hdr.Size); err != nil && err != io.EOF {
+					return err
+				}
+				continue
+			}
+		}
+
+		// Pointer Git LFS: подменяем скачанным объектом; неразрешённый кладём как есть —
+		// pointer-файлом, как его видит git без LFS (причина — в отчёте LFSFiles).
+		var size int64
+		var ptr *LFSObject
+		if body, size, ptr, err = substLFS(opts.LFS, opts.LFSFiles, rel, body, hdr.Size); err != nil {
+			return err
+		}
 
 		// Общий лимит экспорта по сумме размеров файлов.
 		if opts.MaxExportMB > 0 {
 			limit := int64(opts.MaxExportMB) * 1024 * 1024
-			if total+hdr.Size > limit {
+			if total+size > limit {
 				return ErrExportTooLarge
 			}
 		}
 
-		// Сэмпл для проверки бинарности (только если задан MaxBinarySizeMB и файл больше).
+		// Сэмпл для проверки бинарности: нужен, если файл больше MaxBinarySizeMB,
+		// если включено сканирование секретов (бинарники не трогаем)
+		// или детектор сгенерированного (заголовок "Code generated …", минификация).
 		var sample []byte
-		if opts.MaxBinarySizeMB > 0 && hdr.Size > int64(opts.MaxBinarySizeMB)*1024*1024 {
+		isBig := opts.MaxBinarySizeMB > 0 && size > int64(opts.MaxBinarySizeMB)*1024*1024
+		binary := false
+		kind := filters.KindByName(rel) // без сэмпла — по имени
+		if ptr != nil {
+			kind = filters.KindLFSPointer
+		}
+		if isBig || scanner != nil || opts.SkipGenerated {
 			n := sampleN
-			if hdr.Size < int64(n) {
-				n = int(hdr.Size)
+			if size < int64(n) {
+				n = int(size)
 			}
 			sample = make([]byte, n)
-			if _, err := io.ReadFull(tr, sample); err != nil {
+			if _, err := io.ReadFull(body, sample); err != nil {
 				// если внезапно поток закончился — скипаем файл
 				continue
 			}
-			if filters.IsBinarySample(sample) {
+			kind = filters.SniffKind(sample)
+			binary = kind != filters.KindText && kind != filters.KindLFSPointer
+			if isBig && binary {
 				// файл «большой» и выглядит бинарным — пропускаем его полностью
-				remain := hdr.Size - int64(len(sample))
+				opts.Skipped.Add(rel, kind)
+				remain := size - int64(len(sample))
 				if remain > 0 {
-					if _, err := io.CopyN(io.Discard, tr, remain); err != nil && err != io.EOF {
+					if _, err := io.CopyN(io.Discard, body, remain); err != nil && err != io.EOF {
 						return err
 					}
 				}
 				continue
 			}
+			if opts.SkipGenerated && !binary {
+				if reason, ok := filters.DetectGeneratedContent(rel, sample); ok {
+					opts.Skipped.Add(rel, reason)
+					continue // остаток записи tar пропустит сам на Next()
+				}
+			}
 		}
 
 		// Готовим ZIP-запись. Используем CreateHeader без указания размера — ZIP сам посчитает.
@@ -136,6 +251,27 @@ func BuildZipFromTarGz(src io.Reader, dst io.Writer, opts Options) error {
 			return err
 		}
 
+		// Текстовый файл + сканер → построчно маскируем секреты.
+		if scanner != nil && !binary {
+			var r io.Reader = io.MultiReader(bytes.NewReader(sample), io.LimitReader(body, size-int64(len(sample))))
+			var byLine map[int][]secrets.Finding
+			if secrets.DetectFormat(rel) != secrets.FormatNone && size <= secrets.MaxBlobBytes {
+				// конфиг целиком в память — структурный скан по ключам
+				content, err := io.ReadAll(r)
+				if err != nil {
+					return err
+				}
+				byLine = blobFindings(scanner, rel, content)
+				r = bytes.NewReader(content)
+			}
+			if err := copyMasked(w, r, rel, scanner, byLine); err != nil {
+				return err
+			}
+			total += size
+			opts.Kinds.Add(kind)
+			continue
+		}
+
 		// Сначала пишем сэмпл (если был), затем остаток файла.
 		var wrote int64
 		if len(sample) > 0 {
@@ -144,19 +280,78 @@ func BuildZipFromTarGz(src io.Reader, dst io.Writer, opts Options) error {
 			}
 			wrote += int64(len(sample))
 		}
-		remain := hdr.Size - wrote
+		remain := size - wrote
 		if remain > 0 {
-			if _, err := io.CopyN(w, tr, remain); err != nil && err != io.EOF {
+			if _, err := io.CopyN(w, body, remain); err != nil && err != io.EOF {
 				return err
 			}
 		}
 
-		total += hdr.Size
+		total += size
+		opts.Kinds.Add(kind)
 	}
 
+	if err := writeSecretsReport(zw, scanner, dropped); err != nil {
+		return err
+	}
+	if err := writeManifest(zw, opts.Manifest); err != nil {
+		return err
+	}
 	// закрытие zw в defer
 	return nil
 }
 
+// copyMasked — копирует текст построчно, прогоняя каждую строку через сканер.
+// Переводы строк (LF/CRLF) и отсутствие финального '\n' сохраняются как в исходнике.
+// byLine — готовые находки ScanBlob (nil → сканируем каждую строку сами).
+func copyMasked(w io.Writer, r io.Reader, rel string, sc *secrets.Scanner, byLine map[int][]secrets.Finding) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	lineno := 0
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			lineno++
+			body, eol := splitEOL(line)
+			finds := byLine[lineno]
+			if byLine == nil {
+				finds = sc.ScanLine(rel, body, lineno)
+			}
+			out := sc.ApplyStrategy(body, finds)
+			// StrategyStrip сам добавляет '\n' — не удваиваем перевод строки
+			out = strings.TrimSuffix(out, "\n")
+			if _, werr := io.WriteString(w, out+eol); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// blobFindings — для структурированных конфигов (JSON/YAML/TOML/.properties/.env)
+// сканирует файл целиком и раскладывает находки по строкам; nil — формат
+// не структурированный, сканируем построчно.
+func blobFindings(sc *secrets.Scanner, rel string, b []byte) map[int][]secrets.Finding {
+	if secrets.DetectFormat(rel) == secrets.FormatNone || len(b) > secrets.MaxBlobBytes {
+		return nil
+	}
+	return secrets.GroupByLine(sc.ScanBlob(rel, b))
+}
+
+// splitEOL — отделяет окончание строки ("\n" или "\r\n") от содержимого.
+func splitEOL(line string) (string, string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
+
 // (необязательный) маленький помощник для вырезания расширения, если вдруг пригодится
 func extLower(p string) string { return strings.ToLower(path.Ext(p)) }
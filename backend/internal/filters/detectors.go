@@ -6,6 +6,7 @@ import (
 
 // IsBinarySample — грубая эвристика «бинарник или текст» по сэмплу.
 // Правила:
+//  • известная сигнатура формата (PNG, ZIP, ELF …) → бинарник;
 //  • наличие NUL-байта → бинарник;
 //  • слишком много «подозрительных» одиночных байт → бинарник;
 //  • валидные UTF-8 последовательности считаем текстом.
@@ -13,6 +14,9 @@ func IsBinarySample(sample []byte) bool {
 	if len(sample) == 0 {
 		return false
 	}
+	if magicKind(sample) != "" {
+		return true
+	}
 	// быстрый признак — NUL
 	for _, b := range sample {
 		if b == 0x00 {
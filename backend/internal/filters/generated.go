@@ -0,0 +1,129 @@
+package filters
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Причины пропуска сгенерированного/стороннего кода (DetectGenerated).
+const (
+	ReasonVendored  = "vendored"  // vendor/, node_modules/, third_party/ ...
+	ReasonGenerated = "generated" // *.pb.go, *_gen.go, dist/, "Code generated ... DO NOT EDIT"
+	ReasonMinified  = "minified"  // *.min.js, бандлы с очень длинными строками
+	ReasonLockfile  = "lockfile"  // package-lock.json, go.sum, yarn.lock ...
+)
+
+// каталоги со сторонним кодом (на любой глубине)
+var vendoredDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "third_party": true, "third-party": true,
+	"bower_components": true, "jspm_packages": true, "Pods": true, ".yarn": true,
+}
+
+// каталоги с результатами сборки
+var generatedDirs = map[string]bool{
+	"dist": true, "__generated__": true, ".next": true, ".nuxt": true,
+}
+
+var lockfiles = map[string]bool{
+	"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"bun.lockb": true, "go.sum": true, "Cargo.lock": true, "poetry.lock": true, "Pipfile.lock": true,
+	"Gemfile.lock": true, "composer.lock": true, "Podfile.lock": true, "mix.lock": true,
+	"packages.lock.json": true, "flake.lock": true, "pubspec.lock": true,
+}
+
+// суффиксы имён сгенерированных файлов
+var generatedSuffixes = []string{
+	".pb.go", "_gen.go", ".gen.go", "_generated.go", ".pb.gw.go",
+	"_pb2.py", "_pb2_grpc.py", ".pb.cc", ".pb.h", ".pb.ts", "_pb.js", "_pb.d.ts",
+	".g.dart", ".freezed.dart", ".designer.cs", ".g.cs", ".generated.ts",
+	".js.map", ".css.map",
+}
+
+var minifiedSuffixes = []string{".min.js", ".min.css", ".min.mjs", "-min.js", ".bundle.js"}
+
+// reGeneratedHeader — стандартные маркеры генераторов в начале файла:
+// Go ("Code generated ... DO NOT EDIT."), @generated (Meta/Buck), <auto-generated> (.NET).
+var reGeneratedHeader = regexp.MustCompile(`(?i)(code generated .* do not edit|@generated\b|<auto-generated|autogenerated by|this file was (automatically|auto-)generated|generated by .* do not (edit|modify))`)
+
+// DetectGeneratedPath — проверка только по пути (до чтения содержимого).
+func DetectGeneratedPath(rel string) (string, bool) {
+	base := path.Base(rel)
+	if lockfiles[base] {
+		return ReasonLockfile, true
+	}
+	segs := strings.Split(rel, "/")
+	for _, d := range segs[:len(segs)-1] {
+		if vendoredDirs[d] {
+			return ReasonVendored, true
+		}
+		if generatedDirs[d] {
+			return ReasonGenerated, true
+		}
+	}
+	lower := strings.ToLower(base)
+	for _, suf := range minifiedSuffixes {
+		if strings.HasSuffix(lower, suf) {
+			return ReasonMinified, true
+		}
+	}
+	for _, suf := range generatedSuffixes {
+		if strings.HasSuffix(lower, suf) {
+			return ReasonGenerated, true
+		}
+	}
+	return "", false
+}
+
+// minifiedAvgLine — средняя длина строки, начиная с которой текст считаем минифицированным.
+const minifiedAvgLine = 300
+
+// DetectGeneratedContent — проверка по началу файла (сэмплу):
+// заголовок генератора в первых строках или «бандл» с очень длинными строками.
+func DetectGeneratedContent(rel string, sample []byte) (string, bool) {
+	if len(sample) == 0 {
+		return "", false
+	}
+	// заголовок — только в первых ~10 строках / 1 КБ
+	head := sample
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	lines := bytes.SplitN(head, []byte("\n"), 11)
+	if len(lines) > 10 {
+		lines = lines[:10]
+	}
+	for _, l := range lines {
+		if reGeneratedHeader.Match(l) {
+			return ReasonGenerated, true
+		}
+	}
+	// минификация: мало переводов строк на большом сэмпле
+	if len(sample) >= 2048 && isMinifiable(rel) {
+		n := bytes.Count(sample, []byte("\n")) + 1
+		if len(sample)/n > minifiedAvgLine {
+			return ReasonMinified, true
+		}
+	}
+	return "", false
+}
+
+// isMinifiable — типы файлов, которые обычно минифицируют/бандлят. Данные и
+// разметку (.json, .html, .svg) сюда не берём: в одну строку их часто пишут
+// руками или сериализатором (фикстуры, иконки, конфиги), и это исходники, а не бандлы.
+func isMinifiable(rel string) bool {
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".js", ".mjs", ".cjs", ".css":
+		return true
+	}
+	return false
+}
+
+// DetectGenerated — путь + (если есть) сэмпл содержимого.
+func DetectGenerated(rel string, sample []byte) (string, bool) {
+	if reason, ok := DetectGeneratedPath(rel); ok {
+		return reason, true
+	}
+	return DetectGeneratedContent(rel, sample)
+}
@@ -0,0 +1,23 @@
+package filters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectGeneratedContent_MinifiedOnlyForCode(t *testing.T) {
+	oneLine := []byte(strings.Repeat(`{"id":1,"name":"item"},`, 200))
+	for rel, want := range map[string]bool{
+		"dist-free/app.js":      true,
+		"static/site.css":       true,
+		"testdata/fixture.json": false,
+		"assets/icons/logo.svg": false,
+		"templates/email.html":  false,
+		"src/main.go":           false,
+	} {
+		reason, ok := DetectGeneratedContent(rel, oneLine)
+		if ok != want || (ok && reason != ReasonMinified) {
+			t.Errorf("%s: got %q/%v, want minified=%v", rel, reason, ok, want)
+		}
+	}
+}
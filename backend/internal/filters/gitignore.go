@@ -0,0 +1,190 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fastKind — проверка без регулярки для самых частых видов масок.
+type fastKind int
+
+const (
+	fastNone  fastKind = iota // только регулярка
+	fastExact                 // "/go.mod", "docs/readme.md" — путь целиком
+	fastBase                  // "node_modules", "Makefile" — имя на любой глубине
+	fastExt                   // "*.log", "**/*_test.go" — суффикс имени
+)
+
+// globMeta — символы, после которых маска перестаёт быть литералом.
+const globMeta = `*?[{\`
+
+// rule — одна скомпилированная строка в синтаксисе .gitignore.
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool // "!маска"
+	dirOnly bool // "маска/" — только каталоги
+
+	prefix string   // литеральный префикс пути — отсев до регулярки
+	fast   fastKind // быстрый путь (если маска позволяет)
+	lit    string   // литерал для fast*
+}
+
+// matches — совпадает ли путь с маской (без учёта negate/dirOnly).
+func (r rule) matches(p string) bool {
+	if !strings.HasPrefix(p, r.prefix) {
+		return false
+	}
+	switch r.fast {
+	case fastExact:
+		return p == r.lit
+	case fastBase:
+		return len(p) > len(r.prefix) && p[strings.LastIndexByte(p, '/')+1:] == r.lit
+	case fastExt:
+		return len(p) >= len(r.prefix)+len(r.lit) && strings.HasSuffix(p, r.lit)
+	}
+	return r.re.MatchString(p)
+}
+
+// parseRule — строка .gitignore → rule. base — каталог, в котором лежит
+// .gitignore ("" — корень репозитория): маски с '/' якорятся к нему,
+// маски без '/' совпадают на любой глубине внутри него.
+// ok=false — пустая строка или комментарий; err — битая маска.
+func parseRule(line, base string) (rule, bool, error) {
+	line = strings.TrimSuffix(line, "\r")
+	// хвостовые пробелы режем, если они не экранированы "\ "
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return rule{}, false, nil
+	}
+	var r rule
+	if line[0] == '!' {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false, nil
+	}
+	// якорь: '/' в начале или в середине маски
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	body, err := globBody(line)
+	if err != nil {
+		return rule{}, false, err
+	}
+	basePrefix := ""
+	if base = strings.Trim(base, "/"); base != "" {
+		basePrefix = base + "/"
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	b.WriteString(regexp.QuoteMeta(basePrefix))
+	if !anchored {
+		b.WriteString(`(?:.*/)?`)
+	}
+	b.WriteString(body)
+	b.WriteString("$")
+	if r.re, err = regexp.Compile(b.String()); err != nil {
+		return rule{}, false, err
+	}
+
+	// быстрые пути
+	r.prefix = basePrefix
+	literal := !strings.ContainsAny(line, globMeta)
+	switch {
+	case literal && anchored:
+		r.fast, r.lit = fastExact, basePrefix+line
+		r.prefix = r.lit
+	case literal:
+		r.fast, r.lit = fastBase, line
+	case extSuffix(line, anchored) != "":
+		r.fast, r.lit = fastExt, extSuffix(line, anchored)
+	case anchored:
+		if i := strings.IndexAny(line, globMeta); i > 0 {
+			r.prefix = basePrefix + line[:i]
+		}
+	}
+	return r, true, nil
+}
+
+// extSuffix — для "*.ext" (без якоря) и "**/*.ext" возвращает ".ext", иначе "".
+func extSuffix(line string, anchored bool) string {
+	if anchored {
+		if !strings.HasPrefix(line, "**/") {
+			return ""
+		}
+		line = line[3:]
+	}
+	if len(line) < 2 || line[0] != '*' || strings.ContainsAny(line[1:], globMeta+"/") {
+		return ""
+	}
+	return line[1:]
+}
+
+// rules — упорядоченный список масок; побеждает последняя совпавшая.
+type rules []rule
+
+// compileRules — маски из API: битая маска → ошибка с её текстом.
+func compileRules(patterns []string, base string) (rules, error) {
+	out := make(rules, 0, len(patterns))
+	for _, p := range patterns {
+		r, ok, err := parseRule(p, base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		if ok {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// compileRulesLax — строки файлов правил из репозитория: битые просто пропускаем (как git).
+func compileRulesLax(lines []string, base string) rules {
+	out := make(rules, 0, len(lines))
+	for _, l := range lines {
+		if r, ok, err := parseRule(l, base); ok && err == nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// last — итог для одного пути: (выбран, было ли совпадение вообще).
+func (rs rules) last(p string, isDir bool) (bool, bool) {
+	for i := len(rs) - 1; i >= 0; i-- {
+		r := rs[i]
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.matches(p) {
+			return !r.negate, true
+		}
+	}
+	return false, false
+}
+
+// match — выбран ли файл p. Каталоги-предки проверяем сверху вниз:
+// если каталог выбран, файл внутри него отрицанием уже не вернуть (как в git).
+func (rs rules) match(p string) bool {
+	if len(rs) == 0 {
+		return false
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] != '/' {
+			continue
+		}
+		if sel, _ := rs.last(p[:i], true); sel {
+			return true
+		}
+	}
+	sel, _ := rs.last(p, false)
+	return sel
+}
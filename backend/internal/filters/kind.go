@@ -0,0 +1,172 @@
+package filters
+
+import (
+	"bytes"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Вид файла — для дерева, превью и манифеста экспорта.
+const (
+	KindText       = "text"
+	KindBinary     = "binary"
+	KindImage      = "image"
+	KindArchive    = "archive"
+	KindLFSPointer = "lfs-pointer"
+)
+
+// magic — сигнатура формата: байты sig по смещению off.
+type magic struct {
+	off  int
+	sig  string
+	kind string
+}
+
+// сигнатуры проверяются по порядку; более длинные/точные — раньше
+var magics = []magic{
+	// картинки
+	{0, "\x89PNG\r\n\x1a\n", KindImage},
+	{0, "\xff\xd8\xff", KindImage},
+	{0, "GIF87a", KindImage},
+	{0, "GIF89a", KindImage},
+	{0, "II*\x00", KindImage},          // TIFF little-endian
+	{0, "MM\x00*", KindImage},          // TIFF big-endian
+	{0, "\x00\x00\x01\x00", KindImage}, // ICO
+	{0, "8BPS", KindImage},             // PSD
+	{4, "ftypavif", KindImage},
+	{4, "ftypheic", KindImage},
+	// архивы
+	{0, "PK\x03\x04", KindArchive}, // zip, jar, docx, apk …
+	{0, "PK\x05\x06", KindArchive}, // пустой zip
+	{0, "\x1f\x8b", KindArchive},   // gzip
+	{0, "BZh", KindArchive},
+	{0, "\xfd7zXZ\x00", KindArchive},
+	{0, "7z\xbc\xaf\x27\x1c", KindArchive},
+	{0, "Rar!\x1a\x07", KindArchive},
+	{0, "\x28\xb5\x2f\xfd", KindArchive}, // zstd
+	{257, "ustar", KindArchive},          // tar
+	// исполняемые и прочие бинарные форматы
+	{0, "\x7fELF", KindBinary},
+	{0, "\xfe\xed\xfa\xce", KindBinary}, // Mach-O 32
+	{0, "\xfe\xed\xfa\xcf", KindBinary}, // Mach-O 64
+	{0, "\xce\xfa\xed\xfe", KindBinary},
+	{0, "\xcf\xfa\xed\xfe", KindBinary},
+	{0, "\xca\xfe\xba\xbe", KindBinary}, // Mach-O fat / Java class
+	{0, "\x00asm", KindBinary},          // WebAssembly
+	{0, "SQLite format 3\x00", KindBinary},
+	{0, "%PDF-", KindBinary},
+	{0, "wOFF", KindBinary},
+	{0, "wOF2", KindBinary},
+	{0, "OggS", KindBinary},
+	{0, "fLaC", KindBinary},
+	{0, "ID3\x03", KindBinary},       // mp3 (ID3v2.3)
+	{0, "ID3\x04", KindBinary},       // mp3 (ID3v2.4)
+	{0, "\x1aE\xdf\xa3", KindBinary}, // mkv/webm
+	{4, "ftyp", KindBinary},          // mp4/mov
+}
+
+// magicKind — вид по сигнатуре; "" — сигнатура не распознана.
+func magicKind(sample []byte) string {
+	for _, m := range magics {
+		if len(sample) >= m.off+len(m.sig) && string(sample[m.off:m.off+len(m.sig)]) == m.sig {
+			return m.kind
+		}
+	}
+	// RIFF-контейнеры: WEBP — картинка, WAVE/AVI — медиа
+	if len(sample) >= 12 && string(sample[:4]) == "RIFF" {
+		if string(sample[8:12]) == "WEBP" {
+			return KindImage
+		}
+		return KindBinary
+	}
+	// PE (Windows exe/dll): "MZ" + смещение заголовка "PE\0\0"
+	if len(sample) >= 64 && sample[0] == 'M' && sample[1] == 'Z' {
+		off := int(sample[60]) | int(sample[61])<<8 | int(sample[62])<<16 | int(sample[63])<<24
+		if off > 0 && len(sample) >= off+4 && string(sample[off:off+4]) == "PE\x00\x00" {
+			return KindBinary
+		}
+	}
+	return ""
+}
+
+// lfsSpec — первая строка pointer-файла Git LFS.
+const lfsSpec = "version https://git-lfs.github.com/spec/v1"
+
+// MaxLFSPointerSize — pointer-файлы крошечные (~130 байт); больше — точно не pointer.
+const MaxLFSPointerSize = 1024
+
+// ParseLFSPointer — разобрать pointer-файл Git LFS:
+//
+//	version https://git-lfs.github.com/spec/v1
+//	oid sha256:<64 hex>
+//	size <bytes>
+func ParseLFSPointer(sample []byte) (oid string, size int64, ok bool) {
+	if len(sample) > MaxLFSPointerSize || !bytes.HasPrefix(sample, []byte(lfsSpec)) {
+		return "", 0, false
+	}
+	size = -1
+	for _, line := range strings.Split(string(sample), "\n") {
+		k, v, _ := strings.Cut(strings.TrimSuffix(line, "\r"), " ")
+		switch k {
+		case "oid":
+			if h, found := strings.CutPrefix(v, "sha256:"); found && len(h) == 64 {
+				oid = h
+			}
+		case "size":
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+				size = n
+			}
+		}
+	}
+	if oid == "" || size < 0 {
+		return "", 0, false
+	}
+	return oid, size, true
+}
+
+// IsLFSPointer — сэмпл является pointer-файлом Git LFS.
+func IsLFSPointer(sample []byte) bool {
+	_, _, ok := ParseLFSPointer(sample)
+	return ok
+}
+
+// SniffKind — вид файла по началу содержимого: pointer LFS, сигнатура формата,
+// иначе эвристика IsBinarySample.
+func SniffKind(sample []byte) string {
+	if IsLFSPointer(sample) {
+		return KindLFSPointer
+	}
+	if k := magicKind(sample); k != "" {
+		return k
+	}
+	if IsBinarySample(sample) {
+		return KindBinary
+	}
+	return KindText
+}
+
+// расширения для KindByName (когда содержимого нет — дерево GitHub)
+var extKinds = map[string]string{
+	".png": KindImage, ".jpg": KindImage, ".jpeg": KindImage, ".gif": KindImage, ".bmp": KindImage,
+	".webp": KindImage, ".ico": KindImage, ".tif": KindImage, ".tiff": KindImage, ".psd": KindImage,
+	".avif": KindImage, ".heic": KindImage,
+	".zip": KindArchive, ".jar": KindArchive, ".war": KindArchive, ".apk": KindArchive, ".tar": KindArchive,
+	".gz": KindArchive, ".tgz": KindArchive, ".bz2": KindArchive, ".xz": KindArchive, ".7z": KindArchive,
+	".rar": KindArchive, ".zst": KindArchive, ".nupkg": KindArchive, ".whl": KindArchive,
+	".exe": KindBinary, ".dll": KindBinary, ".so": KindBinary, ".dylib": KindBinary, ".a": KindBinary,
+	".o": KindBinary, ".bin": KindBinary, ".class": KindBinary, ".wasm": KindBinary, ".pyc": KindBinary,
+	".db": KindBinary, ".sqlite": KindBinary, ".sqlite3": KindBinary, ".pdf": KindBinary, ".iso": KindBinary,
+	".mp3": KindBinary, ".wav": KindBinary, ".flac": KindBinary, ".ogg": KindBinary,
+	".mp4": KindBinary, ".mov": KindBinary, ".avi": KindBinary, ".mkv": KindBinary, ".webm": KindBinary,
+	".woff": KindBinary, ".woff2": KindBinary, ".ttf": KindBinary, ".otf": KindBinary, ".eot": KindBinary,
+}
+
+// KindByName — вид файла без содержимого (по имени из дерева). Pointer Git LFS
+// по имени не узнать — его определяет только SniffKind по содержимому.
+func KindByName(p string) string {
+	if k, ok := extKinds[strings.ToLower(path.Ext(p))]; ok {
+		return k
+	}
+	return KindText
+}
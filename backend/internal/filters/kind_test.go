@@ -0,0 +1,40 @@
+package filters
+
+import "testing"
+
+func TestSniffKind(t *testing.T) {
+	lfs := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+		"size 12345\n"
+	cases := []struct {
+		name   string
+		sample string
+		want   string
+	}{
+		{"png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", KindImage},
+		{"jpeg", "\xff\xd8\xff\xe0\x00\x10JFIF", KindImage},
+		{"webp", "RIFF\x10\x00\x00\x00WEBPVP8 ", KindImage},
+		{"jar", "PK\x03\x04\x14\x00\x08\x00", KindArchive},
+		{"gzip", "\x1f\x8b\x08\x00", KindArchive},
+		{"elf", "\x7fELF\x02\x01\x01", KindBinary},
+		{"wasm", "\x00asm\x01\x00\x00\x00", KindBinary},
+		{"sqlite", "SQLite format 3\x00\x10\x00", KindBinary},
+		{"pdf без NUL", "%PDF-1.7\n%âãÏÓ\n", KindBinary},
+		{"lfs", lfs, KindLFSPointer},
+		{"lfs без oid", "version https://git-lfs.github.com/spec/v1\nsize 1\n", KindText},
+		{"go", "package main\n\nfunc main() {}\n", KindText},
+	}
+	for _, c := range cases {
+		if got := SniffKind([]byte(c.sample)); got != c.want {
+			t.Errorf("%s: SniffKind = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	oid, size, ok := ParseLFSPointer([]byte(lfs))
+	if !ok || size != 12345 || oid[:8] != "4d7a2146" {
+		t.Errorf("ParseLFSPointer = %q, %d, %v", oid, size, ok)
+	}
+	if got := KindByName("assets/logo.png"); got != KindImage {
+		t.Errorf("KindByName(png) = %q", got)
+	}
+}
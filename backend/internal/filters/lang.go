@@ -0,0 +1,72 @@
+package filters
+
+import (
+	"path"
+	"strings"
+)
+
+// LangOther — язык не определён по имени.
+const LangOther = "Other"
+
+// языки по расширению — единственная таблица: статистика репо и подсветка в паках
+var langByExt = map[string]string{
+	".go": "Go",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".mts": "TypeScript", ".cts": "TypeScript",
+	".js": "JavaScript", ".jsx": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript",
+	".vue": "Vue", ".svelte": "Svelte",
+	".py": "Python", ".pyi": "Python", ".ipynb": "Jupyter Notebook",
+	".cs": "C#", ".fs": "F#", ".vb": "Visual Basic",
+	".java": "Java", ".kt": "Kotlin", ".kts": "Kotlin", ".scala": "Scala", ".groovy": "Groovy", ".gradle": "Groovy",
+	".c": "C", ".h": "C", ".cc": "C++", ".cpp": "C++", ".cxx": "C++", ".hpp": "C++", ".hh": "C++",
+	".m": "Objective-C", ".mm": "Objective-C", ".swift": "Swift",
+	".rs": "Rust", ".zig": "Zig", ".nim": "Nim",
+	".rb": "Ruby", ".php": "PHP", ".pl": "Perl", ".lua": "Lua", ".r": "R", ".jl": "Julia",
+	".dart": "Dart", ".ex": "Elixir", ".exs": "Elixir", ".erl": "Erlang", ".hs": "Haskell",
+	".clj": "Clojure", ".ml": "OCaml", ".elm": "Elm",
+	".sh": "Shell", ".bash": "Shell", ".zsh": "Shell", ".fish": "Shell", ".ps1": "PowerShell",
+	".sql": "SQL", ".proto": "Protocol Buffers", ".graphql": "GraphQL", ".gql": "GraphQL",
+	".html": "HTML", ".htm": "HTML", ".css": "CSS", ".scss": "SCSS", ".sass": "SCSS", ".less": "Less",
+	".json": "JSON", ".yml": "YAML", ".yaml": "YAML", ".toml": "TOML", ".xml": "XML", ".ini": "INI",
+	".md": "Markdown", ".mdx": "Markdown", ".rst": "reStructuredText", ".adoc": "AsciiDoc", ".txt": "Text",
+	".tf": "HCL", ".hcl": "HCL", ".nix": "Nix", ".cmake": "CMake",
+}
+
+// языки по имени файла без расширения
+var langByBase = map[string]string{
+	"Dockerfile": "Dockerfile", "Makefile": "Makefile", "GNUmakefile": "Makefile",
+	"CMakeLists.txt": "CMake", "Jenkinsfile": "Groovy", "Rakefile": "Ruby", "Gemfile": "Ruby",
+	"go.mod": "Go Module", "go.work": "Go Module",
+}
+
+// LanguageByPath — язык файла по имени/расширению; LangOther, если не знаем.
+func LanguageByPath(p string) string {
+	base := path.Base(p)
+	if l, ok := langByBase[base]; ok {
+		return l
+	}
+	if strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+		return "Dockerfile"
+	}
+	if l, ok := langByExt[strings.ToLower(path.Ext(base))]; ok {
+		return l
+	}
+	return LangOther
+}
+
+// тег подсветки markdown-блока по языку; нет в таблице — блок без тега
+var fenceByLang = map[string]string{
+	"Go": "go", "TypeScript": "tsx", "JavaScript": "jsx", "Python": "python", "C#": "csharp",
+	"Java": "java", "Kotlin": "kotlin", "Scala": "scala", "Groovy": "groovy",
+	"C": "c", "C++": "cpp", "Objective-C": "objectivec", "Swift": "swift", "Rust": "rust",
+	"Ruby": "ruby", "PHP": "php", "Perl": "perl", "Lua": "lua", "R": "r", "Dart": "dart",
+	"Elixir": "elixir", "Haskell": "haskell", "Clojure": "clojure", "OCaml": "ocaml",
+	"Shell": "bash", "PowerShell": "powershell", "SQL": "sql", "Protocol Buffers": "protobuf", "GraphQL": "graphql",
+	"HTML": "html", "CSS": "css", "SCSS": "scss", "Less": "less", "Vue": "vue", "Svelte": "svelte",
+	"JSON": "json", "YAML": "yaml", "TOML": "toml", "XML": "xml", "INI": "ini",
+	"HCL": "hcl", "Nix": "nix", "CMake": "cmake", "Dockerfile": "dockerfile", "Makefile": "makefile",
+}
+
+// FenceByPath — тег подсветки для блока кода с файлом p; "" — без подсветки.
+func FenceByPath(p string) string {
+	return fenceByLang[LanguageByPath(p)]
+}
@@ -0,0 +1,25 @@
+package filters
+
+import "testing"
+
+func TestLanguageAndFenceByPath(t *testing.T) {
+	cases := []struct{ path, lang, fence string }{
+		{"cmd/api/main.go", "Go", "go"},
+		{"web/App.TSX", "TypeScript", "tsx"},
+		{"lib/index.mjs", "JavaScript", "jsx"},
+		{"svc/Program.cs", "C#", "csharp"},
+		{"deploy/values.yml", "YAML", "yaml"},
+		{"build/Dockerfile.dev", "Dockerfile", "dockerfile"},
+		{"README.md", "Markdown", ""},
+		{"notes.txt", "Text", ""},
+		{"assets/logo.bin", LangOther, ""},
+	}
+	for _, c := range cases {
+		if got := LanguageByPath(c.path); got != c.lang {
+			t.Errorf("LanguageByPath(%q) = %q, want %q", c.path, got, c.lang)
+		}
+		if got := FenceByPath(c.path); got != c.fence {
+			t.Errorf("FenceByPath(%q) = %q, want %q", c.path, got, c.fence)
+		}
+	}
+}
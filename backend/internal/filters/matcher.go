@@ -8,39 +8,68 @@ import (
 )
 
 // Match решает, нужно ли пропускать путь по include/exclude маскам.
-// Правила:
+// Маски компилируются на каждый вызов — для массовой проверки (экспорт)
+// собирай Matcher один раз через NewMatcher. Битые маски → false.
+// Маски — в синтаксисе .gitignore (см. parseRule):
 //  • Пути — относительные POSIX ("src/app/a.ts"), без ведущего "/".
+//  • Маска без '/' ("*.log") совпадает на любой глубине, с '/' — от корня.
+//  • "dir/" — только каталог (и всё внутри него).
+//  • "!маска" — отрицание; внутри списка побеждает ПОСЛЕДНЯЯ совпавшая маска.
+//  • Файл внутри выбранного каталога отрицанием не вернуть (как в git).
 //  • Если includes пуст → включаем всё, КРОМЕ совпавших с excludes.
-//  • Отрицательные маски вида "!**/*.test.*" НЕ парсим — передавай их в excludes.
 func Match(p string, includes, excludes []string) bool {
+	m, err := NewMatcher(includes, excludes)
+	if err != nil {
+		return false
+	}
+	return m.Match(p)
+}
+
+// Matcher — include/exclude маски, скомпилированные один раз (на экспорт).
+// Семантика та же, что у Match. Безопасен для конкурентного использования.
+type Matcher struct {
+	includes, excludes rules
+}
+
+// NewMatcher компилирует маски; битая маска (незакрытый "[", "\" в конце) → ошибка.
+func NewMatcher(includes, excludes []string) (*Matcher, error) {
+	inc, err := compileRules(includes, "")
+	if err != nil {
+		return nil, err
+	}
+	exc, err := compileRules(excludes, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{includes: inc, excludes: exc}, nil
+}
+
+// MustMatcher — как NewMatcher, но паникует (для статических масок в коде).
+func MustMatcher(includes, excludes []string) *Matcher {
+	m, err := NewMatcher(includes, excludes)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Match — пропускать ли путь p.
+func (m *Matcher) Match(p string) bool {
 	// Нормализуем путь (безопасность и единый формат).
 	np, err := NormalizeRel(p)
 	if err != nil {
 		return false // сломанный путь — не пропускаем
 	}
-
-	// Если совпал с любым exclude → выкидываем.
-	if matchesAny(np, excludes) {
+	// Если excludes выбрали путь → выкидываем.
+	if m.excludes.match(np) {
 		return false
 	}
-
 	// Если includes пуст → пропускаем всё (мы уже исключили exclude выше).
-	if len(includes) == 0 {
+	if len(m.includes) == 0 {
 		return true
 	}
-
-	// Иначе — пропускаем только то, что совпало с include.
-	return matchesAny(np, includes)
-}
-
-func matchesAny(p string, patterns []string) bool {
-	for _, pat := range patterns {
-		re := globToRegexp(pat)
-		if re.MatchString(p) {
-			return true
-		}
-	}
-	return false
+	// Иначе — пропускаем только то, что выбрали includes.
+	return m.includes.match(np)
 }
 
 // NormalizeRel приводит путь к безопасной относительной POSIX-форме.
@@ -72,36 +101,52 @@ func NormalizeRel(p string) (string, error) {
 	return clean, nil
 }
 
-// globToRegexp конвертит простые glob-маски в регулярку.
+// globBody конвертит glob-маску в тело регулярки (без ^ и $ и без
+// gitignore-якорения — его добавляет parseRule).
 // Поддержка:
-//  • "**" — любой префикс с '/'
+//  • "**/" — ноль и более каталогов; "/**" в конце — всё внутри; прочие "**" — как "*"
 //  • "*"  — любые символы, КРОМЕ '/'
 //  • "?"  — ровно один символ, КРОМЕ '/'
+//  • "[a-z]", "[!abc]" / "[^abc]" — классы символов (никогда не совпадают с '/')
+//  • "{a,b}" — альтернативы (вложенные тоже)
+//  • "\x" — литерал x
 // Примеры:
-//  "**/*.ts"  -> ^(?:.*\/)?[^\/]*\.ts$ (упрощённо)
-//  "src/*/a"  -> ^src\/[^\/]*\/a$
-//  "a/**/b"   -> ^a\/.*\/b$
-func globToRegexp(glob string) *regexp.Regexp {
+//  "**/*.ts"     -> (?:.*/)?[^/]*\.ts
+//  "src/*/a"     -> src/[^/]*/a
+//  "a/**/b"      -> a/(?:.*/)?b
+//  "*.{js,ts}"   -> [^/]*\.(?:js|ts)
+// Ошибка — незакрытый класс "[..." или "\" в конце маски.
+func globBody(glob string) (string, error) {
 	var b strings.Builder
-	b.WriteString("^")
-
-	esc := func(r rune) {
-		// экранируем спецсимволы regex
-		if strings.ContainsRune(`.+()|[]{}^$`, r) {
-			b.WriteRune('\\')
-		}
-		b.WriteRune(r)
-	}
-
 	runes := []rune(glob)
 	for i := 0; i < len(runes); i++ {
 		r := runes[i]
 		switch r {
+		case '\\':
+			// экранирование: следующий символ — литерал
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			} else {
+				return "", errors.New("trailing backslash")
+			}
 		case '*':
-			// "**" → .*
 			if i+1 < len(runes) && runes[i+1] == '*' {
-				b.WriteString(".*")
-				i++
+				atSeg := i == 0 || runes[i-1] == '/'
+				switch {
+				case atSeg && i+2 < len(runes) && runes[i+2] == '/':
+					// "**/" → ноль и более каталогов
+					b.WriteString(`(?:.*/)?`)
+					i += 2
+				case atSeg && i+2 == len(runes):
+					// "/**" в конце (или просто "**") → всё внутри
+					b.WriteString(`.*`)
+					i++
+				default:
+					// git: прочие "**" ведут себя как обычная "*"
+					b.WriteString(`[^/]*`)
+					i++
+				}
 			} else {
 				// "*" → [^/]* (в пределах сегмента)
 				b.WriteString(`[^/]*`)
@@ -109,17 +154,102 @@ func globToRegexp(glob string) *regexp.Regexp {
 		case '?':
 			// любой один символ, кроме '/'
 			b.WriteString(`[^/]`)
-		case '.':
-			b.WriteString(`\.`) // точка — экранируем
-		case '/':
-			b.WriteString(`/`) // слэш — как есть
+		case '[':
+			if cls, n := globClass(runes[i:]); n > 0 {
+				b.WriteString(cls)
+				i += n - 1
+			} else {
+				return "", errors.New("unterminated character class")
+			}
+		case '{':
+			if alts, n := globBraces(runes[i:]); n > 0 {
+				b.WriteString("(?:")
+				for k, a := range alts {
+					if k > 0 {
+						b.WriteByte('|')
+					}
+					body, err := globBody(a)
+					if err != nil {
+						return "", err
+					}
+					b.WriteString(body)
+				}
+				b.WriteString(")")
+				i += n - 1
+			} else {
+				b.WriteString(`\{`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String(), nil
+}
+
+// globClass — "[...]" в начале rs → класс регулярки и длина в рунах (0 — не класс).
+func globClass(rs []rune) (string, int) {
+	j := 1
+	neg := false
+	if j < len(rs) && (rs[j] == '!' || rs[j] == '^') {
+		neg = true
+		j++
+	}
+	var b strings.Builder
+	first := true
+	for ; j < len(rs); j++ {
+		c := rs[j]
+		if c == ']' && !first {
+			if b.Len() == 0 {
+				return "", 0
+			}
+			if neg {
+				return "[^/" + b.String() + "]", j + 1
+			}
+			return "[" + b.String() + "]", j + 1
+		}
+		first = false
+		switch c {
+		case '\\':
+			if j+1 < len(rs) {
+				j++
+				c = rs[j]
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		case '[', ']', '^':
+			b.WriteString(`\` + string(c))
 		default:
-			esc(r)
+			b.WriteRune(c)
 		}
 	}
+	return "", 0
+}
 
-	b.WriteString("$")
-	// Регулярка компилируется один раз на паттерн (достаточно быстро).
-	re := regexp.MustCompile(b.String())
-	return re
+// globBraces — "{a,b,...}" в начале rs → альтернативы и длина в рунах
+// (0 — нет закрывающей скобки или нет запятой, тогда '{' — литерал).
+func globBraces(rs []rune) ([]string, int) {
+	depth := 0
+	start := 1
+	var alts []string
+	for j := 0; j < len(rs); j++ {
+		switch rs[j] {
+		case '\\':
+			j++
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alts = append(alts, string(rs[start:j]))
+				start = j + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				if len(alts) == 0 {
+					return nil, 0
+				}
+				return append(alts, string(rs[start:j])), j + 1
+			}
+		}
+	}
+	return nil, 0
 }
@@ -0,0 +1,95 @@
+package filters
+
+import "testing"
+
+func TestMatch_GitignoreSemantics(t *testing.T) {
+	cases := []struct {
+		path     string
+		includes []string
+		excludes []string
+		want     bool
+	}{
+		// без '/' — на любой глубине, с '/' — от корня
+		{"a/b/debug.log", nil, []string{"*.log"}, false},
+		{"docs/x.md", nil, []string{"/x.md"}, true},
+		{"x.md", nil, []string{"/x.md"}, false},
+		{"src/gen/a.go", nil, []string{"src/gen"}, false},
+		{"lib/src/gen/a.go", nil, []string{"src/gen"}, true},
+		// "dir/" — только каталоги
+		{"build/out.js", nil, []string{"build/"}, false},
+		{"tools/build", nil, []string{"build/"}, true},
+		// отрицание и last-match-wins
+		{"logs/keep.log", nil, []string{"*.log", "!keep.log"}, true},
+		{"logs/keep.log", nil, []string{"!keep.log", "*.log"}, false},
+		// из исключённого каталога файл не вернуть
+		{"build/keep.txt", nil, []string{"build/", "!build/keep.txt"}, false},
+		{"build/keep.txt", nil, []string{"build/**", "!build/keep.txt"}, true},
+		// "**"
+		{"a/x/y/b", []string{"a/**/b"}, nil, true},
+		{"a/b", []string{"a/**/b"}, nil, true},
+		{"deep/dir/file.ts", []string{"**/*.ts"}, nil, true},
+		// классы символов и фигурные скобки
+		{"v1/file.txt", []string{"v[0-9]/*.txt"}, nil, true},
+		{"vx/file.txt", []string{"v[!0-9]/*.txt"}, nil, true},
+		{"v1/file.txt", []string{"v[!0-9]/*.txt"}, nil, false},
+		{"web/app.tsx", []string{"*.{ts,tsx}"}, nil, true},
+		{"web/app.js", []string{"*.{ts,tsx}"}, nil, false},
+		{"web/{literal}.txt", []string{`\{literal}.txt`}, nil, true},
+		// include + exclude вместе
+		{"src/a_test.go", []string{"src/**"}, []string{"*_test.go"}, false},
+		{"src/a.go", []string{"src/**"}, []string{"src/vendor/"}, true},
+		{"src/vendor/x.go", []string{"src/**"}, []string{"src/vendor/"}, false},
+		{"src/x.go", []string{"src/*.go", "!src/x.go"}, nil, false},
+	}
+	for _, c := range cases {
+		if got := Match(c.path, c.includes, c.excludes); got != c.want {
+			t.Errorf("Match(%q, %q, %q) = %v, want %v", c.path, c.includes, c.excludes, got, c.want)
+		}
+	}
+}
+
+func TestNewMatcher_InvalidPattern(t *testing.T) {
+	for _, bad := range []string{"src/[a-z", `trailing\`, "{a,[b}"} {
+		if _, err := NewMatcher([]string{bad}, nil); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if Match("src/a.go", []string{"src/[a-z"}, nil) {
+		t.Error("Match must reject paths for invalid patterns")
+	}
+}
+
+// Типичный экспорт: ~20 масок на большой монорепе.
+var benchPatterns = struct{ inc, exc []string }{
+	inc: []string{"**/*.go", "**/*.ts", "**/*.tsx", "*.md", "/Makefile", "docs/**", "src/**/*.{js,jsx}", "cmd/*/main.go"},
+	exc: []string{"node_modules/", "vendor/", "dist/", "*.min.js", "*.pb.go", "*_gen.go", "**/testdata/**",
+		"build/", ".git/", "*.lock", "coverage/", "!keep.lock"},
+}
+
+func benchPaths() []string {
+	dirs := []string{"src/app/components", "internal/service/handlers", "node_modules/react/lib", "docs/guide", "cmd/api", "vendor/github.com/x/y"}
+	files := []string{"index.ts", "main.go", "util.min.js", "api.pb.go", "README.md", "view.tsx", "yarn.lock", "widget.jsx"}
+	var out []string
+	for i := 0; i < 1000; i++ {
+		d := dirs[i%len(dirs)]
+		out = append(out, d+"/"+files[i%len(files)])
+	}
+	return out
+}
+
+func BenchmarkMatch_PerCall(b *testing.B) {
+	paths := benchPaths()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Match(paths[i%len(paths)], benchPatterns.inc, benchPatterns.exc)
+	}
+}
+
+func BenchmarkMatcher_Compiled(b *testing.B) {
+	paths := benchPaths()
+	m := MustMatcher(benchPatterns.inc, benchPatterns.exc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Match(paths[i%len(paths)])
+	}
+}
@@ -0,0 +1,141 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Preset — именованный набор масок include/exclude (синтаксис .gitignore).
+type Preset struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	IncludeGlobs []string `json:"includeGlobs"`
+	ExcludeGlobs []string `json:"excludeGlobs"`
+	Builtin      bool     `json:"builtin"`
+}
+
+// встроенные пресеты; имена зарезервированы — пользовательские с такими же не сохраняем
+var builtinPresets = map[string]Preset{
+	"backend-go": {
+		Description:  "Go-код, модули, схемы и сборка",
+		IncludeGlobs: []string{"*.go", "go.mod", "go.work", "*.sql", "*.proto", "Makefile", "Dockerfile*"},
+		ExcludeGlobs: []string{"vendor/"},
+	},
+	"frontend-ts": {
+		Description: "TypeScript/JavaScript фронтенд, стили и конфиги сборки",
+		IncludeGlobs: []string{
+			"*.ts", "*.tsx", "*.js", "*.jsx", "*.mjs", "*.cjs", "*.vue", "*.svelte",
+			"*.css", "*.scss", "*.sass", "*.less", "*.html",
+			"package.json", "tsconfig*.json", "vite.config.*", "next.config.*", "webpack.config.*",
+		},
+		ExcludeGlobs: []string{"node_modules/", "dist/", "build/", "coverage/", ".next/"},
+	},
+	"docs-only": {
+		Description:  "Только документация",
+		IncludeGlobs: []string{"*.md", "*.mdx", "*.rst", "*.adoc", "docs/", "LICENSE*", "CHANGELOG*"},
+	},
+	"no-tests": {
+		Description: "Без тестов, фикстур и снапшотов",
+		ExcludeGlobs: []string{
+			"*_test.go", "testdata/",
+			"*.test.ts", "*.test.tsx", "*.test.js", "*.test.jsx", "*.spec.ts", "*.spec.tsx", "*.spec.js", "*.spec.jsx",
+			"__tests__/", "__snapshots__/", "__mocks__/",
+			"test_*.py", "*_test.py", "conftest.py",
+			"test/", "tests/", "spec/",
+		},
+	},
+	"infra": {
+		Description: "Docker, CI, Kubernetes/Helm, Terraform",
+		IncludeGlobs: []string{
+			"Dockerfile*", "*.dockerfile", "docker-compose*.yml", "docker-compose*.yaml", "compose.yml", "compose.yaml",
+			"*.tf", "*.tfvars", "*.hcl", "Makefile", "Taskfile.yml", "Jenkinsfile",
+			".github/workflows/", ".gitlab-ci.yml", "k8s/", "kube/", "helm/", "charts/", "deploy/", "infra/",
+		},
+	},
+}
+
+// BuiltinPresets — встроенные пресеты, отсортированные по имени.
+func BuiltinPresets() []Preset {
+	out := make([]Preset, 0, len(builtinPresets))
+	for name := range builtinPresets {
+		p, _ := BuiltinPreset(name)
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// BuiltinPreset — встроенный пресет по имени.
+func BuiltinPreset(name string) (Preset, bool) {
+	p, ok := builtinPresets[name]
+	if !ok {
+		return Preset{}, false
+	}
+	p.Name = name
+	p.Builtin = true
+	return p, true
+}
+
+var rePresetName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// ValidatePreset — имя (a-z0-9._-, до 64 символов), не встроенное, маски компилируются.
+func ValidatePreset(p Preset) error {
+	if !rePresetName.MatchString(p.Name) {
+		return fmt.Errorf("invalid preset name %q", p.Name)
+	}
+	if _, ok := builtinPresets[p.Name]; ok {
+		return fmt.Errorf("preset %q is built-in", p.Name)
+	}
+	if len(p.IncludeGlobs) == 0 && len(p.ExcludeGlobs) == 0 {
+		return fmt.Errorf("preset %q has no globs", p.Name)
+	}
+	_, err := NewMatcher(p.IncludeGlobs, p.ExcludeGlobs)
+	return err
+}
+
+// ErrUnknownPreset — пресета нет ни среди встроенных, ни у пользователя.
+var ErrUnknownPreset = errors.New("unknown filter preset")
+
+// ResolvePresets — раскрыть пресеты (по порядку) и дописать маски запроса.
+// lookup ищет пользовательские пресеты (nil — только встроенные); его ошибка
+// (например, недоступна БД) возвращается как есть, отсутствие — ErrUnknownPreset.
+// Повторы убираем, оставляя последнее вхождение: для last-match-wins это не меняет смысла.
+func ResolvePresets(names []string, lookup func(name string) (Preset, bool, error), include, exclude []string) ([]string, []string, error) {
+	if len(names) == 0 {
+		return include, exclude, nil
+	}
+	var inc, exc []string
+	for _, name := range names {
+		p, ok := BuiltinPreset(name)
+		if !ok && lookup != nil {
+			var err error
+			if p, ok, err = lookup(name); err != nil {
+				return nil, nil, fmt.Errorf("filter preset %q: %w", name, err)
+			}
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("%w %q", ErrUnknownPreset, name)
+		}
+		inc = append(inc, p.IncludeGlobs...)
+		exc = append(exc, p.ExcludeGlobs...)
+	}
+	inc = dedupKeepLast(append(inc, include...))
+	exc = dedupKeepLast(append(exc, exclude...))
+	return inc, exc, nil
+}
+
+func dedupKeepLast(in []string) []string {
+	last := make(map[string]int, len(in))
+	for i, s := range in {
+		last[s] = i
+	}
+	out := make([]string, 0, len(last))
+	for i, s := range in {
+		if last[s] == i {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package filters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePresets(t *testing.T) {
+	user := map[string]Preset{
+		"api-only": {Name: "api-only", IncludeGlobs: []string{"api/", "*.go"}},
+	}
+	lookup := func(name string) (Preset, bool, error) {
+		p, ok := user[name]
+		return p, ok, nil
+	}
+
+	inc, exc, err := ResolvePresets([]string{"backend-go", "no-tests", "api-only"}, lookup, []string{"README.md"}, []string{"*.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "*.go" из backend-go уходит: последнее вхождение — из api-only
+	wantInc := []string{"go.mod", "go.work", "*.sql", "*.proto", "Makefile", "Dockerfile*", "api/", "*.go", "README.md"}
+	if !reflect.DeepEqual(inc, wantInc) {
+		t.Errorf("include = %v, want %v", inc, wantInc)
+	}
+	if exc[0] != "vendor/" || exc[len(exc)-1] != "*.log" {
+		t.Errorf("exclude = %v", exc)
+	}
+	m, err := NewMatcher(inc, exc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, want := range map[string]bool{
+		"cmd/api/main.go":      true,
+		"cmd/api/main_test.go": false,
+		"vendor/x/y.go":        false,
+		"web/app.ts":           false,
+	} {
+		if got := m.Match(p); got != want {
+			t.Errorf("Match(%q) = %v, want %v", p, got, want)
+		}
+	}
+
+	if _, _, err := ResolvePresets([]string{"nope"}, lookup, nil, nil); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("unknown preset: err = %v, want ErrUnknownPreset", err)
+	}
+	dbErr := errors.New("db down")
+	failing := func(string) (Preset, bool, error) { return Preset{}, false, dbErr }
+	if _, _, err := ResolvePresets([]string{"api-only"}, failing, nil, nil); !errors.Is(err, dbErr) || errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("lookup failure: err = %v, want the lookup error", err)
+	}
+	if err := ValidatePreset(Preset{Name: "infra", IncludeGlobs: []string{"*.tf"}}); err == nil {
+		t.Error("built-in name: want error")
+	}
+}
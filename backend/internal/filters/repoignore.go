@@ -0,0 +1,148 @@
+package filters
+
+import (
+	"path"
+	"strings"
+)
+
+// Файлы правил, которые репозиторий задаёт сам.
+const (
+	GitignoreFile     = ".gitignore"
+	GitattributesFile = ".gitattributes"
+	OwnIgnoreFile     = ".rep2promptignore" // как .gitignore, но только для экспортов
+)
+
+// Причины исключения (RepoIgnore.Ignored).
+const (
+	ReasonGitignore         = "gitignore"
+	ReasonOwnIgnore         = "rep2promptignore"
+	ReasonExportIgnore      = "export-ignore"
+	ReasonLinguistGenerated = "linguist-generated"
+	ReasonLinguistVendored  = "linguist-vendored"
+)
+
+// attrsOfInterest — атрибуты .gitattributes, которые исключают файл из экспорта.
+var attrsOfInterest = []string{ReasonExportIgnore, ReasonLinguistGenerated, ReasonLinguistVendored}
+
+// attrRule — строка .gitattributes: маска + значения интересующих нас атрибутов.
+type attrRule struct {
+	rule
+	set map[string]bool // true — выставлен, false — явно снят (-attr, attr=false, !attr)
+}
+
+// RepoIgnore — правила исключения из самого репозитория: .gitignore (в любом
+// каталоге), .rep2promptignore и .gitattributes (export-ignore,
+// linguist-generated, linguist-vendored).
+// Наполняется по мере чтения tar, и тогда правило действует только на файлы,
+// идущие в архиве ПОСЛЕ файла правил. git archive кладёт ".gitignore" раньше
+// большинства соседей, но не всех: ".github/", ".editorconfig" и имена на
+// "-", "#" и т.п. идут до него. Поэтому экспорт заранее собирает все файлы
+// правил по дереву (exporter.FetchRepoRules), а чтение tar лишь добирает
+// то, чего в дереве не было. Повторный Add того же файла ничего не меняет.
+type RepoIgnore struct {
+	git   rules // все .gitignore; глубже = позже = приоритетнее
+	own   rules // все .rep2promptignore
+	attrs []attrRule
+	added map[string]bool // уже разобранные файлы правил
+}
+
+func NewRepoIgnore() *RepoIgnore { return &RepoIgnore{added: map[string]bool{}} }
+
+// IsRuleFile — rel — один из файлов правил (в любом каталоге)?
+func IsRuleFile(rel string) bool {
+	switch path.Base(rel) {
+	case GitignoreFile, GitattributesFile, OwnIgnoreFile:
+		return true
+	}
+	return false
+}
+
+// Add — разобрать файл правил rel (путь от корня репозитория) с содержимым content.
+func (ri *RepoIgnore) Add(rel string, content []byte) {
+	if ri.added[rel] {
+		return
+	}
+	if ri.added == nil {
+		ri.added = map[string]bool{}
+	}
+	ri.added[rel] = true
+	base := path.Dir(rel)
+	if base == "." {
+		base = ""
+	}
+	lines := strings.Split(string(content), "\n")
+	switch path.Base(rel) {
+	case GitignoreFile:
+		ri.git = append(ri.git, compileRulesLax(lines, base)...)
+	case OwnIgnoreFile:
+		ri.own = append(ri.own, compileRulesLax(lines, base)...)
+	case GitattributesFile:
+		for _, line := range lines {
+			if ar, ok := parseAttrLine(line, base); ok {
+				ri.attrs = append(ri.attrs, ar)
+			}
+		}
+	}
+}
+
+// Ignored — исключён ли rel правилами репозитория и по какой причине.
+func (ri *RepoIgnore) Ignored(rel string) (string, bool) {
+	if ri == nil {
+		return "", false
+	}
+	if ri.own.match(rel) {
+		return ReasonOwnIgnore, true
+	}
+	if ri.git.match(rel) {
+		return ReasonGitignore, true
+	}
+	// атрибуты: для каждого побеждает последняя строка, где он упомянут
+	for _, attr := range attrsOfInterest {
+		for i := len(ri.attrs) - 1; i >= 0; i-- {
+			ar := ri.attrs[i]
+			v, ok := ar.set[attr]
+			if !ok || !ar.matches(rel) {
+				continue
+			}
+			if v {
+				return attr, true
+			}
+			break
+		}
+	}
+	return "", false
+}
+
+// parseAttrLine — "pattern attr1 -attr2 attr3=false ...".
+// Отрицательных масок в .gitattributes нет, маски каталогов ("dir/") не действуют.
+func parseAttrLine(line, base string) (attrRule, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "!") {
+		return attrRule{}, false
+	}
+	set := map[string]bool{}
+	for _, f := range fields[1:] {
+		name, val := f, true
+		switch {
+		case strings.HasPrefix(f, "-") || strings.HasPrefix(f, "!"):
+			name, val = f[1:], false
+		case strings.Contains(f, "="):
+			kv := strings.SplitN(f, "=", 2)
+			name = kv[0]
+			val = kv[1] != "false" && kv[1] != "0"
+		}
+		for _, a := range attrsOfInterest {
+			if name == a {
+				set[name] = val
+			}
+		}
+	}
+	if len(set) == 0 {
+		return attrRule{}, false
+	}
+	r, ok, err := parseRule(strings.Trim(fields[0], `"`), base)
+	if !ok || err != nil || r.dirOnly {
+		return attrRule{}, false
+	}
+	return attrRule{rule: r, set: set}, true
+}
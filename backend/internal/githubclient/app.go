@@ -0,0 +1,289 @@
+package githubclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNoInstallation — приложение не установлено на репозиторий (идём с PAT).
+var ErrNoInstallation = errors.New("github app is not installed for repository")
+
+// AppAuth — аутентификация GitHub App: JWT приложения (RS256, живёт 10 минут)
+// → installation для owner/repo → installation access token (живёт час).
+// Всё кэшируется; токен обновляется за refreshBefore до истечения.
+// Параллельные промахи по одному ключу идут к GitHub одним запросом (flight).
+type AppAuth struct {
+	AppID   int64
+	Key     *rsa.PrivateKey
+	BaseURL string
+	Doer    HTTPDoer // «голый» транспорт: без пула токенов и кэша
+
+	now    func() time.Time
+	flight singleflight.Group // "install:owner/repo", "token:id"
+
+	mu       sync.Mutex
+	jwt      string
+	jwtUntil time.Time
+	installs map[string]installEntry // owner/repo → installation
+	tokens   map[int64]installToken  // installation → токен
+}
+
+type installEntry struct {
+	id    int64 // 0 — не установлено
+	until time.Time
+}
+
+type installToken struct {
+	token   string
+	expires time.Time
+}
+
+const (
+	appJWTTTL          = 9 * time.Minute // GitHub допускает не больше 10
+	installCacheTTL    = 10 * time.Minute
+	noInstallCacheTTL  = time.Minute // «не установлено» — коротко: приложение могли только что поставить
+	tokenRefreshBefore = 5 * time.Minute
+)
+
+// NewAppAuth — keyPEM: приватный ключ приложения (PKCS#1 или PKCS#8).
+func NewAppAuth(appID int64, keyPEM []byte, baseURL string, doer HTTPDoer) (*AppAuth, error) {
+	key, err := ParseAppPrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &AppAuth{
+		AppID:    appID,
+		Key:      key,
+		BaseURL:  baseURL,
+		Doer:     doer,
+		now:      time.Now,
+		installs: map[string]installEntry{},
+		tokens:   map[int64]installToken{},
+	}, nil
+}
+
+// ParseAppPrivateKey — RSA-ключ из PEM (GitHub выдаёт PKCS#1, но примем и PKCS#8).
+func ParseAppPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("github app key: no PEM block")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("github app key: %w", err)
+	}
+	rk, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app key: not an RSA key")
+	}
+	return rk, nil
+}
+
+// Token — installation token для owner/repo; ErrNoInstallation, если приложения там нет.
+func (a *AppAuth) Token(ctx context.Context, owner, repo string) (string, error) {
+	id, err := a.installationID(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if t, ok := a.cachedToken(id); ok {
+		return t, nil
+	}
+	v, err, _ := a.flight.Do("token:"+strconv.FormatInt(id, 10), func() (any, error) {
+		if t, ok := a.cachedToken(id); ok { // другой запрос уже выпустил
+			return t, nil
+		}
+		var out struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expires_at"`
+		}
+		if err := a.appRequest(ctx, http.MethodPost, fmt.Sprintf("/app/installations/%d/access_tokens", id), &out); err != nil {
+			return "", err
+		}
+		a.mu.Lock()
+		a.tokens[id] = installToken{token: out.Token, expires: out.ExpiresAt}
+		a.mu.Unlock()
+		return out.Token, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// cachedToken — выданный токен, если до истечения больше tokenRefreshBefore.
+func (a *AppAuth) cachedToken(id int64) (string, bool) {
+	a.mu.Lock()
+	t, ok := a.tokens[id]
+	a.mu.Unlock()
+	if ok && a.now().Add(tokenRefreshBefore).Before(t.expires) {
+		return t.token, true
+	}
+	return "", false
+}
+
+func (a *AppAuth) installationID(ctx context.Context, owner, repo string) (int64, error) {
+	key := strings.ToLower(owner + "/" + repo)
+	id, ok := a.cachedInstall(key)
+	if !ok {
+		v, err, _ := a.flight.Do("install:"+key, func() (any, error) {
+			if id, ok := a.cachedInstall(key); ok {
+				return id, nil
+			}
+			var out struct {
+				ID int64 `json:"id"`
+			}
+			err := a.appRequest(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/installation", owner, repo), &out)
+			if err != nil && err != ErrNotFound {
+				return int64(0), err
+			}
+			ttl := installCacheTTL
+			if out.ID == 0 {
+				ttl = noInstallCacheTTL
+			}
+			a.mu.Lock()
+			a.installs[key] = installEntry{id: out.ID, until: a.now().Add(ttl)}
+			a.mu.Unlock()
+			return out.ID, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		id = v.(int64)
+	}
+	if id == 0 {
+		return 0, ErrNoInstallation
+	}
+	return id, nil
+}
+
+// cachedInstall — installation из кэша (0 — «не установлено»).
+func (a *AppAuth) cachedInstall(key string) (int64, bool) {
+	a.mu.Lock()
+	e, ok := a.installs[key]
+	a.mu.Unlock()
+	if ok && a.now().Before(e.until) {
+		return e.id, true
+	}
+	return 0, false
+}
+
+// appRequest — запрос от имени приложения (Bearer JWT).
+func (a *AppAuth) appRequest(ctx context.Context, method, path string, v any) error {
+	jwt, err := a.appJWT()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	res, err := a.Doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		_, _ = io.Copy(io.Discard, res.Body)
+		return ErrNotFound
+	case res.StatusCode >= 500:
+		_, _ = io.Copy(io.Discard, res.Body)
+		return ErrUpstream
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		_, _ = io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("github app: unexpected status %d for %s", res.StatusCode, path)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// appJWT — JWT приложения. iat сдвигаем на минуту назад — на случай расхождения часов.
+func (a *AppAuth) appJWT() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if a.jwt != "" && now.Before(a.jwtUntil) {
+		return a.jwt, nil
+	}
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTTTL).Unix(),
+		"iss": strconv.FormatInt(a.AppID, 10),
+	})
+	enc := base64.RawURLEncoding
+	signing := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.Key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	a.jwt = signing + "." + enc.EncodeToString(sig)
+	a.jwtUntil = now.Add(appJWTTTL - time.Minute)
+	return a.jwt, nil
+}
+
+// AppDoer — HTTPDoer-обёртка: запросам к /repos/{owner}/{repo}/… без Authorization
+// подставляет installation token. Если приложение там не установлено или
+// GitHub не выдал токен — запрос уходит дальше как есть (Next подставит PAT).
+type AppDoer struct {
+	Next HTTPDoer
+	App  *AppAuth
+}
+
+func (d *AppDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return d.Next.Do(req)
+	}
+	owner, repo, ok := repoFromPath(req.URL.Path)
+	if !ok {
+		return d.Next.Do(req)
+	}
+	token, err := d.App.Token(req.Context(), owner, repo)
+	if err != nil {
+		return d.Next.Do(req)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return d.Next.Do(r)
+}
+
+// repoFromPath — owner/repo из пути REST API вида /repos/{owner}/{repo}[/…]
+// или Git LFS на github.com: /{owner}/{repo}.git/info/lfs/….
+func repoFromPath(p string) (string, string, bool) {
+	if i := strings.Index(p, ".git/info/lfs/"); i > 0 {
+		parts := strings.Split(strings.TrimPrefix(p[:i], "/"), "/")
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1], true
+		}
+	}
+	i := strings.Index(p, "/repos/")
+	if i < 0 {
+		return "", "", false
+	}
+	parts := strings.SplitN(p[i+len("/repos/"):], "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
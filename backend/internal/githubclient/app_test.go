@@ -0,0 +1,160 @@
+package githubclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGitHubApp — GitHub с установленным в o/private приложением.
+type fakeGitHubApp struct {
+	pub          *rsa.PublicKey
+	tokenIssued  atomic.Int32
+	installCalls atomic.Int32
+}
+
+func (f *fakeGitHubApp) validJWT(r *http.Request) bool {
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(f.pub, crypto.SHA256, sum[:], sig) != nil {
+		return false
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Exp int64  `json:"exp"`
+	}
+	b, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	_ = json.Unmarshal(b, &claims)
+	return claims.Iss == "42" && claims.Exp > time.Now().Unix()
+}
+
+func (f *fakeGitHubApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/repos/o/private/installation" && f.validJWT(r):
+		f.installCalls.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 7})
+	case strings.HasSuffix(r.URL.Path, "/installation") && f.validJWT(r):
+		f.installCalls.Add(1)
+		http.NotFound(w, r)
+	case r.URL.Path == "/app/installations/7/access_tokens" && r.Method == http.MethodPost && f.validJWT(r):
+		f.tokenIssued.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"token": "ghs_inst", "expires_at": time.Now().Add(time.Hour)})
+	case r.URL.Path == "/repos/o/private" && r.Header.Get("Authorization") == "Bearer ghs_inst":
+		_ = json.NewEncoder(w).Encode(map[string]any{"default_branch": "app"})
+	case r.URL.Path == "/repos/o/public" && r.Header.Get("Authorization") == "Bearer pat":
+		_ = json.NewEncoder(w).Encode(map[string]any{"default_branch": "pat"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAppDoer_InstallationTokenAndPATFallback(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	fake := &fakeGitHubApp{pub: &key.PublicKey}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	app, err := NewAppAuth(42, keyPEM, srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTokenPool([]string{"pat"}, 0)
+	c := &Client{BaseURL: srv.URL, Doer: &AppDoer{Next: &PoolDoer{Next: srv.Client(), Pool: pool}, App: app}}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if br, err := c.GetDefaultBranch(ctx, "o", "private"); err != nil || br != "app" {
+			t.Fatalf("private: %q %v", br, err)
+		}
+		if br, err := c.GetDefaultBranch(ctx, "o", "public"); err != nil || br != "pat" {
+			t.Fatalf("public: %q %v", br, err)
+		}
+	}
+	if n := fake.tokenIssued.Load(); n != 1 {
+		t.Errorf("access tokens issued %d times, want 1 (cached)", n)
+	}
+	if n := fake.installCalls.Load(); n != 2 {
+		t.Errorf("installation lookups = %d, want 2 (one per repo)", n)
+	}
+
+	// токен протух — берём новый
+	app.now = func() time.Time { return time.Now().Add(58 * time.Minute) }
+	if _, err := app.Token(ctx, "o", "private"); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.tokenIssued.Load(); n != 2 {
+		t.Errorf("access tokens issued %d times after expiry, want 2", n)
+	}
+}
+
+func TestAppAuth_ConcurrentMissesShareOneRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	fake := &fakeGitHubApp{pub: &key.PublicKey}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	app, err := NewAppAuth(42, keyPEM, srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tok, err := app.Token(ctx, "o", "private"); err != nil || tok != "ghs_inst" {
+				t.Errorf("token: %q %v", tok, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fake.tokenIssued.Load(); n != 1 {
+		t.Errorf("access tokens issued %d times, want 1", n)
+	}
+	if n := fake.installCalls.Load(); n != 1 {
+		t.Errorf("installation lookups = %d, want 1", n)
+	}
+
+	// «не установлено» помним недолго: приложение могут поставить в любой момент
+	if _, err := app.Token(ctx, "o", "public"); err != ErrNoInstallation {
+		t.Fatalf("public: err = %v", err)
+	}
+	app.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := app.Token(ctx, "o", "public"); err != ErrNoInstallation {
+		t.Fatalf("public: err = %v", err)
+	}
+	if n := fake.installCalls.Load(); n != 3 {
+		t.Errorf("installation lookups = %d, want 3 (negative result expired)", n)
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"encoding/json" // декодирование JSON-ответов GitHub API
 	"errors"        // константные/обёрточные ошибки
 	"fmt"           // форматирование строк (Sprintf)
-	"io"            // чтение тела ответа (io.ReadAll)
+	"log/slog"
 	"net/http"      // HTTP-клиент, запросы/ответы
+	"os"
 	"strings"       // манипуляция строками (проверка/склейка путей)
 	"time"          // таймауты, тип Duration
 	"net"
@@ -21,10 +22,13 @@ type HTTPDoer interface {
 
 // Client — наш обёрточный GitHub API клиент.
 type Client struct {
-	BaseURL string        // базовый адрес API, по умолчанию "https://api.github.com"
-	Token   string        // OAuth-токен (если есть), добавим заголовок Authorization: Bearer <token>
-	Doer    HTTPDoer      // конкретная реализация Doer (обычно *http.Client, но можно мок)
-	Timeout time.Duration // общий таймаут (настраиваем http.Client.Timeout)
+	BaseURL  string        // базовый адрес API, по умолчанию "https://api.github.com"
+	Token    string        // OAuth-токен (если есть), добавим заголовок Authorization: Bearer <token>
+	Doer     HTTPDoer      // конкретная реализация Doer (обычно *http.Client, но можно мок)
+	Timeout  time.Duration // общий таймаут (настраиваем http.Client.Timeout)
+	Pool     *TokenPool    // пул серверных токенов (nil — Token или анонимно)
+	WebURL   string        // github.com (batch API Git LFS); пусто — "https://github.com"
+	Download HTTPDoer      // без токенов и кэша: ссылки на чужие хосты (объекты LFS); nil — http.DefaultClient
 }
 
 // Константные ошибки для семантики наверх (хендлеру легче мапить коды)
@@ -62,12 +66,92 @@ func New(cfg config.Config) *Client {
     // Без Client.Timeout — его ставим точечно в методах (см. GetTarball)
     httpClient := &http.Client{Transport: tr}
 
+    // Повторы (5xx, сеть, вторичный лимит) — самый внутренний слой:
+    // каждый повтор идёт с тем же токеном, что выбрали слои выше
+    var doer HTTPDoer = &RetryDoer{Next: httpClient, MaxRetries: cfg.GitHubMaxRetries, Budget: cfg.GitHubRetryBudget}
+    download := doer
+
+    // Git LFS на github.com ждёт токен как Basic — переписываем то, что подставят слои выше
+    doer = &LFSAuthDoer{Next: doer}
+
+    // HTTP-кэш с ETag: повторные запросы без изменений отвечают 304 и не тратят лимит.
+    // Под пулом и App: Authorization уже выбран, он входит в ключ (cacheKey) —
+    // ETag, полученный одним токеном, ревалидируется тем же токеном
+    if store := newCacheStore(cfg); store != nil {
+        doer = &CachingDoer{Next: doer, Store: store}
+    }
+
+    // Пул токенов: токен выбирается на каждый запрос по остатку квоты,
+    // поэтому Client.Token остаётся пустым (иначе пул не подставит свой)
+    token := cfg.GitHubToken
+    pool := NewTokenPool(append([]string{cfg.GitHubToken}, cfg.GitHubTokens...), cfg.GitHubTokenReserve)
+    if pool != nil {
+        doer = &PoolDoer{Next: doer, Pool: pool}
+        token = ""
+    }
+
+    // GitHub App: для репозиториев, где приложение установлено, — installation token;
+    // его запросы идут «голым» транспортом, мимо пула PAT
+    if app := newAppAuth(cfg, &RetryDoer{Next: httpClient, MaxRetries: cfg.GitHubMaxRetries, Budget: cfg.GitHubRetryBudget}); app != nil {
+        doer = &AppDoer{Next: doer, App: app}
+    }
+
+    // токен пользователя (OAuth) из контекста — самый внешний слой
+    doer = &ContextTokenDoer{Next: doer}
+
     return &Client{
-        BaseURL: "https://api.github.com",
-        Token:   cfg.GitHubToken,
-        Doer:    httpClient,
-        Timeout: cfg.RequestTimeout, // можно хранить для JSON-методов
+        BaseURL:  "https://api.github.com",
+        Token:    token,
+        Doer:     doer,
+        Timeout:  cfg.RequestTimeout, // можно хранить для JSON-методов
+        Pool:     pool,
+        Download: download,
+    }
+}
+
+// newAppAuth — GitHub App из GITHUB_APP_ID + ключа; nil — не настроено или ключ битый.
+func newAppAuth(cfg config.Config, doer HTTPDoer) *AppAuth {
+    if cfg.GitHubAppID == 0 {
+        return nil
     }
+    key := []byte(cfg.GitHubAppPrivateKey)
+    if cfg.GitHubAppPrivateKeyFile != "" {
+        b, err := os.ReadFile(cfg.GitHubAppPrivateKeyFile)
+        if err != nil {
+            slog.Error("github app key read failed", slog.String("error", err.Error()))
+            return nil
+        }
+        key = b
+    }
+    app, err := NewAppAuth(cfg.GitHubAppID, key, "https://api.github.com", doer)
+    if err != nil {
+        slog.Error("github app init failed", slog.String("error", err.Error()))
+        return nil
+    }
+    slog.Info("github app auth enabled", slog.Int64("app_id", cfg.GitHubAppID))
+    return app
+}
+
+// newCacheStore — хранилище HTTP-кэша по GITHUB_CACHE_BACKEND; nil — кэш выключен.
+func newCacheStore(cfg config.Config) CacheStore {
+    switch cfg.GitHubCacheBackend {
+    case "memory":
+        return NewMemoryCache(cfg.GitHubCacheMaxBytes)
+    case "disk":
+        dc, err := NewDiskCache(cfg.GitHubCacheDir, cfg.GitHubCacheTTL, cfg.GitHubCacheMaxBytes)
+        if err != nil {
+            slog.Error("github cache dir init failed, using memory", slog.String("error", err.Error()))
+            return NewMemoryCache(cfg.GitHubCacheMaxBytes)
+        }
+        return dc
+    case "redis":
+        addr := cfg.GitHubCacheRedisAddr
+        if addr == "" {
+            addr = cfg.RedisAddr
+        }
+        return NewRedisCache(addr, cfg.RedisPassword, cfg.GitHubCacheRedisDB, cfg.GitHubCacheRedisTTL)
+    }
+    return nil
 }
 
 // ensureLeadingSlash — маленький helper: гарантирует, что path начинается с "/".
@@ -103,44 +187,13 @@ func (c *Client) GetJSON(ctx context.Context, path string, v any) (int, error) {
 	}
 	defer res.Body.Close() // гарантируем закрытие тела ответа, чтобы не протекли ресурсы
 
-	// Специальная обработка rate-limit: GitHub обычно шлёт 403 с X-RateLimit-Remaining: 0,
-	// также может прилететь 429 (Too Many Requests).
-	if res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusTooManyRequests {
-		// Читаем заголовок X-RateLimit-Remaining — если "0", то это rate limit.
-		if strings.TrimSpace(res.Header.Get("X-RateLimit-Remaining")) == "0" {
-			// Извлекаем X-RateLimit-Reset — секунды UNIX, когда лимит сбросится.
-			resetStr := res.Header.Get("X-RateLimit-Reset")
-			var reset int64
-			if resetStr != "" {
-				// преобразуем строку в int64; если ошибка — оставим 0
-				fmt.Sscanf(resetStr, "%d", &reset)
-			}
-			return res.StatusCode, &RateLimitedError{Reset: reset} // возвращаем осмысленную ошибку
-		}
-		// Если Remaining не 0 — отдаём как есть (может быть другой запрет)
-	}
-
 	// Для 2xx успешных ответов — декодируем JSON в v.
 	if res.StatusCode >= 200 && res.StatusCode < 300 {
-		// Можно декодировать напрямую из res.Body в v:
-		// return res.StatusCode, json.NewDecoder(res.Body).Decode(v)
-		// Но иногда удобно прочитать полностью (для логов/отладки). Сделаем напрямую:
 		return res.StatusCode, json.NewDecoder(res.Body).Decode(v)
 	}
 
-	// Для неуспехов (4xx/5xx) — прочитаем тело, чтобы не оставить сокет полузанятым.
-	_, _ = io.ReadAll(res.Body) // игнорируем содержимое; главное — вычитать
-
-	// Карта статусов → ошибок верхнего уровня
-	if res.StatusCode == http.StatusNotFound { // 404
-		return res.StatusCode, ErrNotFound
-	}
-	if res.StatusCode >= 500 { // любые 5xx
-		return res.StatusCode, ErrUpstream
-	}
-
-	// Прочие коды — возвращаем "обычную" ошибку с кодом
-	return res.StatusCode, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	// Неуспех → типизированная ошибка: rate limit (основной/вторичный), 403, 404, 5xx (см. errors.go)
+	return res.StatusCode, statusError(res)
 }
 
 // GetDefaultBranch достаёт default_branch репозитория через /repos/{owner}/{repo}.
@@ -0,0 +1,96 @@
+package githubclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CommitRef — результат ResolveCommit: SHA коммита и ETag ответа для ревалидации.
+type CommitRef struct {
+	SHA  string
+	ETag string
+}
+
+// ResolveCommit — ref (ветка/тег/SHA) → SHA коммита через /commits/{ref}
+// с Accept: application/vnd.github.sha (в ответе — только SHA текстом).
+// Если передан etag и ref не сдвинулся, GitHub отвечает 304: notModified=true,
+// такой ответ не расходует rate limit.
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref, etag string) (CommitRef, bool, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.BaseURL, owner, repo, ref)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return CommitRef{}, false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	res, err := c.Doer.Do(req)
+	if err != nil {
+		return CommitRef{}, false, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return CommitRef{ETag: etag}, true, nil
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusUnprocessableEntity:
+		// 422 — «No commit found for SHA»: для нас то же, что 404
+		_, _ = io.Copy(io.Discard, res.Body)
+		return CommitRef{}, false, ErrNotFound
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		return CommitRef{}, false, statusError(res)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if err != nil {
+		return CommitRef{}, false, err
+	}
+	sha := strings.TrimSpace(string(body))
+	if !IsCommitSHA(sha) {
+		return CommitRef{}, false, fmt.Errorf("unexpected commit sha %q", sha)
+	}
+	return CommitRef{SHA: sha, ETag: res.Header.Get("ETag")}, false, nil
+}
+
+// IsCommitSHA — полный SHA-1 коммита (40 hex). Такой ref неизменяем — резолвить не нужно.
+func IsCommitSHA(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchingRefs — имена веток и тегов, начинающиеся с prefix (git/matching-refs).
+// Нужно, чтобы разделить «release/1.2/services/api» на ref и путь.
+func (c *Client) MatchingRefs(ctx context.Context, owner, repo, prefix string) ([]string, error) {
+	var names []string
+	for _, kind := range []string{"heads", "tags"} {
+		var out []struct {
+			Ref string `json:"ref"`
+		}
+		path := fmt.Sprintf("/repos/%s/%s/git/matching-refs/%s/%s", owner, repo, kind, url.PathEscape(prefix))
+		if _, err := c.GetJSON(ctx, path, &out); err != nil {
+			return nil, err
+		}
+		for _, r := range out {
+			names = append(names, strings.TrimPrefix(r.Ref, "refs/"+kind+"/"))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,36 @@
+package githubclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxCompareFiles — больше файлов compare API не отдаёт, остальные молча отрезает.
+const MaxCompareFiles = 300
+
+// Comparison — /repos/{owner}/{repo}/compare/{base}...{head}.
+// Files — в формате PR (PullFile): статус, строки, patch.
+type Comparison struct {
+	Status       string     `json:"status"` // ahead|behind|diverged|identical
+	AheadBy      int        `json:"ahead_by"`
+	BehindBy     int        `json:"behind_by"`
+	TotalCommits int        `json:"total_commits"`
+	MergeBase    CommitSHA  `json:"merge_base_commit"`
+	Files        []PullFile `json:"files"`
+}
+
+// CommitSHA — коммит в ответах GitHub, от которого нужен только SHA.
+type CommitSHA struct {
+	SHA string `json:"sha"`
+}
+
+// Truncated — GitHub отдал не все изменённые файлы.
+func (c Comparison) Truncated() bool { return len(c.Files) >= MaxCompareFiles }
+
+// CompareRefs — изменения от base до head (ветки, теги, SHA) с патчами.
+// Один запрос: больше MaxCompareFiles файлов GitHub всё равно не отдаёт.
+func (c *Client) CompareRefs(ctx context.Context, owner, repo, base, head string) (Comparison, error) {
+	var cmp Comparison
+	_, err := c.GetJSON(ctx, fmt.Sprintf("/repos/%s/%s/compare/%s...%s", owner, repo, base, head), &cmp)
+	return cmp, err
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 // ErrTooLarge — если Content-Length > лимита (мы отказываем заранее).
@@ -37,28 +36,8 @@ func (c *Client) GetRawFile(ctx context.Context, owner, repo, pth, ref string, m
 	}
 	defer res.Body.Close()
 
-	// rate-limit: 403/429 + Remaining==0
-	if res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusTooManyRequests {
-		if strings.TrimSpace(res.Header.Get("X-RateLimit-Remaining")) == "0" {
-			var reset int64
-			if v := res.Header.Get("X-RateLimit-Reset"); v != "" {
-				if n, _ := strconv.ParseInt(v, 10, 64); n > 0 {
-					reset = n
-				}
-			}
-			return nil, false, &RateLimitedError{Reset: reset}
-		}
-	}
-	if res.StatusCode == http.StatusNotFound {
-		return nil, false, ErrNotFound
-	}
-	if res.StatusCode >= 500 {
-		_, _ = io.ReadAll(res.Body)
-		return nil, false, ErrUpstream
-	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		_, _ = io.ReadAll(res.Body)
-		return nil, false, fmt.Errorf("unexpected status: %d", res.StatusCode)
+		return nil, false, statusError(res)
 	}
 
 	// Если сервер назвал размер — можем отказать сразу.
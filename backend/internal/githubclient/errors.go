@@ -0,0 +1,101 @@
+package githubclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecondaryRateLimitError — «вторичный» лимит GitHub (слишком часто/параллельно):
+// 403/429 без X-RateLimit-Remaining: 0, обычно с Retry-After.
+type SecondaryRateLimitError struct {
+	RetryAfter time.Duration // без Retry-After — secondaryWait (GitHub рекомендует минуту)
+	Message    string
+}
+
+func (e *SecondaryRateLimitError) Error() string {
+	return fmt.Sprintf("secondary_rate_limited (retry after %s): %s", e.RetryAfter, e.Message)
+}
+
+// ForbiddenError — 401/403 не из-за лимитов: нет прав, SSO, заблокированный токен.
+type ForbiddenError struct {
+	Status  int
+	Message string // message из ответа GitHub
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden (%d): %s", e.Status, e.Message)
+}
+
+// statusError — не-2xx ответ → типизированная ошибка. Тело вычитывается (до 64 KiB).
+func statusError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	switch {
+	case isRateLimited(res):
+		reset, _ := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+		return &RateLimitedError{Reset: reset}
+	case isSecondaryRateLimit(res, body):
+		ra := retryAfter(res.Header)
+		if ra <= 0 {
+			ra = secondaryWait
+		}
+		return &SecondaryRateLimitError{RetryAfter: ra, Message: apiMessage(body)}
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return &ForbiddenError{Status: res.StatusCode, Message: apiMessage(body)}
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 500:
+		return ErrUpstream
+	}
+	return fmt.Errorf("unexpected status: %d", res.StatusCode)
+}
+
+// isSecondaryRateLimit — 429 или 403 с Retry-After/текстом про secondary rate limit.
+func isSecondaryRateLimit(res *http.Response, body []byte) bool {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if res.StatusCode == http.StatusTooManyRequests || res.Header.Get("Retry-After") != "" {
+		return true
+	}
+	m := strings.ToLower(apiMessage(body))
+	return strings.Contains(m, "secondary rate limit") || strings.Contains(m, "abuse")
+}
+
+// retryAfter — Retry-After в секундах или HTTP-дате; 0 — нет/не разобрали.
+func retryAfter(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// apiMessage — поле message из JSON-ошибки GitHub (или начало тела).
+func apiMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Message != "" {
+		return e.Message
+	}
+	s := strings.TrimSpace(string(body))
+	if len(s) > 200 {
+		s = s[:200]
+	}
+	return s
+}
@@ -0,0 +1,208 @@
+package githubclient
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxBody — ответы больше этого не кэшируем (отдаём потоком как есть).
+const DefaultCacheMaxBody = 8 << 20
+
+// CachedResponse — сохранённый 200-ответ GitHub вместе с заголовками (ETag, Last-Modified).
+type CachedResponse struct {
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"storedAt"`
+}
+
+func (c *CachedResponse) size() int64 { return int64(len(c.Body)) + 512 }
+
+// CacheStore — хранилище HTTP-кэша. Ошибки хранилища — промах, не отказ.
+// Реализации: MemoryCache, DiskCache, RedisCache.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool)
+	Set(ctx context.Context, key string, r *CachedResponse)
+}
+
+// CachingDoer — HTTPDoer-обёртка с условными запросами: для закэшированного
+// ответа шлём If-None-Match/If-Modified-Since, и на 304 (не тратит rate limit)
+// отдаём сохранённое тело как обычный 200.
+//
+// Не трогаем: не-GET, запросы с Range или своими условными заголовками
+// (ResolveCommit сам ведёт ETag) и запросы с Cache-Control: no-store (tarball).
+type CachingDoer struct {
+	Next    HTTPDoer
+	Store   CacheStore
+	MaxBody int64 // 0 — DefaultCacheMaxBody
+}
+
+// cacheStatusHeader — заголовок ответа для отладки: hit (304) или miss.
+const cacheStatusHeader = "X-Cache"
+
+func (d *CachingDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.Store == nil || !cacheableRequest(req) {
+		return d.Next.Do(req)
+	}
+	ctx := req.Context()
+	key := cacheKey(req)
+
+	cached, ok := d.Store.Get(ctx, key)
+	if ok {
+		req = req.Clone(ctx)
+		if v := cached.Header.Get("ETag"); v != "" {
+			req.Header.Set("If-None-Match", v)
+		}
+		if v := cached.Header.Get("Last-Modified"); v != "" {
+			req.Header.Set("If-Modified-Since", v)
+		}
+	}
+
+	res, err := d.Next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if ok && res.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		return cached.response(req, res.Header), nil
+	}
+	if res.StatusCode != http.StatusOK || (res.Header.Get("ETag") == "" && res.Header.Get("Last-Modified") == "") {
+		return res, nil
+	}
+
+	maxBody := d.MaxBody
+	if maxBody <= 0 {
+		maxBody = DefaultCacheMaxBody
+	}
+	if res.ContentLength > maxBody {
+		return res, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBody+1))
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > maxBody {
+		// не влезло — отдаём прочитанное + остаток потока
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return res, nil
+	}
+	res.Body.Close()
+
+	d.Store.Set(ctx, key, &CachedResponse{Header: res.Header.Clone(), Body: body, StoredAt: time.Now()})
+	res.Header.Set(cacheStatusHeader, "miss")
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	return res, nil
+}
+
+// response — 200 из кэша; лимиты берём из свежего 304, чтобы они были актуальны.
+func (c *CachedResponse) response(req *http.Request, fresh http.Header) *http.Response {
+	h := c.Header.Clone()
+	for k, v := range fresh {
+		if strings.HasPrefix(k, "X-Ratelimit-") {
+			h[k] = v
+		}
+	}
+	h.Set(cacheStatusHeader, "hit")
+	h.Set("Content-Length", strconv.Itoa(len(c.Body)))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+func cacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	h := req.Header
+	return h.Get("Range") == "" && h.Get("If-None-Match") == "" && h.Get("If-Modified-Since") == "" &&
+		!strings.Contains(h.Get("Cache-Control"), "no-store")
+}
+
+// cacheKey — URL + Accept + хэш Authorization: разные токены видят разные
+// приватные репозитории, а сам токен в ключ (и в Redis/на диск) не попадает.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + req.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ===== MemoryCache: LRU в памяти процесса, ограничен по байтам =====
+
+// DefaultCacheMaxBytes — ёмкость MemoryCache по умолчанию.
+const DefaultCacheMaxBytes = 64 << 20
+
+type MemoryCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	ll       *list.List // front — самые свежие
+	byKey    map[string]*list.Element
+}
+
+type memCacheEntry struct {
+	key string
+	r   *CachedResponse
+}
+
+// NewMemoryCache — maxBytes <= 0 — DefaultCacheMaxBytes.
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultCacheMaxBytes
+	}
+	return &MemoryCache{maxBytes: maxBytes, ll: list.New(), byKey: map[string]*list.Element{}}
+}
+
+func (m *MemoryCache) Get(_ context.Context, key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return el.Value.(*memCacheEntry).r, true
+}
+
+func (m *MemoryCache) Set(_ context.Context, key string, r *CachedResponse) {
+	if r.size() > m.maxBytes {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.byKey[key]; ok {
+		m.removeLocked(el)
+	}
+	m.byKey[key] = m.ll.PushFront(&memCacheEntry{key: key, r: r})
+	m.bytes += r.size()
+	for m.bytes > m.maxBytes {
+		m.removeLocked(m.ll.Back())
+	}
+}
+
+func (m *MemoryCache) removeLocked(el *list.Element) {
+	e := el.Value.(*memCacheEntry)
+	m.ll.Remove(el)
+	delete(m.byKey, e.key)
+	m.bytes -= e.r.size()
+}
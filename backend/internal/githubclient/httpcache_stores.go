@@ -0,0 +1,197 @@
+package githubclient
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultDiskCacheMaxBytes — ёмкость DiskCache по умолчанию.
+const DefaultDiskCacheMaxBytes = 512 << 20
+
+// DiskCache — ответы файлами в dir (по файлу на ключ). Переживает рестарт
+// процесса; удобно для локальной разработки и одиночного воркера.
+// Объём ограничен maxBytes: при переполнении удаляем просроченные и самые
+// давно прочитанные файлы (mtime обновляется на каждом попадании).
+type DiskCache struct {
+	dir      string
+	maxAge   time.Duration // 0 — без срока
+	maxBytes int64
+
+	mu    sync.Mutex
+	bytes int64 // оценка занятого места, уточняется при каждой чистке
+}
+
+// NewDiskCache — maxBytes <= 0 — DefaultDiskCacheMaxBytes.
+func NewDiskCache(dir string, maxAge time.Duration, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultDiskCacheMaxBytes
+	}
+	d := &DiskCache{dir: dir, maxAge: maxAge, maxBytes: maxBytes}
+	d.mu.Lock()
+	d.sweepLocked()
+	d.mu.Unlock()
+	return d, nil
+}
+
+func (d *DiskCache) path(key string) string { return filepath.Join(d.dir, key+".json") }
+
+func (d *DiskCache) Get(_ context.Context, key string) (*CachedResponse, bool) {
+	p := d.path(key)
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	var r CachedResponse
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, false
+	}
+	if d.maxAge > 0 && time.Since(r.StoredAt) > d.maxAge {
+		_ = os.Remove(p)
+		return nil, false
+	}
+	// mtime — время последнего чтения: по нему sweepLocked выбирает, что удалить
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return &r, true
+}
+
+func (d *DiskCache) Set(_ context.Context, key string, r *CachedResponse) {
+	b, err := json.Marshal(r)
+	if err != nil || int64(len(b)) > d.maxBytes {
+		return
+	}
+	// пишем во временный файл и переименовываем — читатель не увидит половину
+	tmp, err := os.CreateTemp(d.dir, key+".*.tmp")
+	if err != nil {
+		slog.Warn("github cache disk write failed", slog.String("error", err.Error()))
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.bytes += int64(len(b))
+	if d.bytes > d.maxBytes {
+		d.sweepLocked()
+	}
+}
+
+// sweepLocked — пересчитать занятое место, удалить просроченное и, если всё ещё
+// больше maxBytes, самые давно прочитанные файлы — до 90% ёмкости (чтобы не
+// чистить на каждой записи).
+func (d *DiskCache) sweepLocked() {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path  string
+		size  int64
+		mtime time.Time
+	}
+	var files []file
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		p := filepath.Join(d.dir, e.Name())
+		// StoredAt не старше mtime: просроченное по mtime просрочено и по StoredAt
+		if d.maxAge > 0 && time.Since(info.ModTime()) > d.maxAge {
+			_ = os.Remove(p)
+			continue
+		}
+		files = append(files, file{path: p, size: info.Size(), mtime: info.ModTime()})
+		total += info.Size()
+	}
+	if total > d.maxBytes {
+		sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+		target := d.maxBytes / 10 * 9
+		for _, f := range files {
+			if total <= target {
+				break
+			}
+			if os.Remove(f.path) == nil {
+				total -= f.size
+			}
+		}
+	}
+	d.bytes = total
+}
+
+// redisCachePrefix — пространство ключей HTTP-кэша в Redis.
+const redisCachePrefix = "rep2prompt:ghcache:"
+
+// RedisCacheMaxBody — ответы крупнее в Redis не кладём: память Redis дороже
+// диска, а большие списки (деревья, файлы PR) редко запрашиваются повторно.
+const RedisCacheMaxBody = 1 << 20
+
+// RedisCache — HTTP-кэш в Redis, общий для API и воркеров. Держать его стоит
+// в отдельной базе или отдельном инстансе, не в базе очереди asynq: у всех
+// ключей есть TTL, так что с maxmemory-policy volatile-lru Redis вытесняет
+// кэш, а не задачи.
+type RedisCache struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func NewRedisCache(addr, password string, db int, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		rdb: redis.NewClient(&redis.Options{Addr: addr, Password: password, DB: db}),
+		ttl: ttl,
+	}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (*CachedResponse, bool) {
+	b, err := c.rdb.Get(ctx, redisCachePrefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("github cache redis get failed", slog.String("error", err.Error()))
+		}
+		return nil, false
+	}
+	var r CachedResponse
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, r *CachedResponse) {
+	if len(r.Body) > RedisCacheMaxBody {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := c.rdb.Set(ctx, redisCachePrefix+key, b, c.ttl).Err(); err != nil {
+		slog.Warn("github cache redis set failed", slog.String("error", err.Error()))
+	}
+}
+
+// Close — закрыть соединения.
+func (c *RedisCache) Close() error { return c.rdb.Close() }
@@ -0,0 +1,162 @@
+package githubclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingDoer_RevalidatesWithETag(t *testing.T) {
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"default_branch":"dev"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Doer: &CachingDoer{Next: srv.Client(), Store: NewMemoryCache(0)}}
+	for i := 0; i < 3; i++ {
+		br, err := c.GetDefaultBranch(context.Background(), "o", "r")
+		if err != nil || br != "dev" {
+			t.Fatalf("call %d: branch=%q err=%v", i, br, err)
+		}
+	}
+	if full.Load() != 1 || notModified.Load() != 2 {
+		t.Errorf("full=%d notModified=%d, want 1 and 2", full.Load(), notModified.Load())
+	}
+}
+
+func TestCachingDoer_SkipsNoStoreAndConditional(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string // If-None-Match каждого запроса
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("If-None-Match"))
+		mu.Unlock()
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+
+	d := &CachingDoer{Next: srv.Client(), Store: NewMemoryCache(0)}
+	do := func(path string, header map[string]string) int {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		res, err := d.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// no-store не кэшируется и не ревалидируется
+	do("/tarball", map[string]string{"Cache-Control": "no-store"})
+	do("/tarball", map[string]string{"Cache-Control": "no-store"})
+
+	// свои условные заголовки вызывающего (ResolveCommit) уходят как есть,
+	// а 304 не подменяется закэшированным 200
+	do("/commits/main", nil)
+	if code := do("/commits/main", map[string]string{"If-None-Match": `"mine"`}); code != http.StatusNotModified {
+		t.Errorf("caller's If-None-Match: status %d, want 304", code)
+	}
+	if code := do("/commits/main", map[string]string{"If-Modified-Since": "Mon, 02 Jan 2006 15:04:05 GMT"}); code != http.StatusNotModified {
+		t.Errorf("caller's If-Modified-Since: status %d, want 304", code)
+	}
+
+	want := []string{"", "", "", `"mine"`, ""}
+	if strings.Join(seen, "|") != strings.Join(want, "|") {
+		t.Errorf("If-None-Match sent = %q, want %q", seen, want)
+	}
+}
+
+func TestDiskCache_EvictsLeastRecentlyRead(t *testing.T) {
+	ctx := context.Background()
+	body := func() *CachedResponse {
+		return &CachedResponse{Header: http.Header{}, Body: []byte(strings.Repeat("x", 1000)), StoredAt: time.Now()}
+	}
+	d, err := NewDiskCache(t.TempDir(), time.Hour, 5000) // ~1.4 КБ на файл: влезают три
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	for i, key := range []string{"a", "b", "c"} {
+		d.Set(ctx, key, body())
+		ts := old.Add(time.Duration(i) * time.Second)
+		_ = os.Chtimes(d.path(key), ts, ts)
+	}
+	// "a" прочитали последним — вытеснять надо "b"
+	if _, ok := d.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	d.Set(ctx, "d", body())
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, ok := d.Get(ctx, key); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+// Кэш под пулом: ETag, выданный одному токену, ревалидируется тем же токеном.
+func TestCachingDoer_UnderPoolKeepsTokenPerETag(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	var conditional, mismatched atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		calls[tok]++
+		n := calls[tok]
+		mu.Unlock()
+		// квота тает по-разному — пул чередует токены
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(100-n*(len(tok)*7)))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			conditional.Add(1)
+			if inm != `"etag-`+tok+`"` {
+				mismatched.Add(1)
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"etag-`+tok+`"`)
+		_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer srv.Close()
+
+	pool := NewTokenPool([]string{"a", "bb"}, 0)
+	c := &Client{BaseURL: srv.URL, Doer: &PoolDoer{Next: &CachingDoer{Next: srv.Client(), Store: NewMemoryCache(0)}, Pool: pool}}
+	for i := 0; i < 6; i++ {
+		if _, err := c.GetDefaultBranch(context.Background(), "o", "r"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(calls) != 2 || conditional.Load() == 0 {
+		t.Fatalf("pool did not alternate or cache was not used: calls=%v conditional=%d", calls, conditional.Load())
+	}
+	if mismatched.Load() != 0 {
+		t.Errorf("%d revalidations carried another token's ETag", mismatched.Load())
+	}
+}
@@ -0,0 +1,131 @@
+package githubclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Git LFS batch API живёт на github.com, а не на api.github.com.
+const (
+	defaultWebURL = "https://github.com"
+	lfsMediaType  = "application/vnd.git-lfs+json"
+)
+
+type lfsObject struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		OID     string `json:"oid"`
+		Actions struct {
+			Download *struct {
+				Href   string            `json:"href"`
+				Header map[string]string `json:"header"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// OpenLFSObject — содержимое объекта Git LFS (oid — sha256 без префикса).
+// Сначала batch API (operation=download) отдаёт подписанную ссылку на хранилище,
+// затем качаем по ней через Client.Download — наш токен на чужой хост не уходит.
+// Поток ОБЯЗАТЕЛЬНО закрыть вызывающему.
+func (c *Client) OpenLFSObject(ctx context.Context, owner, repo, oid string, size int64) (io.ReadCloser, error) {
+	body, err := json.Marshal(map[string]any{
+		"operation": "download",
+		"transfers": []string{"basic"},
+		"objects":   []lfsObject{{OID: oid, Size: size}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	web := c.WebURL
+	if web == "" {
+		web = defaultWebURL
+	}
+	u := fmt.Sprintf("%s/%s/%s.git/info/lfs/objects/batch", web, owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	res, err := c.Doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, statusError(res)
+	}
+	var out lfsBatchResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Objects) == 0 {
+		return nil, ErrNotFound
+	}
+	obj := out.Objects[0]
+	switch {
+	case obj.Error != nil && obj.Error.Code == http.StatusNotFound:
+		return nil, ErrNotFound
+	case obj.Error != nil:
+		return nil, fmt.Errorf("lfs object %s: %d %s", oid, obj.Error.Code, obj.Error.Message)
+	case obj.Actions.Download == nil || obj.Actions.Download.Href == "":
+		return nil, ErrNotFound
+	}
+
+	dl := obj.Actions.Download
+	dreq, err := http.NewRequestWithContext(ctx, http.MethodGet, dl.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range dl.Header {
+		dreq.Header.Set(k, v)
+	}
+	var doer HTTPDoer = http.DefaultClient
+	if c.Download != nil {
+		doer = c.Download
+	}
+	dres, err := doer.Do(dreq)
+	if err != nil {
+		return nil, err
+	}
+	if dres.StatusCode < 200 || dres.StatusCode >= 300 {
+		err := statusError(dres)
+		dres.Body.Close()
+		return nil, err
+	}
+	return dres.Body, nil
+}
+
+// LFSAuthDoer — batch API Git LFS на github.com принимает токен только как
+// Basic (x-access-token:<token>). Стоит внутри слоёв, выбирающих токен,
+// и переписывает их Bearer для запросов к …/info/lfs/.
+type LFSAuthDoer struct {
+	Next HTTPDoer
+}
+
+func (d *LFSAuthDoer) Do(req *http.Request) (*http.Response, error) {
+	tok, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.Contains(req.URL.Path, "/info/lfs/") {
+		return d.Next.Do(req)
+	}
+	r := req.Clone(req.Context())
+	r.SetBasicAuth("x-access-token", tok)
+	return d.Next.Do(r)
+}
@@ -1,55 +1,134 @@
 package githubclient
 
 import (
-	"errors"     // пакет для создания и возврата ошибок
-	"net/url"    // парсер URL для https-ссылок
-	"strings"    // работа со строками (обрезка, проверка префиксов/суффиксов)
+	"errors"  // пакет для создания и возврата ошибок
+	"net/url" // парсер URL для https/ssh/git-ссылок
+	"strconv"
+	"strings" // работа со строками (обрезка, проверка префиксов/суффиксов)
 )
 
-func ParseGitHubURL(raw string) (string, string, error){
+// RepoRef — что удалось вытащить из ссылки на GitHub.
+type RepoRef struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Ref   string `json:"ref,omitempty"`  // ветка/тег/SHA из tree/blob/commit-ссылки
+	Path  string `json:"path,omitempty"` // путь внутри репозитория (tree/blob)
+	PR    int    `json:"pr,omitempty"`   // номер pull request (pull/<n>)
+
+	// RefPath — «<ref>/<path>» из tree/blob-ссылки как есть. Ref может содержать
+	// слэши (release/1.2), поэтому Ref/Path выше — лишь догадка по первому сегменту;
+	// точное деление — SplitRefPath по списку веток и тегов.
+	RefPath string `json:"-"`
+}
+
+// ParseGitHubURL — только owner и repo (старый контракт).
+func ParseGitHubURL(raw string) (string, string, error) {
+	r, err := ParseRepoURL(raw)
+	return r.Owner, r.Repo, err
+}
+
+// ParseRepoURL разбирает ссылки вида:
+//
+//	https://github.com/o/r[.git]
+//	https://github.com/o/r/tree/<ref>/<path>, .../blob/<ref>/<path>
+//	https://github.com/o/r/commit/<sha>, .../pull/<n>[/files]
+//	git@github.com:o/r.git, ssh://git@github.com/o/r.git, git://github.com/o/r.git
+func ParseRepoURL(raw string) (RepoRef, error) {
 	raw = strings.TrimSpace(raw)
-	if raw == ""{
-		return "", "", errors.New("empty url")
+	if raw == "" {
+		return RepoRef{}, errors.New("empty url")
 	}
 
-	if strings.HasPrefix(raw, "git@"){
+	// scp-подобный ssh: git@github.com:owner/repo.git
+	if strings.HasPrefix(raw, "git@") {
 		const prefix = "git@github.com:"
-		if !strings.HasPrefix(raw, prefix){
-			return "","", errors.New("unsupported ssh host")
+		if !strings.HasPrefix(raw, prefix) {
+			return RepoRef{}, errors.New("unsupported ssh host")
 		}
-		path := strings.TrimPrefix(raw, prefix)
-		parts := strings.Split(path, "/")
-		if len(parts) != 2 {                    
-			return "", "", errors.New("invalid ssh path; expected owner/repo.git")
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(raw, prefix), "/"), "/")
+		if len(parts) != 2 {
+			return RepoRef{}, errors.New("invalid ssh path; expected owner/repo.git")
 		}
-		owner := parts[0]
-		repo := strings.TrimSuffix(parts[1], ".git")
-		if owner == "" || repo == "" {            
-			return "", "", errors.New("invalid ssh path segments")
-		}
-		return owner, repo, nil
+		return repoFromSegments(parts)
+	}
+
+	// «github.com/o/r» без схемы — частый копипаст
+	if strings.HasPrefix(strings.ToLower(raw), "github.com/") || strings.HasPrefix(strings.ToLower(raw), "www.github.com/") {
+		raw = "https://" + raw
 	}
-	
 	u, err := url.Parse(raw)
+	if err != nil {
+		return RepoRef{}, errors.New("invalid url")
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https", "http", "ssh", "git", "git+ssh":
+	default:
+		return RepoRef{}, errors.New("unsupported url scheme")
+	}
+	if host := strings.ToLower(u.Hostname()); host != "github.com" && host != "www.github.com" {
+		return RepoRef{}, errors.New("host must be github.com")
+	}
 
-	if err != nil {          
-		return "", "", errors.New("invalid url")
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return RepoRef{}, errors.New("expected path owner/repo")
 	}
-	
-	if !strings.EqualFold(u.Host,"github.com"){
-		return "", "", errors.New("host must be github.com")
+	if len(parts) > 2 && !strings.HasPrefix(strings.ToLower(u.Scheme), "http") {
+		return RepoRef{}, errors.New("expected two path segments: owner/repo")
 	}
+	return repoFromSegments(parts)
+}
 
-		path := strings.TrimPrefix(u.Path, "/") 
-	parts := strings.Split(path, "/")       
-	if len(parts) != 2 {                    
-		return "", "", errors.New("expected two path segments: owner/repo")
+// repoFromSegments — owner/repo[/tree|blob|commit|pull/...].
+func repoFromSegments(parts []string) (RepoRef, error) {
+	r := RepoRef{Owner: parts[0], Repo: strings.TrimSuffix(parts[1], ".git")}
+	if r.Owner == "" || r.Repo == "" {
+		return RepoRef{}, errors.New("owner or repo missing")
 	}
+	rest := parts[2:]
+	if len(rest) == 0 {
+		return r, nil
+	}
+	switch rest[0] {
+	case "tree", "blob":
+		if len(rest) < 2 || rest[1] == "" {
+			return RepoRef{}, errors.New("ref missing after " + rest[0])
+		}
+		r.RefPath = strings.Join(rest[1:], "/")
+		r.Ref, r.Path, _ = strings.Cut(r.RefPath, "/")
+	case "commit":
+		if len(rest) != 2 || rest[1] == "" {
+			return RepoRef{}, errors.New("expected commit/<sha>")
+		}
+		r.Ref = rest[1]
+	case "pull":
+		if len(rest) < 2 {
+			return RepoRef{}, errors.New("expected pull/<number>")
+		}
+		n, err := strconv.Atoi(rest[1])
+		if err != nil || n <= 0 {
+			return RepoRef{}, errors.New("expected pull/<number>")
+		}
+		r.PR = n
+	default:
+		return RepoRef{}, errors.New("unsupported GitHub URL path: " + rest[0])
+	}
+	return r, nil
+}
 
-	owner := parts[0]                                 
-	repo := strings.TrimSuffix(parts[1], ".git")      
-	if owner == "" || repo == "" {                     
-		return "", "", errors.New("owner or repo missing")
+// SplitRefPath — делит «<ref>/<path>» по известным именам веток/тегов:
+// берём самое длинное имя, совпадающее с началом по границе сегмента.
+// Если ничего не подошло — ref это первый сегмент (например, SHA).
+func SplitRefPath(refPath string, refs []string) (string, string) {
+	best := ""
+	for _, name := range refs {
+		if len(name) > len(best) && (refPath == name || strings.HasPrefix(refPath, name+"/")) {
+			best = name
+		}
+	}
+	if best == "" {
+		ref, path, _ := strings.Cut(refPath, "/")
+		return ref, path
 	}
-	return owner, repo, nil
-}
\ No newline at end of file
+	return best, strings.TrimPrefix(strings.TrimPrefix(refPath, best), "/")
+}
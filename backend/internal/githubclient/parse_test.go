@@ -0,0 +1,49 @@
+package githubclient
+
+import "testing"
+
+func TestParseRepoURL(t *testing.T) {
+	cases := []struct {
+		in   string
+		want RepoRef
+	}{
+		{"https://github.com/o/r", RepoRef{Owner: "o", Repo: "r"}},
+		{"github.com/o/r.git/", RepoRef{Owner: "o", Repo: "r"}},
+		{"git@github.com:o/r.git", RepoRef{Owner: "o", Repo: "r"}},
+		{"ssh://git@github.com/o/r.git", RepoRef{Owner: "o", Repo: "r"}},
+		{"git://github.com/o/r.git", RepoRef{Owner: "o", Repo: "r"}},
+		{"https://github.com/o/r/tree/main", RepoRef{Owner: "o", Repo: "r", Ref: "main", RefPath: "main"}},
+		{"https://github.com/o/r/tree/release/1.2/services/api", RepoRef{Owner: "o", Repo: "r", Ref: "release", Path: "1.2/services/api", RefPath: "release/1.2/services/api"}},
+		{"https://github.com/o/r/blob/v1/README.md#L3", RepoRef{Owner: "o", Repo: "r", Ref: "v1", Path: "README.md", RefPath: "v1/README.md"}},
+		{"https://github.com/o/r/commit/abc123", RepoRef{Owner: "o", Repo: "r", Ref: "abc123"}},
+		{"https://github.com/o/r/pull/42/files", RepoRef{Owner: "o", Repo: "r", PR: 42}},
+	}
+	for _, c := range cases {
+		got, err := ParseRepoURL(c.in)
+		if err != nil || got != c.want {
+			t.Errorf("%s: got %+v err=%v, want %+v", c.in, got, err, c.want)
+		}
+	}
+
+	for _, bad := range []string{"", "https://gitlab.com/o/r", "https://github.com/o", "https://github.com/o/r/issues/1", "https://github.com/o/r/pull/x", "ssh://git@github.com/o/r/tree/main"} {
+		if _, err := ParseRepoURL(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSplitRefPath(t *testing.T) {
+	refs := []string{"release", "release/1.2", "main"}
+	cases := []struct{ in, ref, path string }{
+		{"release/1.2/services/api", "release/1.2", "services/api"},
+		{"release/2.0/x", "release", "2.0/x"},
+		{"release/1.2", "release/1.2", ""},
+		{"deadbeef/src", "deadbeef", "src"},
+	}
+	for _, c := range cases {
+		ref, path := SplitRefPath(c.in, refs)
+		if ref != c.ref || path != c.path {
+			t.Errorf("%s: got %q %q, want %q %q", c.in, ref, path, c.ref, c.path)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package githubclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Лимиты постраничной выгрузки PR: GitHub отдаёт не больше 3000 файлов PR.
+const (
+	perPage          = 100
+	maxPullFilePages = 30
+	maxCommentPages  = 10
+)
+
+// PullRequest — метаданные PR из /repos/{owner}/{repo}/pulls/{n}.
+type PullRequest struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	State        string     `json:"state"` // open|closed
+	Draft        bool       `json:"draft"`
+	Merged       bool       `json:"merged"`
+	HTMLURL      string     `json:"html_url"`
+	User         PullUser   `json:"user"`
+	Base         PullBranch `json:"base"`
+	Head         PullBranch `json:"head"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	ChangedFiles int        `json:"changed_files"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type PullUser struct {
+	Login string `json:"login"`
+}
+
+// PullBranch — base или head PR. Label — "owner:branch" (у форков owner другой).
+type PullBranch struct {
+	Label string `json:"label"`
+	Ref   string `json:"ref"`
+	SHA   string `json:"sha"`
+}
+
+// PullFile — файл PR из /pulls/{n}/files. Patch пуст у бинарных и слишком больших диффов.
+type PullFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"` // для renamed
+	Status           string `json:"status"`                      // added|removed|modified|renamed|copied|changed|unchanged
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Patch            string `json:"patch,omitempty"`
+}
+
+// Виды PullComment.
+const (
+	CommentConversation = "comment" // обсуждение PR (issue comments)
+	CommentReview       = "review"  // итог ревью: approve / request changes / comment
+	CommentInline       = "inline"  // комментарий к строке диффа
+)
+
+// PullComment — комментарий к PR любого вида, см. Comment*.
+type PullComment struct {
+	Kind      string    `json:"kind"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	State     string    `json:"state,omitempty"` // у review: APPROVED|CHANGES_REQUESTED|COMMENTED
+	Path      string    `json:"path,omitempty"`  // у inline
+	Line      int       `json:"line,omitempty"`  // у inline; 0 — комментарий к устаревшему диффу
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetPullRequest — метаданные PR.
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (PullRequest, error) {
+	var pr PullRequest
+	_, err := c.GetJSON(ctx, fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, number), &pr)
+	return pr, err
+}
+
+// GetPullFiles — изменённые файлы PR с патчами (до 3000, как и отдаёт GitHub).
+func (c *Client) GetPullFiles(ctx context.Context, owner, repo string, number int) ([]PullFile, error) {
+	return getPages[PullFile](ctx, c, fmt.Sprintf("/repos/%s/%s/pulls/%d/files", owner, repo, number), maxPullFilePages)
+}
+
+// GetPullComments — обсуждение, итоги ревью и комментарии к строкам, по времени.
+// Пустые итоги ревью (approve без текста) пропускаем.
+func (c *Client) GetPullComments(ctx context.Context, owner, repo string, number int) ([]PullComment, error) {
+	type rawComment struct {
+		User      PullUser  `json:"user"`
+		Body      string    `json:"body"`
+		State     string    `json:"state"`
+		Path      string    `json:"path"`
+		Line      int       `json:"line"`
+		CreatedAt time.Time `json:"created_at"`
+		Submitted time.Time `json:"submitted_at"`
+	}
+	var out []PullComment
+	sources := []struct {
+		kind, path string
+	}{
+		{CommentConversation, fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, number)},
+		{CommentReview, fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, number)},
+		{CommentInline, fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", owner, repo, number)},
+	}
+	for _, src := range sources {
+		raw, err := getPages[rawComment](ctx, c, src.path, maxCommentPages)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range raw {
+			if src.kind == CommentReview {
+				if strings.TrimSpace(r.Body) == "" {
+					continue
+				}
+				r.CreatedAt = r.Submitted
+			}
+			out = append(out, PullComment{
+				Kind:      src.kind,
+				Author:    r.User.Login,
+				Body:      r.Body,
+				State:     r.State,
+				Path:      r.Path,
+				Line:      r.Line,
+				CreatedAt: r.CreatedAt,
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// getPages — все страницы списка (per_page=100), пока страница полная, но не больше maxPages.
+func getPages[T any](ctx context.Context, c *Client, path string, maxPages int) ([]T, error) {
+	var all []T
+	for page := 1; page <= maxPages; page++ {
+		var items []T
+		if _, err := c.GetJSON(ctx, fmt.Sprintf("%s?per_page=%d&page=%d", path, perPage, page), &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,143 @@
+package githubclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Параметры повторов по умолчанию (нулевые поля RetryDoer).
+const (
+	DefaultMaxRetries  = 3
+	DefaultRetryBudget = 30 * time.Second
+	defaultBaseDelay   = 500 * time.Millisecond
+	defaultMaxDelay    = 10 * time.Second
+	// secondaryWait — сколько ждать вторичный лимит без Retry-After (рекомендация GitHub);
+	// это больше DefaultRetryBudget, поэтому такой ответ не повторяем, а отдаём
+	// SecondaryRateLimitError с RetryAfter = secondaryWait — повторит вызывающий
+	secondaryWait = time.Minute
+)
+
+// RetryDoer — HTTPDoer-обёртка с повторами:
+//   - сетевые ошибки и 5xx — экспоненциальная задержка с джиттером;
+//   - вторичный лимит (429/403 с Retry-After) — ждём сколько сказал GitHub;
+//     без Retry-After не повторяем: ждать надо минуту, это дольше бюджета;
+//   - основной лимит (Remaining: 0) не повторяем — это дело PoolDoer.
+//
+// Повторяются только GET/HEAD. Суммарное ожидание на запрос ограничено Budget,
+// и мы не засыпаем дольше дедлайна контекста — тогда сразу отдаём последний ответ.
+type RetryDoer struct {
+	Next       HTTPDoer
+	MaxRetries int           // повторов сверх первой попытки; <0 — без повторов
+	BaseDelay  time.Duration // первая задержка backoff
+	MaxDelay   time.Duration // потолок одной задержки backoff
+	Budget     time.Duration // суммарное ожидание на один запрос
+}
+
+func (d *RetryDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.MaxRetries < 0 || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return d.Next.Do(req)
+	}
+	ctx := req.Context()
+	maxRetries := d.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	budget := d.Budget
+	if budget <= 0 {
+		budget = DefaultRetryBudget
+	}
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+		}
+		res, err := d.Next.Do(r)
+
+		delay, ok := d.retryDelay(ctx, res, err, attempt)
+		if !ok || attempt >= maxRetries || waited+delay > budget || !fitsDeadline(ctx, delay) {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+	}
+}
+
+// retryDelay — стоит ли повторять и через сколько.
+func (d *RetryDoer) retryDelay(ctx context.Context, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		// отмена/дедлайн — не сетевой сбой, повторять бессмысленно
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		return d.backoff(attempt), true
+	}
+	switch {
+	case isRateLimited(res):
+		return 0, false
+	case res.StatusCode == http.StatusTooManyRequests ||
+		(res.StatusCode == http.StatusForbidden && res.Header.Get("Retry-After") != ""):
+		if ra := retryAfter(res.Header); ra > 0 {
+			return ra, true
+		}
+		if res.StatusCode == http.StatusTooManyRequests {
+			return 0, false // 429 без Retry-After — см. secondaryWait
+		}
+		return d.backoff(attempt), true
+	case res.StatusCode == http.StatusBadGateway || res.StatusCode == http.StatusServiceUnavailable ||
+		res.StatusCode == http.StatusGatewayTimeout || res.StatusCode == http.StatusInternalServerError:
+		if ra := retryAfter(res.Header); ra > 0 {
+			return ra, true
+		}
+		return d.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff — base*2^attempt (не больше MaxDelay) с «равным» джиттером: [d/2, d).
+func (d *RetryDoer) backoff(attempt int) time.Duration {
+	base, maxDelay := d.BaseDelay, d.MaxDelay
+	if base <= 0 {
+		base = defaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// fitsDeadline — успеем ли подождать delay и ещё сделать запрос до дедлайна ctx.
+func fitsDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
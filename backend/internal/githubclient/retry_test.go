@@ -0,0 +1,118 @@
+package githubclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDoer_Retries5xxThenSucceeds(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer srv.Close()
+
+	d := &RetryDoer{Next: srv.Client(), BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	c := &Client{BaseURL: srv.URL, Doer: d}
+	br, err := c.GetDefaultBranch(context.Background(), "o", "r")
+	if err != nil || br != "main" {
+		t.Fatalf("branch=%q err=%v", br, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls=%d, want 3", calls.Load())
+	}
+}
+
+func TestRetryDoer_SecondaryLimitRespectsBudget(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+	}))
+	defer srv.Close()
+
+	// Retry-After больше бюджета — не ждём, сразу отдаём типизированную ошибку
+	c := &Client{BaseURL: srv.URL, Doer: &RetryDoer{Next: srv.Client(), Budget: time.Second}}
+	_, err := c.GetDefaultBranch(context.Background(), "o", "r")
+	var sl *SecondaryRateLimitError
+	if !errors.As(err, &sl) || sl.RetryAfter != time.Minute {
+		t.Fatalf("err=%v, want SecondaryRateLimitError with 1m", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls=%d, want 1", calls.Load())
+	}
+}
+
+func TestRetryDoer_StopsAtContextDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	d := &RetryDoer{Next: srv.Client(), BaseDelay: time.Second}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	start := time.Now()
+	res, err := d.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status=%d calls=%d, want 503 and 1", res.StatusCode, calls.Load())
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Errorf("slept past deadline: %s", time.Since(start))
+	}
+}
+
+func TestStatusError_Forbidden(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Resource protected by organization SAML enforcement."}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Doer: &RetryDoer{Next: srv.Client()}}
+	_, err := c.GetDefaultBranch(context.Background(), "o", "r")
+	var fe *ForbiddenError
+	if !errors.As(err, &fe) || fe.Message != "Resource protected by organization SAML enforcement." {
+		t.Fatalf("err=%v, want ForbiddenError with message", err)
+	}
+}
+
+func TestRetryDoer_SecondaryLimitWithoutRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+	}))
+	defer srv.Close()
+
+	// ждать надо минуту — больше бюджета по умолчанию: не спим, а сразу отдаём ошибку
+	c := &Client{BaseURL: srv.URL, Doer: &RetryDoer{Next: srv.Client()}}
+	start := time.Now()
+	_, err := c.GetDefaultBranch(context.Background(), "o", "r")
+	var sl *SecondaryRateLimitError
+	if !errors.As(err, &sl) || sl.RetryAfter != time.Minute {
+		t.Fatalf("err=%v, want SecondaryRateLimitError with 1m", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > time.Second {
+		t.Errorf("calls=%d after %s, want 1 and no waiting", calls.Load(), time.Since(start))
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	_"strconv"
-	"strings"
 	"time"
 )
 
@@ -48,6 +47,7 @@ func (c *Client) GetTarball(ctx context.Context, owner, repo, ref string) (io.Re
         return nil, err
     }
     req.Header.Set("Accept", "application/vnd.github+json")
+    req.Header.Set("Cache-Control", "no-store") // архив — поток, HTTP-кэшу он не нужен
     if c.Token != "" {
         req.Header.Set("Authorization", "Bearer "+c.Token)
     }
@@ -58,32 +58,14 @@ func (c *Client) GetTarball(ctx context.Context, owner, repo, ref string) (io.Re
         return nil, err
     }
 
-    // rate limit
-    if (res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusTooManyRequests) &&
-        strings.TrimSpace(res.Header.Get("X-RateLimit-Remaining")) == "0" {
-        var reset int64
-        fmt.Sscanf(res.Header.Get("X-RateLimit-Reset"), "%d", &reset)
-        io.Copy(io.Discard, res.Body)
-        res.Body.Close()
-        cancel()
-        return nil, &RateLimitedError{Reset: reset}
-    }
-
     if res.StatusCode >= 200 && res.StatusCode < 300 {
         // Возвращаем тело, связанное с cancel — он вызовется на Close()
         return &bodyWithCancel{ReadCloser: res.Body, cancel: cancel}, nil
     }
 
     // Ошибочные коды
-    io.Copy(io.Discard, res.Body)
+    err = statusError(res)
     res.Body.Close()
     cancel()
-    switch {
-    case res.StatusCode == http.StatusNotFound:
-        return nil, ErrNotFound
-    case res.StatusCode >= 500:
-        return nil, ErrUpstream
-    default:
-        return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
-    }
+    return nil, err
 }
@@ -0,0 +1,261 @@
+package githubclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenPool — набор серверных токенов GitHub. По заголовкам X-RateLimit-*
+// каждого ответа ведёт остаток квоты и для нового запроса берёт токен
+// с наибольшим остатком; исчерпанные (<= reserve) ждут своего Reset.
+type TokenPool struct {
+	mu      sync.Mutex
+	reserve int
+	tokens  []*tokenState
+	byToken map[string]*tokenState
+}
+
+type tokenState struct {
+	token     string
+	id        string // безопасный идентификатор для API/метрик
+	known     bool   // видели ли заголовки лимита
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// TokenQuota — состояние токена для /api/github/ratelimit.
+type TokenQuota struct {
+	ID        string    `json:"id"`
+	Known     bool      `json:"known"` // false — ответов с этим токеном ещё не было
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	Exhausted bool      `json:"exhausted"` // ушёл в резерв, до Reset не используем
+}
+
+// NewTokenPool — reserve: сколько запросов оставлять у токена про запас (ниже
+// порога токен не выбираем до Reset). Пустые и повторяющиеся токены
+// отбрасываются; nil, если токенов нет.
+func NewTokenPool(tokens []string, reserve int) *TokenPool {
+	if reserve < 0 {
+		reserve = 0
+	}
+	p := &TokenPool{reserve: reserve, byToken: map[string]*tokenState{}}
+	for _, t := range tokens {
+		t = strings.TrimSpace(t)
+		if t == "" || p.byToken[t] != nil {
+			continue
+		}
+		st := &tokenState{token: t, id: tokenID(t)}
+		p.tokens = append(p.tokens, st)
+		p.byToken[t] = st
+	}
+	if len(p.tokens) == 0 {
+		return nil
+	}
+	return p
+}
+
+// tokenID — «…abcd»: хватает, чтобы отличить токены, и ничего не раскрывает.
+func tokenID(t string) string {
+	if len(t) <= 4 {
+		return "…"
+	}
+	return "…" + t[len(t)-4:]
+}
+
+// Pick — самый «здоровый» токен. Неизвестные считаем полными. Остаток уменьшаем
+// сразу — параллельные запросы расходятся по разным токенам. Если все в резерве —
+// *RateLimitedError с ближайшим Reset.
+func (p *TokenPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var best *tokenState
+	var earliest time.Time
+	for _, st := range p.tokens {
+		if st.known && !st.reset.IsZero() && now.After(st.reset) {
+			st.known = false // окно сбросилось — до нового ответа считаем полным
+		}
+		if st.known && st.remaining <= p.reserve {
+			if earliest.IsZero() || st.reset.Before(earliest) {
+				earliest = st.reset
+			}
+			continue
+		}
+		if best == nil || st.score() > best.score() {
+			best = st
+		}
+	}
+	if best == nil {
+		return "", &RateLimitedError{Reset: earliest.Unix()}
+	}
+	if best.known {
+		best.remaining--
+	}
+	return best.token, nil
+}
+
+func (st *tokenState) score() int {
+	if !st.known {
+		return 1 << 30
+	}
+	return st.remaining
+}
+
+// Observe — обновить квоту токена по заголовкам ответа. Чужие токены игнорируем.
+func (p *TokenPool) Observe(token string, h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.byToken[token]
+	if st == nil {
+		return
+	}
+	st.known = true
+	st.remaining = remaining
+	st.limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		st.reset = time.Unix(reset, 0)
+	}
+}
+
+// Snapshot — квоты всех токенов (по убыванию остатка).
+func (p *TokenPool) Snapshot() []TokenQuota {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]TokenQuota, 0, len(p.tokens))
+	for _, st := range p.tokens {
+		out = append(out, TokenQuota{
+			ID:        st.id,
+			Known:     st.known,
+			Limit:     st.limit,
+			Remaining: st.remaining,
+			Reset:     st.reset,
+			Exhausted: st.known && st.remaining <= p.reserve && time.Now().Before(st.reset),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Remaining > out[j].Remaining })
+	return out
+}
+
+// RateLimits — квоты токенов пула. probe=true — токены без данных сначала
+// опрашиваем через /rate_limit (этот запрос лимит не расходует).
+func (c *Client) RateLimits(ctx context.Context, probe bool) []TokenQuota {
+	if c.Pool == nil {
+		return nil
+	}
+	if probe {
+		for _, tok := range c.Pool.unknown() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/rate_limit", nil)
+			if err != nil {
+				break
+			}
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("Cache-Control", "no-store")
+			req.Header.Set("Authorization", "Bearer "+tok) // PoolDoer учтёт квоту по заголовкам
+			res, err := c.Doer.Do(req)
+			if err != nil {
+				continue
+			}
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+	}
+	return c.Pool.Snapshot()
+}
+
+// unknown — токены, по которым ещё не было ответов.
+func (p *TokenPool) unknown() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []string
+	for _, st := range p.tokens {
+		if !st.known {
+			out = append(out, st.token)
+		}
+	}
+	return out
+}
+
+// WriteMetrics — квоты в текстовом формате Prometheus.
+func (p *TokenPool) WriteMetrics(w io.Writer) {
+	snap := p.Snapshot()
+	fmt.Fprintln(w, "# HELP github_ratelimit_remaining Remaining GitHub API requests per token.")
+	fmt.Fprintln(w, "# TYPE github_ratelimit_remaining gauge")
+	for _, q := range snap {
+		fmt.Fprintf(w, "github_ratelimit_remaining{token=%q} %d\n", q.ID, q.Remaining)
+	}
+	fmt.Fprintln(w, "# HELP github_ratelimit_limit GitHub API request limit per token.")
+	fmt.Fprintln(w, "# TYPE github_ratelimit_limit gauge")
+	for _, q := range snap {
+		fmt.Fprintf(w, "github_ratelimit_limit{token=%q} %d\n", q.ID, q.Limit)
+	}
+	fmt.Fprintln(w, "# HELP github_ratelimit_reset_seconds Unix time when the token quota resets.")
+	fmt.Fprintln(w, "# TYPE github_ratelimit_reset_seconds gauge")
+	for _, q := range snap {
+		var reset int64
+		if !q.Reset.IsZero() {
+			reset = q.Reset.Unix()
+		}
+		fmt.Fprintf(w, "github_ratelimit_reset_seconds{token=%q} %d\n", q.ID, reset)
+	}
+}
+
+// PoolDoer — HTTPDoer-обёртка: подставляет токен из пула и учитывает лимиты.
+// Запросы с уже выставленным Authorization не трогаем (только учитываем квоту,
+// если это токен пула). Если токен упёрся в лимит, GET повторяем с другим.
+type PoolDoer struct {
+	Next HTTPDoer
+	Pool *TokenPool
+}
+
+func (d *PoolDoer) Do(req *http.Request) (*http.Response, error) {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		res, err := d.Next.Do(req)
+		if err == nil {
+			d.Pool.Observe(strings.TrimPrefix(auth, "Bearer "), res.Header)
+		}
+		return res, err
+	}
+
+	attempts := 1
+	if req.Method == http.MethodGet && req.Body == nil {
+		attempts = len(d.Pool.tokens)
+	}
+	for i := 0; ; i++ {
+		token, err := d.Pool.Pick()
+		if err != nil {
+			return nil, err
+		}
+		r := req.Clone(req.Context())
+		r.Header.Set("Authorization", "Bearer "+token)
+		res, err := d.Next.Do(r)
+		if err != nil {
+			return nil, err
+		}
+		d.Pool.Observe(token, res.Header)
+		if i+1 >= attempts || !isRateLimited(res) {
+			return res, nil
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+}
+
+// isRateLimited — основной лимит: 403/429 и X-RateLimit-Remaining: 0.
+func isRateLimited(res *http.Response) bool {
+	return (res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusTooManyRequests) &&
+		strings.TrimSpace(res.Header.Get("X-RateLimit-Remaining")) == "0"
+}
@@ -0,0 +1,67 @@
+package githubclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func rlHeader(remaining int, reset time.Time) http.Header {
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", "5000")
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return h
+}
+
+func TestTokenPool_PicksHealthiestAndKeepsReserve(t *testing.T) {
+	p := NewTokenPool([]string{"aaaa1", "bbbb2", "", "aaaa1"}, 10)
+	reset := time.Now().Add(time.Hour)
+	p.Observe("aaaa1", rlHeader(100, reset))
+	p.Observe("bbbb2", rlHeader(300, reset))
+
+	if tok, _ := p.Pick(); tok != "bbbb2" {
+		t.Errorf("pick = %q, want bbbb2", tok)
+	}
+
+	p.Observe("bbbb2", rlHeader(10, reset)) // в резерве
+	if tok, _ := p.Pick(); tok != "aaaa1" {
+		t.Errorf("pick = %q, want aaaa1", tok)
+	}
+
+	p.Observe("aaaa1", rlHeader(5, reset))
+	_, err := p.Pick()
+	if rl, ok := err.(*RateLimitedError); !ok || rl.Reset != reset.Unix() {
+		t.Errorf("err = %v, want RateLimitedError with reset", err)
+	}
+}
+
+func TestPoolDoer_RotatesOnRateLimit(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Reset", reset)
+		if r.Header.Get("Authorization") == "Bearer empty" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer srv.Close()
+
+	pool := NewTokenPool([]string{"empty", "full"}, 0)
+	c := &Client{BaseURL: srv.URL, Doer: &PoolDoer{Next: srv.Client(), Pool: pool}, Pool: pool}
+	for i := 0; i < 3; i++ {
+		if br, err := c.GetDefaultBranch(context.Background(), "o", "r"); err != nil || br != "main" {
+			t.Fatalf("call %d: %q %v", i, br, err)
+		}
+	}
+	snap := pool.Snapshot()
+	if len(snap) != 2 || snap[0].Remaining != 4000 || !snap[1].Exhausted {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
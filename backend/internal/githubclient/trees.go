@@ -3,11 +3,14 @@ package githubclient // тот же пакет, где client.go и parse.go
 import (
 	"context"   // для передачи дедлайнов/отмены в HTTP
 	"fmt"       // форматирование строк (Sprintf)
+	"io"        // чтение начала blob'а
 	"net/http"  // константы статусов
-	"path"      // безопасная работа с путями в стиле POSIX (Git всегда '/' )
+	"path"      // склейка путей при обходе по каталогам
 	"sort"      // стабильная сортировка срезов
-	"strings"   // проверки суффиксов/регистров
+	"sync"      // параллельный обход каталогов
+	"time"      // дедлайн обхода по каталогам
 
+	"github.com/yourname/cleanhttp/internal/filters" // вид файла по имени (KindByName) и содержимому (IsLFSPointer)
 	// используем уже написанный Client.GetJSON и ошибки ErrNotFound/ErrUpstream/RateLimitedError
 )
 
@@ -17,28 +20,153 @@ type TreeItem struct {
 	Path      string `json:"path"`      // полный относительный путь внутри репо (например, "cmd/api/main.go")
 	Type      string `json:"type"`      // "file" или "dir"
 	Size      int64  `json:"size"`      // для файлов — размер из GitHub; для папок = 0
-	LFS       bool   `json:"lfs"`       // эвристика: потенциально хранится в git-lfs
+	Kind      string `json:"kind"`      // text|binary|image|archive (по имени) или lfs-pointer (по содержимому); у папок пусто
+	LFS       bool   `json:"lfs"`       // устарело: то же, что kind == "lfs-pointer"
 	Submodule bool   `json:"submodule"` // true, если это сабмодуль (в GitHub type=commit)
+	SHA       string `json:"sha,omitempty"` // для сабмодуля — закреплённый коммит (gitlink)
 }
 
-// rawTree — минимальная форма ответа от GitHub на /git/trees/{ref}?recursive=1
-// Нас интересует только массив tree и поле type/size/path у элементов.
+// rawTree — минимальная форма ответа от GitHub на /git/trees/{ref}[?recursive=1]
+// Нас интересует только массив tree и поле type/size/path/sha у элементов.
 type rawTree struct {
-	Tree []struct {
-		Path string  `json:"path"`          // путь в POSIX-формате
-		Type string  `json:"type"`          // "blob"|"tree"|"commit"
-		Size *int64  `json:"size,omitempty"`// у "tree"/"commit" отсутствует (поэтому *int64)
-	} `json:"tree"`
-	Truncated bool `json:"truncated"` // GitHub может урезать большие деревья; на V1 просто знаем об этом
+	Tree      []rawTreeEntry `json:"tree"`
+	Truncated bool           `json:"truncated"` // GitHub урезает рекурсивные деревья (~100k записей / 7 МБ)
 }
 
+type rawTreeEntry struct {
+	Path string  `json:"path"`           // путь в POSIX-формате (в нерекурсивном ответе — только имя)
+	Type string  `json:"type"`           // "blob"|"tree"|"commit"
+	Sha  string  `json:"sha"`            // для "tree" — чтобы спуститься в подкаталог
+	Size *int64  `json:"size,omitempty"` // у "tree"/"commit" отсутствует (поэтому *int64)
+}
+
+// TreeResult — дерево и признак неполноты (если даже обход по каталогам упёрся в лимит).
+type TreeResult struct {
+	Items     []TreeItem
+	Truncated bool
+}
+
+// Обход по каталогам, когда рекурсивное дерево урезано. Обход лишь
+// дополняет урезанный ответ, поэтому ограничен жёстко: лучше частичное
+// дерево с truncated=true, чем сожжённый rate limit или таймаут запроса.
+const (
+	maxTreeWalkRequests = 200 // предел запросов на одно дерево — бережём rate limit
+	treeWalkWorkers     = 8   // параллельных запросов к GitHub
+)
+
+// treeWalkTimeout — свой дедлайн обхода, с запасом внутри таймаута хендлера
+// (15 с): после него ещё нужно время на sniffLFSPointers. var — для тестов.
+var treeWalkTimeout = 8 * time.Second
+
 // GetTree — забирает дерево целиком и нормализует элементы.
 // owner/repo — репозиторий; ref — ветка/хеш/тег (например, "main").
 // Ошибки: ErrNotFound (404), *RateLimitedError (403/429), ErrUpstream (5xx), либо обычная error.
 func (c *Client) GetTree(ctx context.Context, owner, repo, ref string) ([]TreeItem, error) {
+	res, err := c.GetTreeFull(ctx, owner, repo, ref)
+	return res.Items, err
+}
+
+// GetTreeFull — как GetTree, но с признаком Truncated. Если GitHub урезал
+// рекурсивный ответ, дерево собирается обходом нерекурсивных деревьев по каталогам.
+func (c *Client) GetTreeFull(ctx context.Context, owner, repo, ref string) (TreeResult, error) {
+	raw, err := c.getRawTree(ctx, owner, repo, ref, true)
+	if err != nil {
+		return TreeResult{}, err
+	}
+	entries, truncated := raw.Tree, raw.Truncated
+	if truncated {
+		// огромный монорепозиторий: собираем дерево по каталогам
+		walked, walkTruncated, err := c.walkTree(ctx, owner, repo, ref)
+		if err != nil {
+			return TreeResult{}, err
+		}
+		entries, truncated = walked, walkTruncated
+		if truncated {
+			// обход тоже неполный — добираем то, что было в урезанном ответе
+			entries = mergeTreeEntries(walked, raw.Tree)
+		}
+	}
+	return TreeResult{Items: normalizeTree(entries, c.sniffLFSPointers(ctx, owner, repo, entries)), Truncated: truncated}, nil
+}
+
+// Pointer'ы Git LFS по имени не отличить от настоящих файлов: проверяем
+// содержимое кандидатов (бинарное расширение, не больше filters.MaxLFSPointerSize
+// байт), но не больше maxLFSSniff blob'ов на дерево.
+const maxLFSSniff = 64
+
+// sniffLFSPointers — пути blob'ов, которые оказались pointer-файлами Git LFS.
+// Ошибки отдельных запросов не фатальны: такой файл остаётся с видом по имени.
+func (c *Client) sniffLFSPointers(ctx context.Context, owner, repo string, entries []rawTreeEntry) map[string]bool {
+	var cands []rawTreeEntry
+	for _, e := range entries {
+		if e.Type != "blob" || e.Size == nil || *e.Size > filters.MaxLFSPointerSize {
+			continue
+		}
+		if filters.KindByName(e.Path) == filters.KindText {
+			continue
+		}
+		cands = append(cands, e)
+		if len(cands) == maxLFSSniff {
+			break
+		}
+	}
+	if len(cands) == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	found := map[string]bool{}
+	sem := make(chan struct{}, treeWalkWorkers)
+	var wg sync.WaitGroup
+	for _, e := range cands {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e rawTreeEntry) {
+			defer func() { <-sem; wg.Done() }()
+			head, err := c.getBlobHead(ctx, owner, repo, e.Sha, filters.MaxLFSPointerSize)
+			if err != nil || !filters.IsLFSPointer(head) {
+				return
+			}
+			mu.Lock()
+			found[e.Path] = true
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+	return found
+}
+
+// getBlobHead — первые max байт blob'а по SHA (git/blobs, сырой формат):
+// содержимое как в git, т.е. для LFS — сам pointer, а не объект.
+func (c *Client) getBlobHead(ctx context.Context, owner, repo, sha string, max int64) ([]byte, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/git/blobs/%s", c.BaseURL, owner, repo, sha)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	res, err := c.Doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, statusError(res)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, max))
+}
+
+// getRawTree — один запрос /git/trees/{sha-или-ref}.
+func (c *Client) getRawTree(ctx context.Context, owner, repo, ref string, recursive bool) (rawTree, error) {
 	// Строим путь GitHub API:
 	// /repos/{owner}/{repo}/git/trees/{ref}?recursive=1 — рекурсивное дерево
-	p := fmt.Sprintf("/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, ref)
+	p := fmt.Sprintf("/repos/%s/%s/git/trees/%s", owner, repo, ref)
+	if recursive {
+		p += "?recursive=1"
+	}
 
 	// Куда декодировать «сырой» JSON от GitHub.
 	var raw rawTree
@@ -46,39 +174,135 @@ func (c *Client) GetTree(ctx context.Context, owner, repo, ref string) ([]TreeIt
 	// Выполняем запрос через наш helper (он уже умеет обрабатывать rate-limit и базовые коды)
 	status, err := c.GetJSON(ctx, p, &raw)
 	if err != nil {
-		// Прозрачно пробрасываем типизированные ошибки наверх
-		if _, ok := err.(*RateLimitedError); ok {
-			return nil, err
-		}
-		if err == ErrNotFound || err == ErrUpstream {
-			return nil, err
-		}
-		// Прочее — обычная ошибка сети/декодера
-		return nil, err
+		// Типизированные ошибки (RateLimitedError, ErrNotFound, ErrUpstream) пробрасываем как есть
+		return rawTree{}, err
 	}
 	// Дополнительная страховка по статусу (в норме сюда приходят только 2xx)
 	if status == http.StatusNotFound {
-		return nil, ErrNotFound
+		return rawTree{}, ErrNotFound
 	}
 	if status >= 500 {
-		return nil, ErrUpstream
+		return rawTree{}, ErrUpstream
 	}
+	return raw, nil
+}
 
+// walkTree — обход нерекурсивных деревьев в ширину, уровень за уровнем,
+// до treeWalkWorkers запросов параллельно. truncated=true, если упёрлись в
+// maxTreeWalkRequests или treeWalkTimeout, либо GitHub урезал отдельный
+// каталог; собранное к этому моменту возвращается как есть.
+func (c *Client) walkTree(ctx context.Context, owner, repo, ref string) ([]rawTreeEntry, bool, error) {
+	wctx, cancel := context.WithTimeout(ctx, treeWalkTimeout)
+	defer cancel()
+	// дедлайн обхода (но не вызывающего) — не ошибка, а частичное дерево
+	walkExpired := func() bool { return wctx.Err() != nil && ctx.Err() == nil }
+
+	type dirJob struct{ prefix, sha string }
+	var (
+		mu        sync.Mutex
+		out       []rawTreeEntry
+		next      []dirJob
+		firstErr  error
+		truncated bool
+		requests  int
+	)
+	level := []dirJob{{prefix: "", sha: ref}}
+	for len(level) > 0 {
+		if left := maxTreeWalkRequests - requests; len(level) > left {
+			level, truncated = level[:left], true
+		}
+		requests += len(level)
+
+		sem := make(chan struct{}, treeWalkWorkers)
+		var wg sync.WaitGroup
+		for _, j := range level {
+			if walkExpired() {
+				break
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(j dirJob) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				raw, err := c.getRawTree(wctx, owner, repo, j.sha, false)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if walkExpired() {
+						truncated = true
+						return
+					}
+					if firstErr == nil {
+						firstErr = err
+					}
+					return
+				}
+				if raw.Truncated {
+					truncated = true
+				}
+				for _, e := range raw.Tree {
+					e.Path = path.Join(j.prefix, e.Path)
+					out = append(out, e)
+					if e.Type == "tree" {
+						next = append(next, dirJob{prefix: e.Path, sha: e.Sha})
+					}
+				}
+			}(j)
+		}
+		wg.Wait()
+		if firstErr != nil {
+			return nil, false, firstErr
+		}
+		if walkExpired() {
+			return out, true, nil
+		}
+		level, next = next, nil
+		if requests >= maxTreeWalkRequests && len(level) > 0 {
+			truncated = true
+			break
+		}
+	}
+	return out, truncated, nil
+}
+
+// mergeTreeEntries — a плюс элементы b с путями, которых в a нет.
+func mergeTreeEntries(a, b []rawTreeEntry) []rawTreeEntry {
+	seen := make(map[string]bool, len(a))
+	for _, e := range a {
+		seen[e.Path] = true
+	}
+	for _, e := range b {
+		if !seen[e.Path] {
+			a = append(a, e)
+		}
+	}
+	return a
+}
+
+// normalizeTree — «сырые» элементы GitHub → TreeItem, отсортированные для UI.
+// lfs — пути pointer-файлов Git LFS (см. sniffLFSPointers).
+func normalizeTree(entries []rawTreeEntry, lfs map[string]bool) []TreeItem {
 	// Преобразуем «сырые» элементы в наши TreeItem.
-	items := make([]TreeItem, 0, len(raw.Tree)) // ёмкость заранее — чуть экономим аллокации
+	items := make([]TreeItem, 0, len(entries)) // ёмкость заранее — чуть экономим аллокации
 
-	for _, t := range raw.Tree {
+	for _, t := range entries {
 		switch t.Type {
 		case "blob": // обычный файл
 			var sz int64
 			if t.Size != nil { // у blob size присутствует
 				sz = *t.Size
 			}
+			// вид по расширению; pointer LFS — только если проверили содержимое
+			kind := filters.KindByName(t.Path)
+			if lfs[t.Path] {
+				kind = filters.KindLFSPointer
+			}
 			items = append(items, TreeItem{
 				Path:      t.Path,
 				Type:      "file",
 				Size:      sz,
-				LFS:       looksLikeLFS(t.Path, sz), // простая эвристика
+				Kind:      kind,
+				LFS:       kind == filters.KindLFSPointer,
 				Submodule: false,
 			})
 		case "tree": // каталог
@@ -96,6 +320,7 @@ func (c *Client) GetTree(ctx context.Context, owner, repo, ref string) ([]TreeIt
 				Size:      0,
 				LFS:       false,
 				Submodule: true,   // но отмечаем, что это сабмодуль
+				SHA:       t.Sha,
 			})
 		default:
 			// Неизвестный тип — просто пропускаем (на практике редко встречается).
@@ -114,26 +339,5 @@ func (c *Client) GetTree(ctx context.Context, owner, repo, ref string) ([]TreeIt
 		return items[i].Path < items[j].Path // простой лексикографический порядок
 	})
 
-	return items, nil
-}
-
-// looksLikeLFS — эвристика: считаем файл «lfs-подобным», если он большой
-// или имеет характерное бинарное расширение.
-func looksLikeLFS(pth string, size int64) bool {
-	// Порог «большого» файла — 5 MiB. Можно потом вынести в конфиг.
-	const big = 5 * 1024 * 1024
-
-	// Характерные расширения: картинки/архивы/видео/аудио/исполнимые и т.п.
-	ext := strings.ToLower(path.Ext(pth)) // path.Ext — работает с POSIX-путями
-	switch ext {
-	case ".psd", ".zip", ".rar", ".7z", ".gz", ".tar",
-		".bin", ".exe", ".dll",
-		".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp",
-		".mp3", ".wav", ".flac",
-		".mp4", ".mov", ".avi", ".mkv",
-		".pdf", ".iso":
-		return true
-	}
-	// Без «подозрительного» расширения ориентируемся на размер.
-	return size >= big
+	return items
 }
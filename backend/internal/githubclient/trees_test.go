@@ -0,0 +1,177 @@
+package githubclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetTreeFull_WalksWhenTruncated(t *testing.T) {
+	// нерекурсивные деревья по sha
+	trees := map[string][]map[string]any{
+		"main": {
+			{"path": "README.md", "type": "blob", "sha": "b1", "size": 10},
+			{"path": "src", "type": "tree", "sha": "t-src"},
+		},
+		"t-src": {
+			{"path": "main.go", "type": "blob", "sha": "b2", "size": 20},
+			{"path": "pkg", "type": "tree", "sha": "t-pkg"},
+		},
+		"t-pkg": {
+			{"path": "util.go", "type": "blob", "sha": "b3", "size": 30},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sha := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if r.URL.Query().Get("recursive") == "1" {
+			// GitHub урезал ответ
+			_ = json.NewEncoder(w).Encode(map[string]any{"tree": trees["main"][:1], "truncated": true})
+			return
+		}
+		entries, ok := trees[sha]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"tree": entries, "truncated": false})
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Doer: srv.Client()}
+	res, err := c.GetTreeFull(context.Background(), "o", "r", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Truncated {
+		t.Error("walk completed, want Truncated=false")
+	}
+	var got []string
+	for _, it := range res.Items {
+		got = append(got, it.Type+":"+it.Path)
+	}
+	want := "dir:src dir:src/pkg file:README.md file:src/main.go file:src/pkg/util.go"
+	if strings.Join(got, " ") != want {
+		t.Errorf("items = %v, want %s", got, want)
+	}
+}
+
+// Обход по каталогам ограничен числом запросов и своим дедлайном: в обоих
+// случаях — частичное дерево с Truncated, дополненное урезанным ответом.
+func TestGetTreeFull_WalkIsBounded(t *testing.T) {
+	run := func(t *testing.T, dirs int, slow bool) (TreeResult, int64) {
+		var walkRequests atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sha := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			if r.URL.Query().Get("recursive") == "1" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"tree": []map[string]any{
+					{"path": "README.md", "type": "blob", "sha": "b1", "size": 10},
+				}, "truncated": true})
+				return
+			}
+			walkRequests.Add(1)
+			var tree []map[string]any
+			switch {
+			case sha == "main":
+				for i := 0; i < dirs; i++ {
+					tree = append(tree, map[string]any{"path": fmt.Sprintf("d%03d", i), "type": "tree", "sha": fmt.Sprintf("t%03d", i)})
+				}
+				if slow {
+					tree = append(tree, map[string]any{"path": "slow", "type": "tree", "sha": "t-slow"})
+				}
+			case sha == "t-slow":
+				<-r.Context().Done() // GitHub «завис» на этом каталоге
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"tree": tree, "truncated": false})
+		}))
+		defer srv.Close()
+
+		c := &Client{BaseURL: srv.URL, Doer: srv.Client()}
+		res, err := c.GetTreeFull(context.Background(), "o", "r", "main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, walkRequests.Load()
+	}
+	hasPath := func(res TreeResult, p string) bool {
+		for _, it := range res.Items {
+			if it.Path == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("request budget", func(t *testing.T) {
+		res, n := run(t, 3*maxTreeWalkRequests, false)
+		if n > maxTreeWalkRequests {
+			t.Errorf("walk made %d requests, limit %d", n, maxTreeWalkRequests)
+		}
+		if !res.Truncated || !hasPath(res, "README.md") || !hasPath(res, "d000") {
+			t.Errorf("want partial tree with Truncated, got %d items, truncated=%v", len(res.Items), res.Truncated)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		defer func(d time.Duration) { treeWalkTimeout = d }(treeWalkTimeout)
+		treeWalkTimeout = 200 * time.Millisecond
+		start := time.Now()
+		res, _ := run(t, 3, true)
+		if time.Since(start) > 5*time.Second {
+			t.Errorf("walk ignored its deadline: %v", time.Since(start))
+		}
+		if !res.Truncated || !hasPath(res, "README.md") || !hasPath(res, "d000") {
+			t.Errorf("want partial tree with Truncated, got %d items, truncated=%v", len(res.Items), res.Truncated)
+		}
+	})
+}
+
+func TestGetTreeFull_SniffsLFSPointers(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+	blobs := map[string]string{
+		"b-ptr":  pointer,
+		"b-icon": "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 120), // настоящая маленькая картинка
+	}
+	size := func(n int) *int { return &n }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/git/blobs/") {
+			body, ok := blobs[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"tree": []map[string]any{
+			{"path": "assets/logo.png", "type": "blob", "sha": "b-ptr", "size": size(len(pointer))},
+			{"path": "assets/icon.png", "type": "blob", "sha": "b-icon", "size": size(len(blobs["b-icon"]))},
+			{"path": "assets/big.png", "type": "blob", "sha": "b-big", "size": size(40_000)},
+			{"path": "notes.txt", "type": "blob", "sha": "b-txt", "size": size(140)},
+		}})
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Doer: srv.Client()}
+	res, err := c.GetTreeFull(context.Background(), "o", "r", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"assets/logo.png": "lfs-pointer",
+		"assets/icon.png": "image",
+		"assets/big.png":  "image",
+		"notes.txt":       "text",
+	}
+	for _, it := range res.Items {
+		if it.Kind != want[it.Path] || it.LFS != (want[it.Path] == "lfs-pointer") {
+			t.Errorf("%s: kind=%q lfs=%v, want %q", it.Path, it.Kind, it.LFS, want[it.Path])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package githubclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+type userTokenKey struct{}
+
+// WithToken — токен пользователя (OAuth) для всех запросов к GitHub в ctx.
+// Он важнее серверных: GitHub App и пул PAT такие запросы не трогают.
+func WithToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, userTokenKey{}, token)
+}
+
+// TokenFromContext — токен из WithToken ("" — запрос от имени сервера).
+func TokenFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(userTokenKey{}).(string)
+	return t
+}
+
+// TokenScope — область видимости для ключей общих кэшей: "" у серверных запросов,
+// иначе короткий хэш токена — чтобы приватное дерево одного пользователя
+// не досталось другому.
+func TokenScope(ctx context.Context) string {
+	t := TokenFromContext(ctx)
+	if t == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(t))
+	return "u" + hex.EncodeToString(sum[:8])
+}
+
+// ContextTokenDoer — подставляет токен из WithToken. Стоит снаружи HTTP-кэша,
+// чтобы токен попал в ключ кэша.
+type ContextTokenDoer struct {
+	Next HTTPDoer
+}
+
+func (d *ContextTokenDoer) Do(req *http.Request) (*http.Response, error) {
+	if t := TokenFromContext(req.Context()); t != "" && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t)
+	}
+	return d.Next.Do(req)
+}
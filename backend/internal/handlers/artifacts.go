@@ -9,11 +9,14 @@ import (
 
 	"github.com/yourname/cleanhttp/internal/artifacts"
 	"github.com/yourname/cleanhttp/internal/httputil"
+	"github.com/yourname/cleanhttp/internal/store"
 )
 
 // ArtifactsListHandler — GET /api/artifacts/:exportId → список файлов + expiresAt.
+// Чужие экспорты (другого пользователя) — 404, как и несуществующие.
 type ArtifactsListHandler struct {
-	Store artifacts.ArtifactsStore
+	Store   artifacts.ArtifactsStore
+	Exports store.ExportsStore
 }
 
 func lastPathSegment(p string) string {
@@ -39,6 +42,11 @@ func (h *ArtifactsListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, ok := ownedExport(r, h.Exports, exportID); !ok {
+		httputil.WriteError(w, http.StatusNotFound, "not_found", "export not found", nil)
+		return
+	}
+
 	files, expAt, err := h.Store.ListByExportID(exportID)
 	if err != nil {
 		if errors.Is(err, artifacts.ErrNotFound) {
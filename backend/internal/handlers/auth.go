@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/auth"
+	"github.com/yourname/cleanhttp/internal/httputil"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+// AuthHandler — вход через GitHub OAuth:
+//
+//	GET  /api/auth/github/login     — редирект на github.com за согласием
+//	GET  /api/auth/github/callback  — обмен code на токен, сессия, редирект в UI
+//	GET  /api/auth/me               — текущий пользователь (401 — не вошёл)
+//	POST /api/auth/logout           — выйти
+type AuthHandler struct {
+	OAuth           *auth.GitHubOAuth // nil — OAuth не настроен
+	Sessions        *auth.Sessions
+	Users           store.UsersStore
+	SuccessRedirect string // куда вернуть браузер после входа
+}
+
+type meResp struct {
+	ID        string `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch p := strings.TrimPrefix(r.URL.Path, "/auth"); {
+	case p == "/github/login" && r.Method == http.MethodGet:
+		h.login(w, r)
+	case p == "/github/callback" && r.Method == http.MethodGet:
+		h.callback(w, r)
+	case p == "/me" && r.Method == http.MethodGet:
+		u, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			httputil.WriteError(w, http.StatusUnauthorized, "unauthorized", "not signed in", nil)
+			return
+		}
+		httputil.WriteJSON(w, http.StatusOK, meResp{ID: u.ID, Login: u.Login, Name: u.Name, Email: u.Email, AvatarURL: u.AvatarURL})
+	case p == "/logout" && r.Method == http.MethodPost:
+		// cookie без состояния: удалить её в браузере мало — повышаем версию,
+		// и все ранее выданные cookie пользователя перестают приниматься
+		if u, ok := auth.UserFromContext(r.Context()); ok {
+			if err := h.Users.RevokeSessions(r.Context(), u.ID); err != nil {
+				httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to revoke session", map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		if h.Sessions != nil {
+			h.Sessions.Clear(w)
+		}
+		httputil.WriteJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		httputil.WriteError(w, http.StatusNotFound, "not_found", "unknown auth route", nil)
+	}
+}
+
+func (h *AuthHandler) login(w http.ResponseWriter, r *http.Request) {
+	if h.OAuth == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "oauth_not_configured", "GitHub OAuth is not configured", nil)
+		return
+	}
+	state, err := auth.NewState()
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to start login", nil)
+		return
+	}
+	h.Sessions.SetState(w, state)
+	http.Redirect(w, r, h.OAuth.AuthCodeURL(state), http.StatusFound)
+}
+
+func (h *AuthHandler) callback(w http.ResponseWriter, r *http.Request) {
+	if h.OAuth == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "oauth_not_configured", "GitHub OAuth is not configured", nil)
+		return
+	}
+	q := r.URL.Query()
+	if !h.Sessions.CheckState(w, r, q.Get("state")) {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_state", "OAuth state mismatch, start login again", nil)
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		// пользователь отказал в доступе
+		httputil.WriteError(w, http.StatusForbidden, "oauth_denied", q.Get("error_description"), map[string]any{"error": e})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	token, scopes, err := h.OAuth.Exchange(ctx, q.Get("code"))
+	if err != nil {
+		slog.Warn("oauth exchange failed", slog.String("error", err.Error()))
+		httputil.WriteError(w, http.StatusBadGateway, "oauth_exchange_failed", "failed to exchange OAuth code", nil)
+		return
+	}
+	gu, err := h.OAuth.FetchUser(ctx, token)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadGateway, "upstream_error", "failed to load GitHub profile", nil)
+		return
+	}
+	enc, err := h.Sessions.Cipher.Seal([]byte(token))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to store token", nil)
+		return
+	}
+	u := store.User{
+		ID:        "gh:" + strconv.FormatInt(gu.ID, 10),
+		GitHubID:  gu.ID,
+		Login:     gu.Login,
+		Name:      gu.Name,
+		Email:     gu.Email,
+		AvatarURL: gu.AvatarURL,
+		TokenEnc:  enc,
+		Scopes:    scopes,
+	}
+	if err := h.Users.PutUser(ctx, u); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to save user", map[string]any{"error": err.Error()})
+		return
+	}
+	// версию сессий хранит база (PutUser её не трогает) — берём актуальную
+	saved, err := h.Users.GetUser(ctx, u.ID)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to load user", map[string]any{"error": err.Error()})
+		return
+	}
+	h.Sessions.Issue(w, u.ID, saved.SessionVersion)
+	slog.Info("user signed in", slog.String("user_id", u.ID), slog.String("login", u.Login))
+
+	redirect := h.SuccessRedirect
+	if redirect == "" {
+		redirect = "/"
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/httputil"
+)
+
+// CompareHandler — GET /api/repo/compare?owner=..&repo=..&base=..&head=..[&patch=1]
+// Изменённые файлы между двумя ref'ами (compare API GitHub) со статусом и числом строк.
+type CompareHandler struct {
+	GH *githubclient.Client
+}
+
+type compareFile struct {
+	Path         string `json:"path"`
+	PreviousPath string `json:"previousPath,omitempty"` // для renamed
+	Status       string `json:"status"`                 // added|removed|modified|renamed|copied|changed
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+	Patch        string `json:"patch,omitempty"` // только с ?patch=1
+}
+
+type compareResp struct {
+	Owner        string        `json:"owner"`
+	Repo         string        `json:"repo"`
+	Base         string        `json:"base"`
+	Head         string        `json:"head"`
+	Status       string        `json:"status"` // ahead|behind|diverged|identical
+	AheadBy      int           `json:"aheadBy"`
+	BehindBy     int           `json:"behindBy"`
+	TotalCommits int           `json:"totalCommits"`
+	MergeBase    string        `json:"mergeBase"`
+	Files        []compareFile `json:"files"`
+	Additions    int           `json:"additions"`
+	Deletions    int           `json:"deletions"`
+	Truncated    bool          `json:"truncated"` // GitHub отдал не все файлы
+}
+
+func (h *CompareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is allowed", nil)
+		return
+	}
+	q := r.URL.Query()
+	owner, repo := q.Get("owner"), q.Get("repo")
+	base, head := strings.TrimSpace(q.Get("base")), strings.TrimSpace(q.Get("head"))
+	if owner == "" || repo == "" || base == "" || head == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "owner, repo, base and head are required", nil)
+		return
+	}
+	if !validCompareRef(base) || !validCompareRef(head) {
+		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "invalid base or head ref", nil)
+		return
+	}
+	withPatch := q.Get("patch") == "1" || q.Get("patch") == "true"
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	cmp, err := h.GH.CompareRefs(ctx, owner, repo, base, head)
+	if err != nil {
+		if writeGitHubError(w, err, "repository or ref not found") {
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": err.Error()})
+		return
+	}
+
+	resp := compareResp{
+		Owner:        owner,
+		Repo:         repo,
+		Base:         base,
+		Head:         head,
+		Status:       cmp.Status,
+		AheadBy:      cmp.AheadBy,
+		BehindBy:     cmp.BehindBy,
+		TotalCommits: cmp.TotalCommits,
+		MergeBase:    cmp.MergeBase.SHA,
+		Files:        make([]compareFile, 0, len(cmp.Files)),
+		Truncated:    cmp.Truncated(),
+	}
+	for _, f := range cmp.Files {
+		cf := compareFile{
+			Path:         f.Filename,
+			PreviousPath: f.PreviousFilename,
+			Status:       f.Status,
+			Additions:    f.Additions,
+			Deletions:    f.Deletions,
+		}
+		if withPatch {
+			cf.Patch = f.Patch
+		}
+		resp.Files = append(resp.Files, cf)
+		resp.Additions += f.Additions
+		resp.Deletions += f.Deletions
+	}
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// validCompareRef — ref для пути /compare/{base}...{head}: без "..", пробелов и query.
+func validCompareRef(ref string) bool {
+	return !strings.Contains(ref, "..") && !strings.ContainsAny(ref, " \t?#")
+}
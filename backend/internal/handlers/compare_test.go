@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func TestCompareHandler(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/repos/o/r/compare/v1...main" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ahead", "ahead_by": 2, "total_commits": 2,
+			"merge_base_commit": map[string]any{"sha": "abc"},
+			"files": []map[string]any{
+				{"filename": "a.go", "status": "modified", "additions": 3, "deletions": 1, "patch": "@@ -1 +1 @@"},
+				{"filename": "b.go", "previous_filename": "old.go", "status": "renamed", "additions": 1},
+			},
+		})
+	}))
+	defer srv.Close()
+	h := &CompareHandler{GH: &githubclient.Client{BaseURL: srv.URL, Doer: srv.Client()}}
+
+	get := func(query string) (*httptest.ResponseRecorder, compareResp) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/repo/compare?"+query, nil))
+		var resp compareResp
+		if rec.Code == http.StatusOK {
+			_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		}
+		return rec, resp
+	}
+
+	// валидация — до похода в GitHub
+	for _, q := range []string{
+		"owner=o&repo=r&base=v1",
+		"owner=o&repo=r&base=v1..x&head=main",
+		"owner=o&repo=r&base=v1&head=ma%20in",
+		"owner=o&repo=r&base=v1&head=main%3Fx",
+	} {
+		if rec, _ := get(q); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, rec.Code)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("invalid requests reached GitHub %d times", calls)
+	}
+
+	if rec, _ := get("owner=o&repo=r&base=v0&head=main"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown ref: status %d, want 404", rec.Code)
+	}
+
+	rec, resp := get("owner=o&repo=r&base=v1&head=main")
+	if rec.Code != http.StatusOK || len(resp.Files) != 2 || resp.Additions != 4 || resp.Deletions != 1 ||
+		resp.MergeBase != "abc" || resp.Files[1].PreviousPath != "old.go" {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	if resp.Files[0].Patch != "" {
+		t.Error("patch returned without patch=1")
+	}
+	if _, resp = get("owner=o&repo=r&base=v1&head=main&patch=1"); resp.Files[0].Patch != "@@ -1 +1 @@" {
+		t.Errorf("patch=1: patch = %q", resp.Files[0].Patch)
+	}
+}
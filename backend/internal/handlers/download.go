@@ -8,15 +8,18 @@ import (
 
 	"github.com/yourname/cleanhttp/internal/artifacts"
 	"github.com/yourname/cleanhttp/internal/httputil"
+	"github.com/yourname/cleanhttp/internal/store"
 )
 
 // DownloadHandler — GET /api/download/:artifactId → отдаёт файл (стримом).
+// Артефакт чужого экспорта — 404.
 type DownloadHandler struct {
-	Store artifacts.ArtifactsStore
+	Store   artifacts.ArtifactsStore
+	Exports store.ExportsStore
 }
 
-func NewDownloadHandler(st artifacts.ArtifactsStore) *DownloadHandler {
-	return &DownloadHandler{Store: st}
+func NewDownloadHandler(st artifacts.ArtifactsStore, exports store.ExportsStore) *DownloadHandler {
+	return &DownloadHandler{Store: st, Exports: exports}
 }
 
 func (h *DownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -35,12 +38,16 @@ func (h *DownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	f, meta, _, err := h.Store.OpenByArtifactID(rest)
+	f, meta, exportID, err := h.Store.OpenByArtifactID(rest)
 	if err != nil {
 		httputil.WriteError(w, http.StatusNotFound, "not_found", "artifact not found", nil)
 		return
 	}
 	defer f.Close()
+	if _, ok := ownedExport(r, h.Exports, exportID); !ok {
+		httputil.WriteError(w, http.StatusNotFound, "not_found", "artifact not found", nil)
+		return
+	}
 
 	ct := artifacts.DetectContentType(meta.Name)
 	w.Header().Set("Content-Type", ct)
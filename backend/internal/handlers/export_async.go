@@ -2,15 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/hibiken/asynq"
 
+	"github.com/yourname/cleanhttp/internal/auth"
+	"github.com/yourname/cleanhttp/internal/filters"
 	"github.com/yourname/cleanhttp/internal/githubclient"
 	"github.com/yourname/cleanhttp/internal/httputil"
 	"github.com/yourname/cleanhttp/internal/jobs"
+	"github.com/yourname/cleanhttp/internal/secrets"
 	"github.com/yourname/cleanhttp/internal/store"
 )
 
@@ -21,6 +25,7 @@ type TaskEnqueuer interface {
 type ExportAsyncHandler struct {
 	Queue   TaskEnqueuer
 	Exports store.ExportsStore
+	Presets store.PresetsStore // пользовательские пресеты фильтров (nil — только встроенные)
 	GH      *githubclient.Client
 	Logger  *slog.Logger
 }
@@ -40,13 +45,25 @@ type exportRequest struct {
 	Profile         string   `json:"profile"`
 	IncludeGlobs    []string `json:"includeGlobs"`
 	ExcludeGlobs    []string `json:"excludeGlobs"`
+	FilterPresets   []string `json:"filterPresets"` // имена пресетов: их маски идут перед includeGlobs/excludeGlobs
 	SecretScan      bool     `json:"secretScan"`
 	SecretStrategy  string   `json:"secretStrategy"`
+	DropSecretFiles bool     `json:"dropSecretFiles"`
+	PIIScan         bool     `json:"piiScan"`
+	SecretBaseline  string   `json:"secretBaseline"` // содержимое baseline-файла: отпечатки принятых находок
+	NoRepoIgnore    bool     `json:"noRepoIgnore"`   // не применять .gitignore/.gitattributes/.rep2promptignore репозитория
+	SkipGenerated   *bool    `json:"skipGenerated"`  // nil → по формату: txt/promptpack — да, zip — нет
 	TokenModel      string   `json:"tokenModel"`
 	MaxBinarySizeMB int      `json:"maxBinarySizeMB"`
 	TTLHours        int      `json:"ttlHours"`
 	IdempotencyKey  string   `json:"idempotencyKey"`
-	Priority        string   `json:"priority"` // "high" | "default" | "low"
+	Priority        string   `json:"priority"`          // "high" | "default" | "low"
+	RootPath        string   `json:"rootPath"`          // только поддерево; пути в экспорте — относительно него
+	Submodules      bool     `json:"includeSubmodules"` // включить сабмодули (GitHub-хостинг, с лимитами глубины и размера)
+	ResolveLFS      bool     `json:"resolveLfs"`        // скачать объекты Git LFS вместо pointer'ов (текстовые, до EXPORT_LFS_MAX_MB)
+	PR              int      `json:"pr"`                // номер PR для format=pr
+	DiffBase        string   `json:"diffBase"`          // diff-режим (txt/promptpack): только файлы, изменённые в diffBase...ref
+	DiffHunks       bool     `json:"diffHunks"`         // в diff-режиме добавить unified diff к файлам
 }
 
 func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -71,17 +88,99 @@ func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		format = "promptpack"
 	}
 	req.Format = format
+	if format == "pr" && req.PR <= 0 {
+		httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+			"code":    "pr_required",
+			"message": "format pr needs a positive pr number",
+		})
+		return
+	}
+	req.DiffBase = strings.TrimSpace(req.DiffBase)
+	if req.DiffBase != "" && format != "txt" && format != "promptpack" {
+		httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+			"code":    "diff_format_unsupported",
+			"message": "diffBase works with txt and promptpack formats only",
+		})
+		return
+	}
+	// пресеты → итоговые маски; дальше везде (опции, payload) только они
+	inc, exc, err := filters.ResolvePresets(req.FilterPresets, presetLookup(r.Context(), h.Presets), req.IncludeGlobs, req.ExcludeGlobs)
+	if errors.Is(err, filters.ErrUnknownPreset) {
+		httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+			"code":    "unknown_filter_preset",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		httputil.WriteJSON(w, http.StatusInternalServerError, map[string]any{
+			"code":    "internal_error",
+			"message": err.Error(),
+		})
+		return
+	}
+	req.IncludeGlobs, req.ExcludeGlobs = inc, exc
+	if _, err := filters.NewMatcher(req.IncludeGlobs, req.ExcludeGlobs); err != nil {
+		httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+			"code":    "invalid_glob",
+			"message": err.Error(),
+		})
+		return
+	}
+	root, err := normalizeRootPath(req.RootPath)
+	if err != nil {
+		httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+			"code":    "invalid_root_path",
+			"message": err.Error(),
+		})
+		return
+	}
+	req.RootPath = root
+	// сгенерированное/vendored по умолчанию выкидываем из txt/promptpack (бережём бюджет),
+	// zip — «как в репозитории»
+	skipGenerated := req.Format != "zip"
+	if req.SkipGenerated != nil {
+		skipGenerated = *req.SkipGenerated
+	}
+	if req.SecretBaseline != "" {
+		if _, err := secrets.ParseBaseline(strings.NewReader(req.SecretBaseline)); err != nil {
+			httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+				"code":    "invalid_secret_baseline",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	// экспорт вошедшего пользователя: воркер возьмёт его токен по userId из БД
+	// (сам токен в Redis не кладём); ключ идемпотентности стор ищет в пределах пользователя
+	var userID string
+	if u, ok := auth.UserFromContext(r.Context()); ok {
+		userID = u.ID
+	}
 
-	exp, _ := h.Exports.CreateOrReuse(req.Owner, req.Repo, req.Ref, store.ExportOptions{
+	exp, _ := h.Exports.CreateOrReuseFor(userID, req.Owner, req.Repo, req.Ref, store.ExportOptions{
 		IncludeGlobs:    req.IncludeGlobs,
 		ExcludeGlobs:    req.ExcludeGlobs,
+		FilterPresets:   req.FilterPresets,
 		SecretScan:      req.SecretScan,
 		SecretStrategy:  req.SecretStrategy,
+		DropSecretFiles: req.DropSecretFiles,
+		PIIScan:         req.PIIScan,
+		SecretBaseline:  req.SecretBaseline,
+		NoRepoIgnore:    req.NoRepoIgnore,
+		SkipGenerated:   skipGenerated,
 		TokenModel:      req.TokenModel,
 		MaxBinarySizeMB: req.MaxBinarySizeMB,
 		TTLHours:        req.TTLHours,
 		Profile:         req.Profile,
 		Format:          req.Format,
+		RootPath:        req.RootPath,
+		Submodules:      req.Submodules,
+		ResolveLFS:      req.ResolveLFS,
+		PR:              req.PR,
+		DiffBase:        req.DiffBase,
+		DiffHunks:       req.DiffHunks,
 		IdempotencyKey:  req.IdempotencyKey,
 	})
 
@@ -97,6 +196,7 @@ func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	payload := struct {
 		ExportID        string   `json:"exportId"`
+		UserID          string   `json:"userId,omitempty"`
 		Owner           string   `json:"owner"`
 		Repo            string   `json:"repo"`
 		Ref             string   `json:"ref"`
@@ -106,12 +206,24 @@ func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ExcludeGlobs    []string `json:"excludeGlobs"`
 		SecretScan      bool     `json:"secretScan"`
 		SecretStrategy  string   `json:"secretStrategy"`
+		DropSecretFiles bool     `json:"dropSecretFiles"`
+		PIIScan         bool     `json:"piiScan"`
+		SecretBaseline  string   `json:"secretBaseline"`
+		NoRepoIgnore    bool     `json:"noRepoIgnore"`
+		SkipGenerated   bool     `json:"skipGenerated"`
 		TokenModel      string   `json:"tokenModel"`
 		MaxBinarySizeMB int      `json:"maxBinarySizeMB"`
 		TTLHours        int      `json:"ttlHours"`
 		IdempotencyKey  string   `json:"idempotencyKey"`
+		RootPath        string   `json:"rootPath,omitempty"`
+		Submodules      bool     `json:"includeSubmodules,omitempty"`
+		ResolveLFS      bool     `json:"resolveLfs,omitempty"`
+		PR              int      `json:"pr,omitempty"`
+		DiffBase        string   `json:"diffBase,omitempty"`
+		DiffHunks       bool     `json:"diffHunks,omitempty"`
 	}{
 		ExportID:        exp.ID,
+		UserID:          userID,
 		Owner:           req.Owner,
 		Repo:            req.Repo,
 		Ref:             req.Ref,
@@ -121,10 +233,21 @@ func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ExcludeGlobs:    req.ExcludeGlobs,
 		SecretScan:      req.SecretScan,
 		SecretStrategy:  req.SecretStrategy,
+		DropSecretFiles: req.DropSecretFiles,
+		PIIScan:         req.PIIScan,
+		SecretBaseline:  req.SecretBaseline,
+		NoRepoIgnore:    req.NoRepoIgnore,
+		SkipGenerated:   skipGenerated,
 		TokenModel:      req.TokenModel,
 		MaxBinarySizeMB: req.MaxBinarySizeMB,
 		TTLHours:        req.TTLHours,
 		IdempotencyKey:  req.IdempotencyKey,
+		RootPath:        req.RootPath,
+		Submodules:      req.Submodules,
+		ResolveLFS:      req.ResolveLFS,
+		PR:              req.PR,
+		DiffBase:        req.DiffBase,
+		DiffHunks:       req.DiffHunks,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -182,3 +305,13 @@ func (h *ExportAsyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"status":   exp.Status,
 	})
 }
+
+// normalizeRootPath — rootPath экспорта: "" или "/" — весь репозиторий,
+// иначе нормализованный путь каталога без "/" по краям.
+func normalizeRootPath(p string) (string, error) {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" || p == "." {
+		return "", nil
+	}
+	return filters.NormalizeRel(p)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/httputil"
+)
+
+// writeGitHubError — общие ошибки GitHub-клиента → HTTP-ответ.
+// notFound — текст для 404 («repository not found», «file not found»...).
+// false — ошибка не из GitHub, ответ пишет вызывающий.
+func writeGitHubError(w http.ResponseWriter, err error, notFound string) bool {
+	var rl *githubclient.RateLimitedError
+	var sl *githubclient.SecondaryRateLimitError
+	var fe *githubclient.ForbiddenError
+	switch {
+	case errors.As(err, &rl):
+		httputil.WriteError(w, http.StatusTooManyRequests, "rate_limited", "GitHub API rate limited", map[string]any{"reset": rl.Reset})
+	case errors.As(err, &sl):
+		secs := int(math.Ceil(sl.RetryAfter.Seconds()))
+		if secs > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+		}
+		httputil.WriteError(w, http.StatusTooManyRequests, "secondary_rate_limited", "GitHub secondary rate limit, retry later", map[string]any{"retryAfter": secs, "message": sl.Message})
+	case errors.As(err, &fe):
+		httputil.WriteError(w, http.StatusForbidden, "forbidden", "GitHub denied access: "+fe.Message, map[string]any{"status": fe.Status})
+	case errors.Is(err, githubclient.ErrNotFound):
+		httputil.WriteError(w, http.StatusNotFound, "not_found", notFound, nil)
+	case errors.Is(err, githubclient.ErrUpstream):
+		httputil.WriteError(w, http.StatusBadGateway, "upstream_error", "GitHub upstream error", nil)
+	default:
+		return false
+	}
+	return true
+}
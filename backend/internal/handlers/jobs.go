@@ -6,11 +6,29 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/yourname/cleanhttp/internal/auth"
 	"github.com/yourname/cleanhttp/internal/httputil"
 	"github.com/yourname/cleanhttp/internal/jobs"
 	"github.com/yourname/cleanhttp/internal/store"
 )
 
+// ownedExport — экспорт, если он анонимный или принадлежит пользователю сессии.
+// Чужой экспорт для вызывающего неотличим от несуществующего (404).
+func ownedExport(r *http.Request, exports store.ExportsStore, id string) (*store.Export, bool) {
+	exp, ok := exports.Get(id)
+	if !ok {
+		return nil, false
+	}
+	if exp.UserID == "" {
+		return exp, true
+	}
+	u, ok := auth.UserFromContext(r.Context())
+	if !ok || u.ID != exp.UserID {
+		return nil, false
+	}
+	return exp, true
+}
+
 // JobStatusHandler — GET /api/jobs/:id
 type JobStatusHandler struct {
 	Exports store.ExportsStore
@@ -26,7 +44,7 @@ func (h *JobStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "missing id", nil)
 		return
 	}
-	if exp, ok := h.Exports.Get(id); ok {
+	if exp, ok := ownedExport(r, h.Exports, id); ok {
 		resp := jobStatusResponse(exp)
 		httputil.WriteJSON(w, http.StatusOK, resp)
 		return
@@ -50,6 +68,10 @@ func (h *JobCancelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "missing id", nil)
 		return
 	}
+	if _, ok := ownedExport(r, h.Exports, id); !ok {
+		httputil.WriteError(w, http.StatusNotFound, "not_found", "job not found", nil)
+		return
+	}
 	if !h.Exports.RequestCancel(id) {
 		httputil.WriteError(w, http.StatusConflict, "conflict", "job already finished or not found", nil)
 		return
@@ -77,7 +99,7 @@ func (h *JobEventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "missing id", nil)
 		return
 	}
-	exp, ok := h.Exports.Get(id)
+	exp, ok := ownedExport(r, h.Exports, id)
 	if !ok {
 		httputil.WriteError(w, http.StatusNotFound, "not_found", "job not found", nil)
 		return
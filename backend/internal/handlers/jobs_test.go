@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/auth"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+func TestJobHandlers_HideOtherUsersExports(t *testing.T) {
+	exports := store.NewExportsMem("exp")
+	own, _ := exports.CreateOrReuseFor("u1", "o", "r", "main", store.ExportOptions{})
+	anon, _ := exports.CreateOrReuseFor("", "o", "r", "main", store.ExportOptions{})
+
+	do := func(h http.Handler, method, path, userID string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if userID != "" {
+			req = req.WithContext(auth.WithUser(req.Context(), store.User{ID: userID}))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	status := &JobStatusHandler{Exports: exports}
+	cancel := &JobCancelHandler{Exports: exports}
+
+	cases := []struct {
+		name   string
+		h      http.Handler
+		method string
+		path   string
+		user   string
+		want   int
+	}{
+		{"owner", status, http.MethodGet, "/jobs/" + own.ID, "u1", http.StatusOK},
+		{"other user", status, http.MethodGet, "/jobs/" + own.ID, "u2", http.StatusNotFound},
+		{"anonymous", status, http.MethodGet, "/jobs/" + own.ID, "", http.StatusNotFound},
+		{"anonymous export", status, http.MethodGet, "/jobs/" + anon.ID, "u2", http.StatusOK},
+		{"cancel by other user", cancel, http.MethodPost, "/jobs/" + own.ID + "/cancel", "u2", http.StatusNotFound},
+		{"cancel by owner", cancel, http.MethodPost, "/jobs/" + own.ID + "/cancel", "u1", http.StatusOK},
+	}
+	for _, c := range cases {
+		if got := do(c.h, c.method, c.path, c.user); got != c.want {
+			t.Errorf("%s: status %d, want %d", c.name, got, c.want)
+		}
+	}
+	if !exports.IsCancelRequested(own.ID) {
+		t.Fatal("owner cancel was not applied")
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/auth"
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/httputil"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+// FilterPresetsHandler — пресеты масок:
+//
+//	GET    /api/filter-presets        — встроенные + пресеты вошедшего пользователя
+//	GET    /api/filter-presets/:name  — один пресет
+//	POST   /api/filter-presets        — создать/обновить свой (нужен вход)
+//	DELETE /api/filter-presets/:name  — удалить свой (нужен вход)
+//
+// Пользовательские пресеты видны только их владельцу; анониму — только встроенные.
+type FilterPresetsHandler struct {
+	Presets store.PresetsStore
+}
+
+func (h *FilterPresetsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/filter-presets"), "/")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	u, signedIn := auth.UserFromContext(r.Context())
+	if (r.Method == http.MethodPost || r.Method == http.MethodDelete) && !signedIn {
+		httputil.WriteError(w, http.StatusUnauthorized, "unauthorized", "sign in to manage filter presets", nil)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		list := filters.BuiltinPresets()
+		if signedIn {
+			own, err := h.Presets.ListPresets(ctx, u.ID)
+			if err != nil {
+				httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to list presets", map[string]any{"error": err.Error()})
+				return
+			}
+			list = append(list, own...)
+		}
+		httputil.WriteJSON(w, http.StatusOK, map[string]any{"items": list})
+
+	case r.Method == http.MethodGet:
+		if p, ok := filters.BuiltinPreset(name); ok {
+			httputil.WriteJSON(w, http.StatusOK, p)
+			return
+		}
+		if !signedIn {
+			httputil.WriteError(w, http.StatusNotFound, "not_found", "preset not found", nil)
+			return
+		}
+		p, err := h.Presets.GetPreset(ctx, u.ID, name)
+		if errors.Is(err, store.ErrPresetNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "not_found", "preset not found", nil)
+			return
+		}
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to get preset", map[string]any{"error": err.Error()})
+			return
+		}
+		httputil.WriteJSON(w, http.StatusOK, p)
+
+	case r.Method == http.MethodPost && name == "":
+		var p filters.Preset
+		if err := httputil.DecodeJSON(r, &p); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "bad_request", "invalid JSON body", map[string]any{"error": err.Error()})
+			return
+		}
+		p.Name = strings.TrimSpace(p.Name)
+		p.Builtin = false
+		if err := filters.ValidatePreset(p); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid_preset", err.Error(), nil)
+			return
+		}
+		if err := h.Presets.PutPreset(ctx, u.ID, p); err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to save preset", map[string]any{"error": err.Error()})
+			return
+		}
+		httputil.WriteJSON(w, http.StatusOK, p)
+
+	case r.Method == http.MethodDelete && name != "":
+		if _, ok := filters.BuiltinPreset(name); ok {
+			httputil.WriteError(w, http.StatusConflict, "conflict", "built-in presets cannot be deleted", nil)
+			return
+		}
+		err := h.Presets.DeletePreset(ctx, u.ID, name)
+		if errors.Is(err, store.ErrPresetNotFound) {
+			httputil.WriteError(w, http.StatusNotFound, "not_found", "preset not found", nil)
+			return
+		}
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "failed to delete preset", map[string]any{"error": err.Error()})
+			return
+		}
+		httputil.WriteJSON(w, http.StatusOK, map[string]any{"ok": true})
+
+	default:
+		httputil.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method", nil)
+	}
+}
+
+// presetLookup — поиск пресета вошедшего пользователя для filters.ResolvePresets.
+// Аноним видит только встроенные; ошибка хранилища — не «неизвестный пресет».
+func presetLookup(ctx context.Context, ps store.PresetsStore) func(string) (filters.Preset, bool, error) {
+	u, ok := auth.UserFromContext(ctx)
+	if ps == nil || !ok {
+		return nil
+	}
+	return func(name string) (filters.Preset, bool, error) {
+		p, err := ps.GetPreset(ctx, u.ID, name)
+		if errors.Is(err, store.ErrPresetNotFound) {
+			return filters.Preset{}, false, nil
+		}
+		return p, err == nil, err
+	}
+}
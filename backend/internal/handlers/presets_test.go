@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/auth"
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+func asUser(r *http.Request, userID string) *http.Request {
+	if userID == "" {
+		return r
+	}
+	return r.WithContext(auth.WithUser(r.Context(), store.User{ID: userID}))
+}
+
+func TestFilterPresetsHandler_ScopedToUser(t *testing.T) {
+	h := &FilterPresetsHandler{Presets: store.NewPresetsMem()}
+	do := func(method, path, body, userID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, asUser(httptest.NewRequest(method, path, strings.NewReader(body)), userID))
+		return rec
+	}
+	const mine = `{"name":"mine","includeGlobs":["api/"]}`
+
+	if rec := do(http.MethodPost, "/filter-presets", mine, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous create: status %d, want 401", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/filter-presets", mine, "u1"); rec.Code != http.StatusOK {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	names := func(userID string) []string {
+		var resp struct{ Items []filters.Preset }
+		_ = json.Unmarshal(do(http.MethodGet, "/filter-presets", "", userID).Body.Bytes(), &resp)
+		var out []string
+		for _, p := range resp.Items {
+			if !p.Builtin {
+				out = append(out, p.Name)
+			}
+		}
+		return out
+	}
+	if got := names("u1"); len(got) != 1 || got[0] != "mine" {
+		t.Errorf("owner list = %v", got)
+	}
+	if got := names("u2"); len(got) != 0 {
+		t.Errorf("other user sees %v", got)
+	}
+	if got := names(""); len(got) != 0 {
+		t.Errorf("anonymous sees %v", got)
+	}
+	if rec := do(http.MethodGet, "/filter-presets/mine", "", "u2"); rec.Code != http.StatusNotFound {
+		t.Errorf("other user get: status %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/filter-presets/mine", "", "u2"); rec.Code != http.StatusNotFound {
+		t.Errorf("other user delete: status %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/filter-presets/mine", "", "u1"); rec.Code != http.StatusOK {
+		t.Errorf("owner get: status %d", rec.Code)
+	}
+}
+
+// failingPresets — хранилище, которое всегда падает (нет БД).
+type failingPresets struct{ store.PresetsStore }
+
+func (failingPresets) GetPreset(context.Context, string, string) (filters.Preset, error) {
+	return filters.Preset{}, errors.New("connection refused")
+}
+
+func TestExportAsyncHandler_PresetLookupErrors(t *testing.T) {
+	post := func(ps store.PresetsStore, userID string) int {
+		h := &ExportAsyncHandler{Presets: ps}
+		body := `{"owner":"o","repo":"r","filterPresets":["mine"]}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, asUser(httptest.NewRequest(http.MethodPost, "/export", strings.NewReader(body)), userID))
+		return rec.Code
+	}
+	if got := post(store.NewPresetsMem(), "u1"); got != http.StatusBadRequest {
+		t.Errorf("unknown preset: status %d, want 400", got)
+	}
+	if got := post(failingPresets{}, "u1"); got != http.StatusInternalServerError {
+		t.Errorf("store failure: status %d, want 500", got)
+	}
+	// чужой пресет анониму не виден, и до хранилища дело не доходит
+	if got := post(failingPresets{}, ""); got != http.StatusBadRequest {
+		t.Errorf("anonymous: status %d, want 400", got)
+	}
+}
@@ -35,6 +35,7 @@ type previewReq struct {
 type previewResp struct {
 	Content   string `json:"content"`
 	Truncated bool   `json:"truncated"`
+	Kind      string `json:"kind"` // text или lfs-pointer (тогда content — сам pointer)
 }
 
 // ServeHTTP — POST /api/preview
@@ -76,16 +77,7 @@ func (h *PreviewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Качаем сырой файл.
 	data, truncated, err := h.GH.GetRawFile(ctx, in.Owner, in.Repo, np, in.Ref, maxBytes)
 	if err != nil {
-		if rl, ok := err.(*githubclient.RateLimitedError); ok {
-			httputil.WriteError(w, http.StatusTooManyRequests, "rate_limited", "GitHub API rate limited", map[string]any{"reset": rl.Reset})
-			return
-		}
-		if err == githubclient.ErrNotFound {
-			httputil.WriteError(w, http.StatusNotFound, "not_found", "file not found", nil)
-			return
-		}
-		if err == githubclient.ErrUpstream {
-			httputil.WriteError(w, http.StatusBadGateway, "upstream_error", "GitHub upstream error", nil)
+		if writeGitHubError(w, err, "file not found") {
 			return
 		}
 		if err == githubclient.ErrTooLarge {
@@ -101,8 +93,9 @@ func (h *PreviewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if len(sample) > 4096 {
 		sample = sample[:4096]
 	}
-	if filters.IsBinarySample(sample) {
-		httputil.WriteError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "binary files are not previewable", nil)
+	kind := filters.SniffKind(sample)
+	if kind != filters.KindText && kind != filters.KindLFSPointer {
+		httputil.WriteError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "binary files are not previewable", map[string]any{"kind": kind})
 		return
 	}
 
@@ -115,5 +108,6 @@ func (h *PreviewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusOK, previewResp{
 		Content:   s,
 		Truncated: truncated,
+		Kind:      kind,
 	})
 }
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/httputil"
+)
+
+// GitHubRateLimitHandler — GET /api/github/ratelimit: остаток квоты по токенам пула.
+// ?refresh=1 — опросить токены, по которым ещё не было ответов.
+type GitHubRateLimitHandler struct {
+	GH *githubclient.Client
+}
+
+func (h *GitHubRateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is allowed", nil)
+		return
+	}
+	probe, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	tokens := h.GH.RateLimits(ctx, probe)
+	if tokens == nil {
+		tokens = []githubclient.TokenQuota{}
+	}
+	remaining, limit, exhausted := 0, 0, 0
+	for _, t := range tokens {
+		remaining += t.Remaining
+		limit += t.Limit
+		if t.Exhausted {
+			exhausted++
+		}
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"tokens":    tokens,
+		"remaining": remaining,
+		"limit":     limit,
+		"exhausted": exhausted,
+	})
+}
+
+// GitHubMetricsHandler — /metrics: квоты токенов в формате Prometheus.
+type GitHubMetricsHandler struct {
+	GH *githubclient.Client
+}
+
+func (h *GitHubMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if h.GH.Pool != nil {
+		h.GH.Pool.WriteMetrics(w)
+	}
+}
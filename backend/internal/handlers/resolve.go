@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/yourname/cleanhttp/internal/githubclient"
@@ -16,6 +17,7 @@ type resolveReq struct{ URL string `json:"url"` }
 type resolveResp struct {
 	Owner, Repo, DefaultRef string
 	Refs                    []string
+	RepoRef                 githubclient.RepoRef // ref/путь/PR из ссылки — UI предвыбирает ветку и поддерево
 }
 
 func (h *ResolveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +36,7 @@ func (h *ResolveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	owner, repo, err := githubclient.ParseGitHubURL(in.URL)
+	ref, err := githubclient.ParseRepoURL(in.URL)
 	if err != nil {
 		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "invalid GitHub URL", map[string]any{"error": err.Error()})
 		return
@@ -43,25 +45,35 @@ func (h *ResolveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	owner, repo := ref.Owner, ref.Repo
 	branch, err := h.GH.GetDefaultBranch(ctx, owner, repo)
 	if err != nil {
-		if rl, ok := err.(*githubclient.RateLimitedError); ok {
-			httputil.WriteError(w, http.StatusTooManyRequests, "rate_limited", "GitHub API rate limited", map[string]any{"reset": rl.Reset})
-			return
-		}
-		if err == githubclient.ErrNotFound {
-			httputil.WriteError(w, http.StatusNotFound, "not_found", "repository not found", nil)
-			return
-		}
-		if err == githubclient.ErrUpstream {
-			httputil.WriteError(w, http.StatusBadGateway, "upstream_error", "GitHub upstream error", nil)
+		if writeGitHubError(w, err, "repository not found") {
 			return
 		}
 		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": err.Error()})
 		return
 	}
 
+	// tree/blob-ссылка со слэшем после ref: «release/1.2/services/api» —
+	// это ветка release/1.2 или ветка release и путь? Сверяемся со списком refs.
+	if strings.Contains(ref.RefPath, "/") && !githubclient.IsCommitSHA(ref.Ref) {
+		names, err := h.GH.MatchingRefs(ctx, owner, repo, ref.Ref)
+		if err != nil {
+			if writeGitHubError(w, err, "repository not found") {
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": err.Error()})
+			return
+		}
+		ref.Ref, ref.Path = githubclient.SplitRefPath(ref.RefPath, append(names, branch))
+	}
+
+	refs := []string{branch}
+	if ref.Ref != "" && ref.Ref != branch {
+		refs = append(refs, ref.Ref)
+	}
 	httputil.WriteJSON(w, http.StatusOK, resolveResp{
-		Owner: owner, Repo: repo, DefaultRef: branch, Refs: []string{branch},
+		Owner: owner, Repo: repo, DefaultRef: branch, Refs: refs, RepoRef: ref,
 	})
 }
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yourname/cleanhttp/internal/httputil"
+	"github.com/yourname/cleanhttp/internal/repostats"
+)
+
+// RepoStatsHandler — GET /api/repo/stats?owner=..&repo=..&ref=..[&largest=N]
+// Статистика строится по дереву (тот же кэш, что у /repo/tree), файлы не качаем.
+type RepoStatsHandler struct {
+	Tree *TreeHandler
+}
+
+// maxLargest — верхняя граница для ?largest=
+const maxLargest = 100
+
+type repoStatsResp struct {
+	Owner     string `json:"owner"`
+	Repo      string `json:"repo"`
+	Ref       string `json:"ref"`
+	Truncated bool   `json:"truncated"` // дерево неполное — цифры занижены
+	repostats.Stats
+}
+
+func (h *RepoStatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httputil.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "only GET is allowed", nil)
+		return
+	}
+	q := r.URL.Query()
+	owner, repo, ref := q.Get("owner"), q.Get("repo"), q.Get("ref")
+	if ref == "" {
+		ref = "main"
+	}
+	if owner == "" || repo == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "bad_request", "owner and repo are required", nil)
+		return
+	}
+	largest := repostats.DefaultLargest
+	if s := q.Get("largest"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxLargest {
+			httputil.WriteError(w, http.StatusBadRequest, "bad_request", "largest must be 1..100", nil)
+			return
+		}
+		largest = n
+	}
+
+	tree, err := h.Tree.loadTree(r.Context(), owner, repo, ref)
+	if err != nil {
+		writeTreeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, repoStatsResp{
+		Owner:     owner,
+		Repo:      repo,
+		Ref:       ref,
+		Truncated: tree.Truncated,
+		Stats:     repostats.Compute(tree.Items, largest),
+	})
+}
@@ -2,31 +2,34 @@ package handlers // пакет хендлеров
 
 import (
 	"context" // локальный таймаут на внешний вызов
-	"fmt"     // соберём ключ кэша
+	"log/slog"
 	"net/http"
-	"sync"    // синхронизация доступа к кэшу
 	"time"
 
 	"github.com/yourname/cleanhttp/internal/githubclient" // наш GitHub-клиент
 	"github.com/yourname/cleanhttp/internal/httputil"     // унифицированные JSON-ответы
+	"github.com/yourname/cleanhttp/internal/treecache"    // общий кэш деревьев (память/Redis)
 )
 
 // TreeHandler — хендлер с зависимостями: GitHub-клиент и кэш.
 type TreeHandler struct {
-	GH    *githubclient.Client // внешний API-клиент
-	cache *treeCache           // простой in-memory кэш
+	GH     *githubclient.Client // внешний API-клиент
+	cache  treecache.TreeCache  // деревья по SHA + ref → SHA
+	refTTL time.Duration        // сколько ref → SHA считается свежим без ревалидации
 }
 
-// NewTreeHandler — конструктор. ttl задаёт срок жизни записи.
-func NewTreeHandler(gh *githubclient.Client, ttl time.Duration) *TreeHandler {
+// NewTreeHandler — конструктор. refTTL — как часто перепроверять, куда указывает ветка/тег.
+func NewTreeHandler(gh *githubclient.Client, cache treecache.TreeCache, refTTL time.Duration) *TreeHandler {
 	return &TreeHandler{
-		GH:    gh,
-		cache: newTreeCache(ttl),
+		GH:     gh,
+		cache:  cache,
+		refTTL: refTTL,
 	}
 }
 
 // ServeHTTP — реализация GET /api/repo/tree?owner=..&repo=..&ref=..
 // Если ref не указан, по умолчанию используем "main".
+// Фильтры, глубина и пагинация — см. treeQuery.
 func (h *TreeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Разрешаем только GET
 	if r.Method != http.MethodGet {
@@ -47,90 +50,95 @@ func (h *TreeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Ключ кэша вида: tree:owner/repo@ref
-	key := fmt.Sprintf("tree:%s/%s@%s", owner, repo, ref)
-
-	// 1) Попробуем отдать из кэша
-	if items, ok := h.cache.Get(key); ok {
-		httputil.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+	tq, err := parseTreeQuery(q)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
 		return
 	}
 
-	// 2) Иначе идём к GitHub с отдельным таймаутом на вызов
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
-	defer cancel()
-
-	items, err := h.GH.GetTree(ctx, owner, repo, ref)
+	tree, err := h.loadTree(r.Context(), owner, repo, ref)
 	if err != nil {
-		// Развилка по типам/кодам ошибок — такие же правила, как в resolve
-		if rl, ok := err.(*githubclient.RateLimitedError); ok {
-			httputil.WriteError(w, http.StatusTooManyRequests, "rate_limited", "GitHub API rate limited", map[string]any{"reset": rl.Reset})
-			return
-		}
-		if err == githubclient.ErrNotFound {
-			httputil.WriteError(w, http.StatusNotFound, "not_found", "repository or ref not found", nil)
-			return
-		}
-		if err == githubclient.ErrUpstream {
-			httputil.WriteError(w, http.StatusBadGateway, "upstream_error", "GitHub upstream error", nil)
-			return
-		}
-		httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": err.Error()})
+		writeTreeError(w, err)
 		return
 	}
-
-	// 3) Сохраним в кэш и вернём клиенту
-	h.cache.Set(key, items)
-	httputil.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+	items, total, next, err := tq.apply(tree.Items)
+	if err != nil {
+		httputil.WriteError(w, http.StatusNotFound, "not_found", err.Error(), map[string]any{"path": tq.path})
+		return
+	}
+	resp := map[string]any{
+		"items":     items,
+		"total":     total,
+		"truncated": tree.Truncated, // дерево неполное даже после обхода по каталогам
+	}
+	if next != "" {
+		resp["nextCursor"] = next
+	}
+	httputil.WriteJSON(w, http.StatusOK, resp)
 }
 
-// ===== Простейший in-memory кэш с TTL =====
+// loadTree — дерево из кэша или из GitHub (с сохранением в кэш).
+// Общий путь для /repo/tree и /repo/stats. Кэш — по SHA коммита, так что
+// новый коммит в ветке сразу даёт новое дерево, а не ждёт TTL.
+func (h *TreeHandler) loadTree(ctx context.Context, owner, repo, ref string) (githubclient.TreeResult, error) {
+	// отдельный таймаут на все внешние вызовы
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
 
-type treeCache struct {
-	mu   sync.RWMutex                              // защищает map от гонок
-	ttl  time.Duration                             // срок жизни записи
-	data map[string]struct{ until time.Time; v []githubclient.TreeItem } // значение + срок годности
-}
+	// 1) ref → SHA
+	sha, err := h.resolveRef(ctx, owner, repo, ref)
+	if err != nil {
+		return githubclient.TreeResult{}, err
+	}
+
+	// 2) дерево этого коммита — из кэша
+	key := treecache.TreeKey(githubclient.TokenScope(ctx), owner, repo, sha)
+	if res, ok := h.cache.GetTree(ctx, key); ok {
+		return res, nil
+	}
 
-func newTreeCache(ttl time.Duration) *treeCache {
-	return &treeCache{
-		ttl:  ttl,
-		data: make(map[string]struct{ until time.Time; v []githubclient.TreeItem }),
+	// 3) иначе идём к GitHub и сохраняем
+	res, err := h.GH.GetTreeFull(ctx, owner, repo, sha)
+	if err != nil {
+		return githubclient.TreeResult{}, err
 	}
+	h.cache.SetTree(ctx, key, res)
+	return res, nil
 }
 
-func (c *treeCache) Get(key string) ([]githubclient.TreeItem, bool) {
-	c.mu.RLock()
-	entry, ok := c.data[key]
-	c.mu.RUnlock()
-	if !ok {
-		return nil, false
+// resolveRef — SHA коммита для ref. Полный SHA не резолвим; свежую запись
+// берём из кэша; устаревшую ревалидируем по ETag (304 — SHA прежний).
+func (h *TreeHandler) resolveRef(ctx context.Context, owner, repo, ref string) (string, error) {
+	if githubclient.IsCommitSHA(ref) {
+		return ref, nil
 	}
-	// Проверяем срок годности
-	if time.Now().After(entry.until) {
-		// Протухло — удалим и скажем «нет»
-		c.mu.Lock()
-		delete(c.data, key)
-		c.mu.Unlock()
-		return nil, false
+	key := treecache.RefKey(githubclient.TokenScope(ctx), owner, repo, ref)
+	prev, cached := h.cache.GetRef(ctx, key)
+	if cached && time.Since(prev.CheckedAt) < h.refTTL {
+		return prev.SHA, nil
 	}
-	// Возвращаем копию среза (чтобы вызывающий не мог испортить наш кэш)
-	out := make([]githubclient.TreeItem, len(entry.v))
-	copy(out, entry.v)
-	return out, true
+
+	cr, notModified, err := h.GH.ResolveCommit(ctx, owner, repo, ref, prev.ETag)
+	switch {
+	case err == nil && notModified:
+		cr = githubclient.CommitRef{SHA: prev.SHA, ETag: prev.ETag}
+	case err == nil:
+	case cached && err != githubclient.ErrNotFound:
+		// GitHub недоступен или лимит — лучше чуть устаревшее дерево, чем ошибка
+		slog.Warn("ref revalidation failed, using cached sha",
+			slog.String("repo", owner+"/"+repo), slog.String("ref", ref), slog.String("error", err.Error()))
+		return prev.SHA, nil
+	default:
+		return "", err
+	}
+	h.cache.SetRef(ctx, key, treecache.RefEntry{SHA: cr.SHA, ETag: cr.ETag, CheckedAt: time.Now()})
+	return cr.SHA, nil
 }
 
-func (c *treeCache) Set(key string, v []githubclient.TreeItem) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	// Кладём копию среза в кэш, выставляем TTL
-	cp := make([]githubclient.TreeItem, len(v))
-	copy(cp, v)
-	c.data[key] = struct {
-		until time.Time
-		v     []githubclient.TreeItem
-	}{
-		until: time.Now().Add(c.ttl),
-		v:     cp,
+// writeTreeError — ошибки GetTree → HTTP (такие же правила, как в resolve).
+func writeTreeError(w http.ResponseWriter, err error) {
+	if writeGitHubError(w, err, "repository or ref not found") {
+		return
 	}
+	httputil.WriteError(w, http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": err.Error()})
 }
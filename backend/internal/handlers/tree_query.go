@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/tokenest"
+)
+
+// maxTreeLimit — наибольший размер страницы /repo/tree.
+const maxTreeLimit = 5000
+
+// treeQuery — разобранные параметры /repo/tree:
+//
+//	path=dir            — только поддерево dir (без самого dir)
+//	depth=N             — глубина относительно path (1 — прямые дети); 0 — без ограничения
+//	include=…&exclude=… — маски (синтаксис .gitignore), можно повторять
+//	cursor=…&limit=N    — страница; cursor берём из nextCursor предыдущего ответа
+//	includeTokens=1     — оценка токенов у файлов и сумма по каталогам
+type treeQuery struct {
+	path    string
+	depth   int
+	matcher *filters.Matcher // nil — без масок
+	offset  int
+	limit   int // 0 — всё
+	tokens  bool
+}
+
+// treeItemOut — элемент ответа: TreeItem + (опционально) токены.
+type treeItemOut struct {
+	githubclient.TreeItem
+	Tokens *int64 `json:"tokens,omitempty"`
+}
+
+// errTreePathNotFound — path не является каталогом дерева.
+var errTreePathNotFound = errors.New("path not found")
+
+func parseTreeQuery(q url.Values) (treeQuery, error) {
+	var tq treeQuery
+	if p := strings.Trim(q.Get("path"), "/"); p != "" {
+		np, err := filters.NormalizeRel(p)
+		if err != nil {
+			return tq, errors.New("invalid path")
+		}
+		tq.path = np
+	}
+	var err error
+	if tq.depth, err = intParam(q, "depth", 0, 0, 1<<10); err != nil {
+		return tq, err
+	}
+	if tq.limit, err = intParam(q, "limit", 0, 1, maxTreeLimit); err != nil {
+		return tq, err
+	}
+	if c := q.Get("cursor"); c != "" {
+		// курсор — смещение в отфильтрованном списке
+		if tq.offset, err = strconv.Atoi(c); err != nil || tq.offset < 0 {
+			return tq, errors.New("invalid cursor")
+		}
+	}
+	if inc, exc := q["include"], q["exclude"]; len(inc) > 0 || len(exc) > 0 {
+		if tq.matcher, err = filters.NewMatcher(inc, exc); err != nil {
+			return tq, err
+		}
+	}
+	tq.tokens, _ = strconv.ParseBool(q.Get("includeTokens"))
+	return tq, nil
+}
+
+// intParam — целый параметр в [min, max]; пусто — def.
+func intParam(q url.Values, name string, def, min, max int) (int, error) {
+	s := q.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, errors.New(name + " must be " + strconv.Itoa(min) + ".." + strconv.Itoa(max))
+	}
+	return n, nil
+}
+
+// apply — выборка по параметрам. Порядок исходного дерева (папки, затем файлы) сохраняется.
+// Возвращает страницу, общее число подходящих элементов и курсор следующей страницы ("" — конец).
+func (tq treeQuery) apply(items []githubclient.TreeItem) ([]treeItemOut, int, string, error) {
+	prefix := ""
+	if tq.path != "" {
+		found := false
+		for _, it := range items {
+			if it.Path == tq.path && it.Type == "dir" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, 0, "", errTreePathNotFound
+		}
+		prefix = tq.path + "/"
+	}
+
+	// 1) файлы (и сабмодули) под path, прошедшие маски; их предки — «живые» каталоги
+	est := tokenest.NewEstimator()
+	keep := make([]bool, len(items))
+	liveDirs := map[string]bool{}
+	dirTokens := map[string]int64{}
+	for i, it := range items {
+		if !strings.HasPrefix(it.Path, prefix) || (it.Type == "dir" && !it.Submodule) {
+			continue
+		}
+		if tq.matcher != nil && !tq.matcher.Match(it.Path) {
+			continue
+		}
+		keep[i] = true
+		var tok int64
+		if tq.tokens && it.Kind == filters.KindText {
+			tok = est.CountForSize(it.Size)
+		}
+		for d := path.Dir(it.Path); d != "." && d+"/" != prefix && strings.HasPrefix(d, prefix); d = path.Dir(d) {
+			liveDirs[d] = true
+			dirTokens[d] += tok
+		}
+	}
+	// 2) каталоги: без масок — все под path, с масками — только с подходящими файлами
+	for i, it := range items {
+		if it.Type == "dir" && !it.Submodule && strings.HasPrefix(it.Path, prefix) {
+			keep[i] = tq.matcher == nil || liveDirs[it.Path]
+		}
+	}
+
+	// 3) глубина и страница
+	var out []treeItemOut
+	total := 0
+	for i, it := range items {
+		if !keep[i] {
+			continue
+		}
+		if tq.depth > 0 && strings.Count(it.Path[len(prefix):], "/") >= tq.depth {
+			continue
+		}
+		total++
+		if total <= tq.offset || (tq.limit > 0 && len(out) >= tq.limit) {
+			continue
+		}
+		o := treeItemOut{TreeItem: it}
+		if tq.tokens {
+			var tok int64
+			switch {
+			case it.Type == "dir":
+				tok = dirTokens[it.Path]
+			case it.Kind == filters.KindText:
+				tok = est.CountForSize(it.Size)
+			}
+			o.Tokens = &tok
+		}
+		out = append(out, o)
+	}
+	next := ""
+	if end := tq.offset + len(out); tq.limit > 0 && end < total {
+		next = strconv.Itoa(end)
+	}
+	if out == nil {
+		out = []treeItemOut{}
+	}
+	return out, total, next, nil
+}
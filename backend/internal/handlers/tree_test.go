@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/treecache"
+)
+
+func TestTreeHandler_ResolveRef(t *testing.T) {
+	const (
+		sha1 = "1111111111111111111111111111111111111111"
+		sha2 = "2222222222222222222222222222222222222222"
+	)
+	var (
+		calls   int
+		lastTag string
+		status  = http.StatusOK
+		body    = sha1
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		lastTag = r.Header.Get("If-None-Match")
+		if lastTag == `"e1"` && status == http.StatusOK && body == sha1 {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"e1"`)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cache := treecache.NewMemory(time.Hour, 100)
+	h := NewTreeHandler(&githubclient.Client{BaseURL: srv.URL, Doer: srv.Client()}, cache, time.Minute)
+	key := treecache.RefKey("", "o", "r", "main")
+	resolve := func(want string, wantCalls int) {
+		t.Helper()
+		got, err := h.resolveRef(ctx, "o", "r", "main")
+		if err != nil || got != want || calls != wantCalls {
+			t.Fatalf("resolveRef = %q, %v (calls %d), want %q (calls %d)", got, err, calls, want, wantCalls)
+		}
+	}
+	expire := func() {
+		e, _ := cache.GetRef(ctx, key)
+		e.CheckedAt = time.Now().Add(-time.Hour)
+		cache.SetRef(ctx, key, e)
+	}
+
+	// полный SHA — без запроса к GitHub
+	if got, err := h.resolveRef(ctx, "o", "r", sha2); err != nil || got != sha2 || calls != 0 {
+		t.Fatalf("commit sha: %q, %v (calls %d)", got, err, calls)
+	}
+
+	resolve(sha1, 1) // промах — идём к GitHub
+	resolve(sha1, 1) // свежая запись — из кэша
+
+	// устаревшая — ревалидация по ETag, 304 оставляет SHA и освежает запись
+	expire()
+	resolve(sha1, 2)
+	if lastTag != `"e1"` {
+		t.Fatalf("If-None-Match = %q", lastTag)
+	}
+	resolve(sha1, 2)
+
+	// ветка сдвинулась — новый SHA
+	expire()
+	body = sha2
+	resolve(sha2, 3)
+
+	// GitHub недоступен — отдаём закэшированный SHA
+	expire()
+	status = http.StatusInternalServerError
+	resolve(sha2, 4)
+
+	// ветку удалили — ошибка, а не устаревший SHA
+	status = http.StatusNotFound
+	if _, err := h.resolveRef(ctx, "o", "r", "main"); err != githubclient.ErrNotFound {
+		t.Fatalf("deleted ref: err = %v", err)
+	}
+}
@@ -3,8 +3,10 @@ package httpserver
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -186,7 +188,9 @@ func Logger() func(http.Handler) http.Handler {
 // CORS — отвечает на preflight-запросы (OPTIONS) и проставляет заголовки.
 // allowAll=true (для dev/test) — ставим Access-Control-Allow-Origin: *
 // allowAll=false (prod) — "отражаем" Origin (простой и безопасный вариант без списка).
-func CORS(allowAll bool) func(http.Handler) http.Handler {
+// Cookie сессии (Allow-Credentials) — только credentialOrigins, в любом окружении:
+// иначе любой сайт мог бы ходить в API от имени вошедшего пользователя.
+func CORS(allowAll bool, credentialOrigins ...string) func(http.Handler) http.Handler {
 	// Ограничиваем доступные методы и заголовки — минимально необходимое.
 	allowedMethods := "GET,POST,OPTIONS"
 	allowedHeaders := "Content-Type,Authorization"
@@ -194,14 +198,17 @@ func CORS(allowAll bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
-			if allowAll {
-				// Dev/Test: максимально просто.
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-			} else if origin != "" {
-				// Prod: отражаем конкретный Origin, а не "*".
+			if origin != "" {
+				// Отражаем конкретный Origin, а не "*": с "*" браузер не пошлёт cookie сессии.
 				// Также добавляем Vary, чтобы прокси/кэши понимали, что ответ зависит от Origin.
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Add("Vary", "Origin")
+				if slices.Contains(credentialOrigins, origin) {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			} else if allowAll {
+				// Dev/Test без Origin (curl и т.п.): максимально просто.
+				w.Header().Set("Access-Control-Allow-Origin", "*")
 			}
 
 			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
@@ -217,6 +224,23 @@ func CORS(allowAll bool) func(http.Handler) http.Handler {
 	}
 }
 
+// ========================= AdminOnly =========================
+//
+// AdminOnly — служебные эндпоинты только с Authorization: Bearer <token>.
+// Пустой token — эндпоинт закрыт совсем; чужим отвечаем 404, не выдавая его существование.
+func AdminOnly(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				httputil.WriteError(w, http.StatusNotFound, "not_found", "not found", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func ReqIDFromContext(ctx context.Context) string {
 	if v, ok := ctx.Value(ctxKeyRequestID).(string); ok {
 		return v
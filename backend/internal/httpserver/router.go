@@ -4,10 +4,12 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 	"os"
 	"github.com/yourname/cleanhttp/internal/artifacts"
+	"github.com/yourname/cleanhttp/internal/auth"
 	"github.com/yourname/cleanhttp/internal/config"
 	"github.com/yourname/cleanhttp/internal/githubclient"
 	"github.com/yourname/cleanhttp/internal/handlers"
@@ -15,9 +17,12 @@ import (
 	"github.com/yourname/cleanhttp/internal/jobs/asynqqueue"
 	"github.com/yourname/cleanhttp/internal/store"
 	"github.com/yourname/cleanhttp/internal/storepg"
+	"github.com/yourname/cleanhttp/internal/treecache"
 )
 
-func New(cfg config.Config) http.Handler {
+// New — роутер со всеми зависимостями. closeFn закрывает соединения, которые
+// роутер открыл сам (Redis кэша деревьев); вызывать после Shutdown сервера.
+func New(cfg config.Config) (h http.Handler, closeFn func()) {
 	mux := http.NewServeMux()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
@@ -82,6 +87,64 @@ func New(cfg config.Config) http.Handler {
 	}
 	var exportsStore store.ExportsStore = exportsMem
 
+	// пользовательские пресеты фильтров: PG, если есть, иначе в памяти процесса
+	var presetsStore store.PresetsStore = store.NewPresetsMem()
+	if pg, ok := repo.(*storepg.ExportsPG); ok {
+		presetsStore = pg.Presets()
+	}
+
+	// кэш деревьев: Redis — общий для всех реплик API, иначе LRU в памяти процесса
+	var treeCache treecache.TreeCache
+	closeFn = func() {}
+	switch cfg.TreeCacheBackend {
+	case "redis":
+		rc := treecache.NewRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.TreeCacheTTL)
+		treeCache = rc
+		closeFn = func() {
+			if err := rc.Close(); err != nil {
+				slog.Warn("tree cache redis close failed", slog.String("error", err.Error()))
+			}
+		}
+	default:
+		treeCache = treecache.NewMemory(cfg.TreeCacheTTL, cfg.TreeCacheMaxItems)
+	}
+	slog.Info("tree cache", slog.String("backend", cfg.TreeCacheBackend))
+
+	// квоты и хвосты токенов — только для мониторинга (ADMIN_TOKEN)
+	mux.Handle("/metrics", AdminOnly(cfg.AdminToken)(&handlers.GitHubMetricsHandler{GH: gh}))
+
+	// пользователи (GitHub OAuth): токены шифруем ключом AUTH_ENCRYPTION_KEY;
+	// без ключа сессий нет — все запросы анонимные (серверные токены)
+	var usersStore store.UsersStore = store.NewUsersMem()
+	if pg, ok := repo.(*storepg.ExportsPG); ok {
+		usersStore = pg.Users()
+	}
+	var sessions *auth.Sessions
+	if cfg.AuthEncryptionKey != "" {
+		key, err := auth.ParseKey(cfg.AuthEncryptionKey)
+		var cipher *auth.Cipher
+		if err == nil {
+			cipher, err = auth.NewCipher(key)
+		}
+		if err != nil {
+			slog.Error("auth init failed", slog.String("error", err.Error()))
+		} else {
+			sessions = &auth.Sessions{Cipher: cipher, TTL: cfg.SessionTTL, Secure: cfg.Env == config.EnvProd}
+		}
+	}
+	authHandler := &handlers.AuthHandler{Sessions: sessions, Users: usersStore, SuccessRedirect: cfg.AuthSuccessRedirect}
+	if sessions != nil && cfg.GitHubOAuthClientID != "" {
+		authHandler.OAuth = &auth.GitHubOAuth{
+			ClientID:     cfg.GitHubOAuthClientID,
+			ClientSecret: cfg.GitHubOAuthClientSecret,
+			RedirectURL:  cfg.GitHubOAuthRedirectURL,
+			Scopes:       auth.DefaultScopes,
+			WebURL:       "https://github.com",
+			APIURL:       "https://api.github.com",
+			Doer:         &http.Client{Timeout: 15 * time.Second},
+		}
+	}
+
 	// Asynq producer
 	asqClient := asynqqueue.NewClient()
 
@@ -92,19 +155,29 @@ func New(cfg config.Config) http.Handler {
 		httputil.WriteJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
+	api.Handle("/auth/", authHandler)
 	api.Handle("/repo/resolve", handlers.NewResolveHandler(gh))
-	api.Handle("/repo/tree", handlers.NewTreeHandler(gh, 3*time.Minute))
+	api.Handle("/github/ratelimit", AdminOnly(cfg.AdminToken)(&handlers.GitHubRateLimitHandler{GH: gh}))
+	treeHandler := handlers.NewTreeHandler(gh, treeCache, cfg.TreeRefTTL)
+	api.Handle("/repo/tree", treeHandler)
+	api.Handle("/repo/stats", &handlers.RepoStatsHandler{Tree: treeHandler})
+	api.Handle("/repo/compare", &handlers.CompareHandler{GH: gh})
 	api.Handle("/preview", handlers.NewPreviewHandler(gh))
 
 	api.Handle("/export", &handlers.ExportAsyncHandler{
 		Queue:   asqClient,
 		Exports: exportsStore,
+		Presets: presetsStore,
 		GH:      gh,
 		Logger:  logger.With(slog.String("component", "api_export")),
 	})
 
-	api.Handle("/artifacts/", http.StripPrefix("/artifacts", &handlers.ArtifactsListHandler{Store: artifactsStore}))
-	api.Handle("/download/", http.StripPrefix("/download", handlers.NewDownloadHandler(artifactsStore)))
+	presetsHandler := &handlers.FilterPresetsHandler{Presets: presetsStore}
+	api.Handle("/filter-presets", presetsHandler)
+	api.Handle("/filter-presets/", presetsHandler)
+
+	api.Handle("/artifacts/", http.StripPrefix("/artifacts", &handlers.ArtifactsListHandler{Store: artifactsStore, Exports: exportsStore}))
+	api.Handle("/download/", http.StripPrefix("/download", handlers.NewDownloadHandler(artifactsStore, exportsStore)))
 
 	api.Handle("/jobs/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
@@ -117,14 +190,28 @@ func New(cfg config.Config) http.Handler {
 		}
 	}))
 
-	mux.Handle("/api/", http.StripPrefix("/api", api))
+	// сессия → пользователь и его GitHub-токен в контексте всех /api-хендлеров
+	apiHandler := http.Handler(api)
+	if sessions != nil {
+		apiHandler = auth.Middleware(sessions, usersStore)(api)
+	}
+	mux.Handle("/api/", http.StripPrefix("/api", apiHandler))
 
-	h := http.Handler(mux)
+	h = http.Handler(mux)
 	h = RequestID()(h)
 	h = Recoverer()(h)
 	h = Timeout(cfg.RequestTimeout)(h)
 	h = Logger()(h)
-	h = CORS(cfg.Env != config.EnvProd)(h)
+	h = CORS(cfg.Env != config.EnvProd, originOf(cfg.AuthSuccessRedirect))(h)
 
-	return h
+	return h, closeFn
+}
+
+// originOf — scheme://host абсолютного URL ("" — относительный или битый).
+func originOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
 }
@@ -20,7 +20,14 @@ const openapiJSON = `{
     "parameters": [
       { "name": "owner", "in": "query", "required": true,  "schema": {"type":"string"} },
       { "name": "repo",  "in": "query", "required": true,  "schema": {"type":"string"} },
-      { "name": "ref",   "in": "query", "required": false, "schema": {"type":"string"}, "description":"ветка/тег/коммит; по умолчанию main" }
+      { "name": "ref",   "in": "query", "required": false, "schema": {"type":"string"}, "description":"ветка/тег/коммит; по умолчанию main" },
+      { "name": "path",  "in": "query", "required": false, "schema": {"type":"string"}, "description":"только поддерево каталога" },
+      { "name": "depth", "in": "query", "required": false, "schema": {"type":"integer","minimum":0}, "description":"глубина относительно path; 0 — без ограничения" },
+      { "name": "include", "in": "query", "required": false, "schema": {"type":"array","items":{"type":"string"}}, "explode": true, "description":"маски .gitignore; каталоги остаются, если в них есть подходящие файлы" },
+      { "name": "exclude", "in": "query", "required": false, "schema": {"type":"array","items":{"type":"string"}}, "explode": true },
+      { "name": "limit", "in": "query", "required": false, "schema": {"type":"integer","minimum":1,"maximum":5000} },
+      { "name": "cursor", "in": "query", "required": false, "schema": {"type":"string"}, "description":"nextCursor из предыдущей страницы" },
+      { "name": "includeTokens", "in": "query", "required": false, "schema": {"type":"boolean"} }
     ],
     "responses": {
       "200": {
@@ -34,16 +41,22 @@ const openapiJSON = `{
                   "type":"array",
                   "items": {
                     "type":"object",
-                    "required":["path","type","size","lfs","submodule"],
+                    "required":["path","type","size","kind","lfs","submodule"],
                     "properties":{
                       "path":{"type":"string","example":"cmd/api/main.go"},
                       "type":{"type":"string","enum":["file","dir"]},
                       "size":{"type":"integer","format":"int64"},
-                      "lfs":{"type":"boolean"},
-                      "submodule":{"type":"boolean"}
+                      "kind":{"type":"string","enum":["text","binary","image","archive","lfs-pointer",""],"description":"вид файла по имени; lfs-pointer — по содержимому blob'а; у папок пусто"},
+                      "lfs":{"type":"boolean","deprecated":true,"description":"то же, что kind == lfs-pointer"},
+                      "submodule":{"type":"boolean"},
+                      "sha":{"type":"string","description":"для сабмодуля — закреплённый коммит"},
+                      "tokens":{"type":"integer","format":"int64","description":"только с includeTokens; у каталогов — сумма"}
                     }
                   }
-                }
+                },
+                "total": {"type":"integer"},
+                "truncated": {"type":"boolean"},
+                "nextCursor": {"type":"string"}
               }
             }
           }
@@ -56,7 +69,208 @@ const openapiJSON = `{
       "500": { "$ref": "#/components/responses/InternalError" }
     }
   }
-}
+},
+    "/api/repo/stats": {
+      "get": {
+        "summary": "Статистика репозитория по дереву: языки, каталоги, виды файлов, большие файлы",
+        "parameters": [
+          { "name": "owner",   "in": "query", "required": true,  "schema": {"type":"string"} },
+          { "name": "repo",    "in": "query", "required": true,  "schema": {"type":"string"} },
+          { "name": "ref",     "in": "query", "required": false, "schema": {"type":"string"}, "description":"по умолчанию main" },
+          { "name": "largest", "in": "query", "required": false, "schema": {"type":"integer","minimum":1,"maximum":100,"default":10} }
+        ],
+        "responses": {
+          "200": {
+            "description": "Ок",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "owner": {"type":"string"},
+                    "repo": {"type":"string"},
+                    "ref": {"type":"string"},
+                    "files": {"type":"integer"},
+                    "dirs": {"type":"integer"},
+                    "submodules": {"type":"integer"},
+                    "sizeBytes": {"type":"integer","format":"int64"},
+                    "tokens": {"type":"integer","format":"int64","description":"оценка для текстовых файлов"},
+                    "languages": {"type":"array","items":{"$ref":"#/components/schemas/StatsBucket"}},
+                    "topDirs": {"type":"array","items":{"$ref":"#/components/schemas/StatsBucket"}},
+                    "kinds": {"type":"array","items":{"$ref":"#/components/schemas/StatsBucket"}},
+                    "largestFiles": {
+                      "type":"array",
+                      "items": {
+                        "type":"object",
+                        "properties": {
+                          "path": {"type":"string"},
+                          "size": {"type":"integer","format":"int64"},
+                          "kind": {"type":"string"}
+                        }
+                      }
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "404": { "$ref": "#/components/responses/NotFound" },
+          "429": { "$ref": "#/components/responses/RateLimited" },
+          "502": { "$ref": "#/components/responses/UpstreamError" },
+          "500": { "$ref": "#/components/responses/InternalError" }
+        }
+      }
+    },
+    "/api/repo/compare": {
+      "get": {
+        "summary": "Изменённые файлы между двумя ref'ами (compare API GitHub): статус и число строк",
+        "parameters": [
+          { "name": "owner", "in": "query", "required": true,  "schema": {"type":"string"} },
+          { "name": "repo",  "in": "query", "required": true,  "schema": {"type":"string"} },
+          { "name": "base",  "in": "query", "required": true,  "schema": {"type":"string"}, "description":"ветка, тег или SHA" },
+          { "name": "head",  "in": "query", "required": true,  "schema": {"type":"string"}, "description":"ветка, тег или SHA" },
+          { "name": "patch", "in": "query", "required": false, "schema": {"type":"boolean","default":false}, "description":"включить unified diff каждого файла" }
+        ],
+        "responses": {
+          "200": {
+            "description": "Ок",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "owner": {"type":"string"},
+                    "repo": {"type":"string"},
+                    "base": {"type":"string"},
+                    "head": {"type":"string"},
+                    "status": {"type":"string","enum":["ahead","behind","diverged","identical"]},
+                    "aheadBy": {"type":"integer"},
+                    "behindBy": {"type":"integer"},
+                    "totalCommits": {"type":"integer"},
+                    "mergeBase": {"type":"string"},
+                    "additions": {"type":"integer"},
+                    "deletions": {"type":"integer"},
+                    "truncated": {"type":"boolean","description":"GitHub отдаёт не больше 300 файлов"},
+                    "files": {
+                      "type":"array",
+                      "items": {
+                        "type":"object",
+                        "properties": {
+                          "path": {"type":"string"},
+                          "previousPath": {"type":"string"},
+                          "status": {"type":"string","enum":["added","removed","modified","renamed","copied","changed","unchanged"]},
+                          "additions": {"type":"integer"},
+                          "deletions": {"type":"integer"},
+                          "patch": {"type":"string"}
+                        }
+                      }
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "404": { "$ref": "#/components/responses/NotFound" },
+          "429": { "$ref": "#/components/responses/RateLimited" },
+          "502": { "$ref": "#/components/responses/UpstreamError" },
+          "500": { "$ref": "#/components/responses/InternalError" }
+        }
+      }
+    },
+    "/api/auth/github/login": {
+      "get": {
+        "summary": "Вход через GitHub OAuth: редирект на github.com",
+        "responses": {
+          "302": { "description": "Редирект на страницу согласия GitHub" },
+          "503": { "description": "OAuth не настроен" }
+        }
+      }
+    },
+    "/api/auth/github/callback": {
+      "get": {
+        "summary": "Callback OAuth: токен шифруется и сохраняется, ставится cookie r2p_session",
+        "parameters": [
+          { "name": "code",  "in": "query", "required": true, "schema": {"type":"string"} },
+          { "name": "state", "in": "query", "required": true, "schema": {"type":"string"} }
+        ],
+        "responses": {
+          "302": { "description": "Редирект в UI (AUTH_SUCCESS_REDIRECT)" },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/api/auth/me": {
+      "get": {
+        "summary": "Текущий пользователь по cookie сессии",
+        "responses": {
+          "200": {
+            "description": "Ок",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "id": {"type":"string","example":"gh:583231"},
+                    "login": {"type":"string"},
+                    "name": {"type":"string"},
+                    "email": {"type":"string"},
+                    "avatarUrl": {"type":"string"}
+                  }
+                }
+              }
+            }
+          },
+          "401": { "description": "Не выполнен вход" }
+        }
+      }
+    },
+    "/api/auth/logout": {
+      "post": {
+        "summary": "Выйти (удалить cookie сессии)",
+        "responses": { "200": { "description": "Ок" } }
+      }
+    },
+    "/api/github/ratelimit": {
+      "get": {
+        "summary": "Остаток квоты GitHub API по токенам пула",
+        "parameters": [
+          { "name": "refresh", "in": "query", "required": false, "schema": {"type":"boolean"}, "description":"опросить /rate_limit для токенов без данных" }
+        ],
+        "responses": {
+          "200": {
+            "description": "Ок",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "remaining": {"type":"integer"},
+                    "limit": {"type":"integer"},
+                    "exhausted": {"type":"integer","description":"токенов в резерве до сброса"},
+                    "tokens": {
+                      "type":"array",
+                      "items": {
+                        "type":"object",
+                        "properties": {
+                          "id": {"type":"string","example":"…a1b2"},
+                          "known": {"type":"boolean"},
+                          "limit": {"type":"integer"},
+                          "remaining": {"type":"integer"},
+                          "reset": {"type":"string","format":"date-time"},
+                          "exhausted": {"type":"boolean"}
+                        }
+                      }
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
     "/api/repo/resolve": {
       "post": {
         "summary": "Разобрать GitHub URL и получить default_branch",
@@ -68,7 +282,7 @@ const openapiJSON = `{
                 "type": "object",
                 "required": ["url"],
                 "properties": {
-                  "url": { "type": "string", "example": "https://github.com/vercel/next.js" }
+                  "url": { "type": "string", "example": "https://github.com/vercel/next.js/tree/canary/packages/next", "description": "owner/repo, tree/blob/commit/pull-ссылки, git@, ssh://, git://" }
                 }
               }
             }
@@ -86,7 +300,18 @@ const openapiJSON = `{
                     "owner": { "type": "string", "example": "vercel" },
                     "repo": { "type": "string", "example": "next.js" },
                     "defaultRef": { "type": "string", "example": "main" },
-                    "refs": { "type": "array", "items": { "type": "string" } }
+                    "refs": { "type": "array", "items": { "type": "string" } },
+                    "repoRef": {
+                      "type": "object",
+                      "description": "что указано в ссылке: ref (ветка может содержать слэши), путь поддерева, номер PR",
+                      "properties": {
+                        "owner": { "type": "string" },
+                        "repo": { "type": "string" },
+                        "ref": { "type": "string", "example": "canary" },
+                        "path": { "type": "string", "example": "packages/next" },
+                        "pr": { "type": "integer" }
+                      }
+                    }
                   }
                 }
               }
@@ -145,6 +370,16 @@ const openapiJSON = `{
       }
     },
     "schemas": {
+      "StatsBucket": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string", "example": "Go" },
+          "files": { "type": "integer" },
+          "bytes": { "type": "integer", "format": "int64" },
+          "tokens": { "type": "integer", "format": "int64" },
+          "percentage": { "type": "number", "example": 57.69 }
+        }
+      },
       "Error": {
         "type": "object",
         "required": ["error"],
@@ -0,0 +1,146 @@
+// Package repostats — сводка по репозиторию из дерева GitHub (без скачивания файлов):
+// языки, каталоги верхнего уровня, виды файлов, самые большие файлы.
+package repostats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/githubclient"
+	"github.com/yourname/cleanhttp/internal/tokenest"
+)
+
+// RootDir — «каталог» для файлов в корне репозитория.
+const RootDir = "/"
+
+// Bucket — счётчики одной группы (язык, каталог, вид).
+type Bucket struct {
+	Name       string  `json:"name"`
+	Files      int     `json:"files"`
+	Bytes      int64   `json:"bytes"`
+	Tokens     int64   `json:"tokens"`     // оценка; бинарные и LFS не считаются
+	Percentage float64 `json:"percentage"` // доля байтов, 0..100 (у языков — от текстовых файлов)
+}
+
+// LargeFile — кандидат в «самые большие файлы».
+type LargeFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Kind string `json:"kind"`
+}
+
+// Stats — ответ /api/repo/stats.
+type Stats struct {
+	Files        int         `json:"files"`
+	Dirs         int         `json:"dirs"`
+	Submodules   int         `json:"submodules"`
+	SizeBytes    int64       `json:"sizeBytes"`
+	Tokens       int64       `json:"tokens"` // оценка для текстовых файлов
+	Languages    []Bucket    `json:"languages"`
+	TopDirs      []Bucket    `json:"topDirs"`
+	Kinds        []Bucket    `json:"kinds"`
+	LargestFiles []LargeFile `json:"largestFiles"`
+}
+
+// DefaultLargest — сколько самых больших файлов отдаём по умолчанию.
+const DefaultLargest = 10
+
+// Compute — считаем статистику по элементам дерева. largest — размер топа больших файлов.
+func Compute(items []githubclient.TreeItem, largest int) Stats {
+	if largest <= 0 {
+		largest = DefaultLargest
+	}
+	est := tokenest.NewEstimator()
+	var (
+		st    Stats
+		langs = map[string]*Bucket{}
+		dirs  = map[string]*Bucket{}
+		kinds = map[string]*Bucket{}
+		files []LargeFile
+		text  int64 // байты текстовых файлов — база для долей языков
+	)
+	add := func(m map[string]*Bucket, name string, size, tokens int64) {
+		b, ok := m[name]
+		if !ok {
+			b = &Bucket{Name: name}
+			m[name] = b
+		}
+		b.Files++
+		b.Bytes += size
+		b.Tokens += tokens
+	}
+
+	for _, it := range items {
+		switch {
+		case it.Submodule:
+			st.Submodules++
+			continue
+		case it.Type == "dir":
+			st.Dirs++
+			continue
+		}
+		kind := it.Kind
+		if kind == "" {
+			kind = filters.KindByName(it.Path)
+		}
+		var tokens int64
+		if kind == filters.KindText {
+			tokens = est.CountForSize(it.Size)
+		}
+		st.Files++
+		st.SizeBytes += it.Size
+		st.Tokens += tokens
+
+		add(kinds, kind, it.Size, tokens)
+		// языки — только для текста: картинки и архивы «языком» не считаем
+		if kind == filters.KindText {
+			text += it.Size
+			add(langs, filters.LanguageByPath(it.Path), it.Size, tokens)
+		}
+		top := RootDir
+		if i := strings.IndexByte(it.Path, '/'); i > 0 {
+			top = it.Path[:i]
+		}
+		add(dirs, top, it.Size, tokens)
+		files = append(files, LargeFile{Path: it.Path, Size: it.Size, Kind: kind})
+	}
+
+	st.Languages = sortedBuckets(langs, text)
+	st.TopDirs = sortedBuckets(dirs, st.SizeBytes)
+	st.Kinds = sortedBuckets(kinds, st.SizeBytes)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > largest {
+		files = files[:largest]
+	}
+	st.LargestFiles = files
+	if st.LargestFiles == nil {
+		st.LargestFiles = []LargeFile{}
+	}
+	return st
+}
+
+// sortedBuckets — по убыванию байтов (при равенстве — по имени), с долями от total.
+func sortedBuckets(m map[string]*Bucket, total int64) []Bucket {
+	out := make([]Bucket, 0, len(m))
+	for _, b := range m {
+		if total > 0 {
+			// округляем до сотых процента
+			b.Percentage = float64(b.Bytes*10000/total) / 100
+		}
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
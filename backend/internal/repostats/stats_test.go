@@ -0,0 +1,44 @@
+package repostats
+
+import (
+	"testing"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func TestCompute(t *testing.T) {
+	items := []githubclient.TreeItem{
+		{Path: "cmd", Type: "dir"},
+		{Path: "vendor/lib", Type: "dir", Submodule: true},
+		{Path: "cmd/main.go", Type: "file", Size: 4000},
+		{Path: "cmd/util.go", Type: "file", Size: 2000},
+		{Path: "web/app.ts", Type: "file", Size: 4000},
+		{Path: "README.md", Type: "file", Size: 400},
+		{Path: "assets/logo.png", Type: "file", Size: 90000},
+		{Path: "assets/big.psd", Type: "file", Size: 130, Kind: "lfs-pointer"}, // дерево проверило содержимое
+	}
+	st := Compute(items, 2)
+
+	if st.Files != 6 || st.Dirs != 1 || st.Submodules != 1 {
+		t.Fatalf("files/dirs/submodules = %d/%d/%d", st.Files, st.Dirs, st.Submodules)
+	}
+	if st.Tokens != (4000+2000+4000+400)/4 {
+		t.Errorf("tokens = %d", st.Tokens)
+	}
+	if got := st.Languages[0]; got.Name != "Go" || got.Files != 2 || got.Bytes != 6000 || got.Percentage != 57.69 {
+		t.Errorf("languages[0] = %+v", got)
+	}
+	if got := st.TopDirs[0]; got.Name != "assets" || got.Tokens != 0 {
+		t.Errorf("topDirs[0] = %+v", got)
+	}
+	kinds := map[string]int{}
+	for _, k := range st.Kinds {
+		kinds[k.Name] = k.Files
+	}
+	if kinds["text"] != 4 || kinds["image"] != 1 || kinds["lfs-pointer"] != 1 {
+		t.Errorf("kinds = %v", kinds)
+	}
+	if len(st.LargestFiles) != 2 || st.LargestFiles[0].Path != "assets/logo.png" || st.LargestFiles[1].Path != "cmd/main.go" {
+		t.Errorf("largest = %+v", st.LargestFiles)
+	}
+}
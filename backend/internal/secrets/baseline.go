@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Маркеры inline-исключений: строка с таким комментарием не маскируется.
+//   API_KEY = "..." // rep2prompt:allow
+// gitleaks:allow поддерживаем для совместимости с уже размеченными репозиториями.
+var allowMarkers = []string{"rep2prompt:allow", "gitleaks:allow"}
+
+func hasAllowComment(line string) bool {
+	for _, m := range allowMarkers {
+		if strings.Contains(line, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Baseline — набор отпечатков принятых (известных) находок.
+type Baseline map[string]struct{}
+
+// Fingerprint — отпечаток находки: sha256(path + "\x00" + rule + "\x00" + value), hex.
+// Номер строки не входит — отпечаток переживает сдвиги кода.
+func Fingerprint(f Finding) string {
+	h := sha256.New()
+	io.WriteString(h, f.Path)
+	h.Write([]byte{0})
+	io.WriteString(h, f.RuleID)
+	h.Write([]byte{0})
+	io.WriteString(h, f.Value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ParseBaseline — файл baseline: по одному отпечатку на строку,
+// пустые строки и комментарии (#) игнорируются. Всё после отпечатка
+// (через пробел) считается пояснением.
+func ParseBaseline(r io.Reader) (Baseline, error) {
+	b := Baseline{}
+	sc := bufio.NewScanner(r)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fp := strings.ToLower(strings.Fields(line)[0])
+		if len(fp) != sha256.Size*2 {
+			return nil, fmt.Errorf("baseline line %d: bad fingerprint %q", n, fp)
+		}
+		if _, err := hex.DecodeString(fp); err != nil {
+			return nil, fmt.Errorf("baseline line %d: bad fingerprint %q", n, fp)
+		}
+		b[fp] = struct{}{}
+	}
+	return b, sc.Err()
+}
+
+// Has — находка уже принята в baseline.
+func (b Baseline) Has(f Finding) bool {
+	if len(b) == 0 {
+		return false
+	}
+	_, ok := b[Fingerprint(f)]
+	return ok
+}
@@ -0,0 +1,412 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// MaxBlobBytes — файлы больше этого размера структурно не разбираем
+// (конфиги крупнее 1 МБ почти всегда сгенерированы), хватает построчного скана.
+const MaxBlobBytes = 1 << 20
+
+// Format — формат структурированного файла для ScanBlob.
+type Format string
+
+const (
+	FormatNone       Format = ""
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatTOML       Format = "toml"
+	FormatProperties Format = "properties"
+	FormatEnv        Format = "env"
+)
+
+// DetectFormat — формат по имени файла; FormatNone, если разбирать структурно нечего.
+func DetectFormat(p string) Format {
+	base := strings.ToLower(path.Base(p))
+	if base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env") {
+		return FormatEnv
+	}
+	switch path.Ext(base) {
+	case ".json":
+		return FormatJSON
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".properties":
+		return FormatProperties
+	}
+	return FormatNone
+}
+
+// IsSecretKey — та же эвристика, что и для ENV в prompt pack: имя ключа
+// содержит SECRET/TOKEN/PASSWORD/API/KEY/PWD/PRIVATE.
+func IsSecretKey(name string) bool {
+	u := strings.ToUpper(name)
+	return strings.Contains(u, "SECRET") || strings.Contains(u, "TOKEN") || strings.Contains(u, "PASSWORD") || strings.Contains(u, "API") || strings.Contains(u, "KEY") || strings.Contains(u, "PWD") || strings.Contains(u, "PRIVATE")
+}
+
+// structuredRuleID — RuleID находок по ключам структурированных конфигов.
+const structuredRuleID = "structured_key"
+
+// kv — пара ключ/значение с координатами значения в исходной строке.
+type kv struct {
+	key    string
+	value  string
+	lineNo int // 1-based
+	start  int // байтовое смещение значения в строке
+}
+
+// ScanBlob — скан файла целиком. Для JSON/YAML/TOML/.properties/.env значения
+// под «секретными» ключами помечаются даже без совпадения с регулярками вендоров.
+// Все находки привязаны к строкам (LineNo/Span), поэтому построчный
+// ApplyStrategy продолжает работать — см. GroupByLine.
+// nil — файл не сканировался (пустой, больше MaxBlobBytes или с NUL-байтами):
+// его нужно сканировать построчно; просканированный без находок — пустой срез.
+func (s *Scanner) ScanBlob(p string, b []byte) []Finding {
+	if len(b) == 0 || len(b) > MaxBlobBytes || bytes.IndexByte(b, 0) >= 0 {
+		return nil
+	}
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	// 1) обычный построчный скан
+	var finds, held []Finding
+	for i, line := range lines {
+		f, h := s.scanLine(p, line, i+1)
+		finds = append(finds, f...)
+		held = append(held, h...)
+	}
+	if finds == nil {
+		finds = []Finding{}
+	}
+	if s.cfg.NoSecrets {
+		s.account(finds, held)
+		return finds
+	}
+
+	// 2) структурный разбор
+	var pairs []kv
+	switch DetectFormat(p) {
+	case FormatJSON:
+		pairs = jsonPairs(text)
+	case FormatYAML:
+		pairs = yamlPairs(lines)
+	case FormatTOML:
+		pairs = tomlPairs(lines)
+	case FormatProperties, FormatEnv:
+		pairs = propertiesPairs(lines)
+	}
+	for _, pr := range pairs {
+		if !IsSecretKey(pr.key) || isNonSecretKey(pr.key) || !looksLikeSecretValue(pr.value) {
+			continue
+		}
+		kind, sev := kindForKey(pr.key)
+		f := Finding{
+			Path:     p,
+			LineNo:   pr.lineNo,
+			Kind:     kind,
+			RuleID:   structuredRuleID,
+			Span:     Span{Start: pr.start, End: pr.start + len(pr.value)},
+			Severity: sev,
+			Note:     "Value under secret-looking key: " + pr.key,
+			Value:    pr.value,
+		}
+		if coveredBy(f, finds) || coveredBy(f, held) {
+			continue // уже нашли (или подавили) регуляркой на этой строке
+		}
+		if s.suppressed(f, lines[pr.lineNo-1]) {
+			held = append(held, f)
+			continue
+		}
+		if isFixturePath(p) {
+			f.Severity = downgrade(f.Severity)
+		}
+		finds = append(finds, f)
+		a := s.agg(p)
+		a.seen[f.RuleID] = true
+		a.addStructured(kind, sev)
+		a.fps = append(a.fps, Fingerprint(f))
+	}
+	s.account(finds, held)
+	return finds
+}
+
+// GroupByLine — раскладывает находки ScanBlob по номерам строк.
+// nil (ScanBlob файл не сканировал) остаётся nil — вызывающий сканирует построчно.
+func GroupByLine(finds []Finding) map[int][]Finding {
+	if finds == nil {
+		return nil
+	}
+	out := make(map[int][]Finding, len(finds))
+	for _, f := range finds {
+		out[f.LineNo] = append(out[f.LineNo], f)
+	}
+	return out
+}
+
+func coveredBy(f Finding, finds []Finding) bool {
+	for _, g := range finds {
+		if g.LineNo == f.LineNo && g.Span.Start < f.Span.End && f.Span.Start < g.Span.End {
+			return true
+		}
+	}
+	return false
+}
+
+// isNonSecretKey — ключи, которые формально попадают под эвристику, но секретов
+// не содержат (apiVersion в k8s, пути к файлам ключей, имена переменных и т.п.).
+func isNonSecretKey(key string) bool {
+	u := strings.ToUpper(key)
+	if u == "APIVERSION" || u == "API_VERSION" {
+		return true
+	}
+	for _, suf := range []string{"URL", "URI", "HOST", "PATH", "FILE", "VERSION", "NAME", "ENDPOINT", "TYPE", "ENV", "VAR", "HEADER", "ID", "ALGORITHM", "LENGTH"} {
+		if strings.HasSuffix(u, suf) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeSecretValue — отсекаем пустые значения, ссылки на переменные и шаблоны.
+func looksLikeSecretValue(v string) bool {
+	v = strings.TrimSpace(v)
+	if len(v) < 4 {
+		return false
+	}
+	lower := strings.ToLower(v)
+	switch lower {
+	case "true", "false", "null", "none", "changeme", "change-me", "secret", "password":
+		return false
+	}
+	if strings.HasPrefix(v, "${") || strings.HasPrefix(v, "{{") || strings.HasPrefix(v, "%(") || strings.HasPrefix(v, "$(") ||
+		(strings.HasPrefix(v, "<") && strings.HasSuffix(v, ">")) {
+		return false
+	}
+	// ссылка на другую переменную: PASSWORD=$DB_PASSWORD
+	if strings.HasPrefix(v, "$") && isUpperIdent(v[1:]) {
+		return false
+	}
+	return true
+}
+
+func isUpperIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !((r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// kindForKey — тип и серьёзность находки по имени ключа; серьёзность та же,
+// что у соответствующих регулярок (password_code, pem_key, env_pair).
+func kindForKey(key string) (Kind, Severity) {
+	u := strings.ToUpper(key)
+	switch {
+	case strings.Contains(u, "PASSWORD") || strings.Contains(u, "PWD"):
+		return KindPassword, SevMed
+	case strings.Contains(u, "PRIVATE"):
+		return KindPrivateKey, SevHigh
+	default:
+		return KindEnvValue, SevMed
+	}
+}
+
+// ===== парсеры (MVP: только скалярные значения в одну строку) =====
+
+// propertiesPairs — .env и .properties: KEY=value, KEY: value, export KEY=value.
+func propertiesPairs(lines []string) []kv {
+	var out []kv
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		off := len(line) - len(trimmed)
+		if strings.HasPrefix(trimmed, "export ") {
+			off += len("export ")
+			trimmed = trimmed[len("export "):]
+		}
+		sep := strings.IndexAny(trimmed, "=:")
+		if sep <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(trimmed[:sep])
+		if v, start, ok := scalarAt(line, off+sep+1); ok {
+			out = append(out, kv{key: key, value: v, lineNo: i + 1, start: start})
+		}
+	}
+	return out
+}
+
+// yamlPairs — "key: value" и "- key: value"; блочные скаляры (|, >) пропускаем.
+func yamlPairs(lines []string) []kv {
+	var out []kv
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || strings.HasPrefix(trimmed, "---") {
+			continue
+		}
+		off := len(line) - len(trimmed)
+		if strings.HasPrefix(trimmed, "- ") {
+			off += 2
+			trimmed = trimmed[2:]
+		}
+		sep := strings.Index(trimmed, ":")
+		if sep <= 0 || (sep+1 < len(trimmed) && trimmed[sep+1] != ' ' && trimmed[sep+1] != '\t') {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(trimmed[:sep]), `"'`)
+		v, start, ok := scalarAt(line, off+sep+1)
+		if !ok || strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">") || strings.HasPrefix(v, "&") || strings.HasPrefix(v, "*") {
+			continue
+		}
+		out = append(out, kv{key: key, value: v, lineNo: i + 1, start: start})
+	}
+	return out
+}
+
+// tomlPairs — key = "value" (секции [..] и многострочные строки пропускаем).
+func tomlPairs(lines []string) []kv {
+	var out []kv
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '[' {
+			continue
+		}
+		off := len(line) - len(trimmed)
+		sep := strings.Index(trimmed, "=")
+		if sep <= 0 {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(trimmed[:sep]), `"'`)
+		if j := strings.LastIndexByte(key, '.'); j >= 0 {
+			key = key[j+1:] // dotted keys: db.password = "..."
+		}
+		rest := strings.TrimLeft(line[off+sep+1:], " \t")
+		if strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`) {
+			continue
+		}
+		if v, start, ok := scalarAt(line, off+sep+1); ok {
+			out = append(out, kv{key: key, value: v, lineNo: i + 1, start: start})
+		}
+	}
+	return out
+}
+
+// scalarAt — значение, начинающееся в line[from:]: снимает пробелы, кавычки
+// и хвостовой комментарий. Возвращает значение и его смещение в строке.
+func scalarAt(line string, from int) (string, int, bool) {
+	if from > len(line) {
+		return "", 0, false
+	}
+	rest := line[from:]
+	lead := len(rest) - len(strings.TrimLeft(rest, " \t"))
+	start := from + lead
+	v := line[start:]
+	if v == "" {
+		return "", 0, false
+	}
+	if q := v[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(v[1:], q)
+		if end < 0 {
+			return "", 0, false
+		}
+		return v[1 : 1+end], start + 1, true
+	}
+	if j := strings.Index(v, " #"); j >= 0 {
+		v = v[:j]
+	}
+	v = strings.TrimRight(v, " \t,")
+	if v == "" {
+		return "", 0, false
+	}
+	return v, start, true
+}
+
+// jsonPairs — обходит JSON токенами и находит строковые значения с их позицией.
+func jsonPairs(text string) []kv {
+	dec := json.NewDecoder(strings.NewReader(text))
+	var (
+		out   []kv
+		stack []bool // true — внутри объекта
+		key   string
+		isKey = false
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil { // EOF или битый JSON — берём, что успели
+			return out
+		}
+		inObject := len(stack) > 0 && stack[len(stack)-1]
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, true)
+				isKey = true
+			case '[':
+				stack = append(stack, false)
+			case '}', ']':
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				isKey = len(stack) > 0 && stack[len(stack)-1]
+			}
+			continue
+		case string:
+			if inObject && isKey {
+				key = t
+				isKey = false
+				continue
+			}
+			if inObject {
+				end := int(dec.InputOffset()) - 1 // позиция закрывающей кавычки
+				if start := openingQuote(text, end); start >= 0 {
+					lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+					out = append(out, kv{
+						key:    key,
+						value:  text[start+1 : end],
+						lineNo: strings.Count(text[:start], "\n") + 1,
+						start:  start + 1 - lineStart,
+					})
+				}
+			}
+		}
+		if inObject {
+			isKey = true
+		}
+	}
+}
+
+// openingQuote — ищет открывающую кавычку JSON-строки, закрывающейся в end.
+func openingQuote(text string, end int) int {
+	if end <= 0 || end >= len(text) || text[end] != '"' {
+		return -1
+	}
+	for i := end - 1; i >= 0; i-- {
+		if text[i] == '\n' {
+			return -1
+		}
+		if text[i] != '"' {
+			continue
+		}
+		// кавычка экранирована, если перед ней нечётное число '\'
+		bs := 0
+		for j := i - 1; j >= 0 && text[j] == '\\'; j-- {
+			bs++
+		}
+		if bs%2 == 0 {
+			return i
+		}
+	}
+	return -1
+}
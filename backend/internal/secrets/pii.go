@@ -0,0 +1,171 @@
+package secrets
+
+import (
+	"math/big"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compilePIIRules — персональные данные (включаются отдельно через Config.PII).
+// Регулярки намеренно широкие: окончательное решение принимает piiValidators
+// (для номеров — ещё и с отсечением хвоста, см. piiValidLen).
+func compilePIIRules() []rule {
+	return []rule{
+		{"pii_email", KindEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`), SevMed, "Email address"},
+		{"pii_ipv4", KindIP, regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), SevLow, "IPv4 address"},
+		{"pii_ipv6", KindIP, regexp.MustCompile(`(?i)\b[0-9a-f]{1,4}(?:::?[0-9a-f]{1,4}){1,7}\b`), SevLow, "IPv6 address"},
+		{"pii_phone", KindPhone, regexp.MustCompile(`\+[1-9](?:[ -]?\d){7,14}\b`), SevMed, "Phone number (E.164)"},
+		{"pii_pan", KindCardNumber, regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), SevHigh, "Payment card number (Luhn)"},
+		{"pii_iban", KindIBAN, regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`), SevMed, "IBAN"},
+	}
+}
+
+// piiValidators — дополнительная проверка совпадения (контрольные суммы и т.п.).
+var piiValidators = map[string]func(string) bool{
+	"pii_email": validEmail,
+	"pii_ipv4":  validIPv4,
+	"pii_ipv6":  validIPv6,
+	"pii_phone": validPhone,
+	"pii_pan":   validPAN,
+	"pii_iban":  validIBAN,
+}
+
+// piiTrimmable — правила, чей жадный повтор может захватить соседний токен
+// ("4111 1111 1111 1111 12", "GB82 WEST … 32 EUR"): проверяем и укороченные варианты.
+var piiTrimmable = map[string]bool{
+	"pii_pan":  true,
+	"pii_iban": true,
+}
+
+// piiValidLen — длина валидной части совпадения (0 — не PII). Для piiTrimmable
+// перебираем префиксы, обрывающиеся перед разделителем (пробел/дефис), от длинных к коротким.
+func piiValidLen(id, value string) int {
+	valid, ok := piiValidators[id]
+	if !ok || valid(value) {
+		return len(value)
+	}
+	if !piiTrimmable[id] {
+		return 0
+	}
+	for i := len(value) - 1; i > 0; i-- {
+		if value[i] != ' ' && value[i] != '-' {
+			continue
+		}
+		prefix := strings.TrimRight(value[:i], " -")
+		if valid(prefix) {
+			return len(prefix)
+		}
+	}
+	return 0
+}
+
+// IsPIIKind — относится ли тип к семейству PII (а не к секретам).
+func IsPIIKind(k Kind) bool {
+	switch k {
+	case KindEmail, KindIP, KindPhone, KindCardNumber, KindIBAN:
+		return true
+	}
+	return false
+}
+
+func validEmail(s string) bool {
+	// git-адреса вида git@github.com:owner/repo и noreply-адреса — не PII
+	lower := strings.ToLower(s)
+	return !strings.HasPrefix(lower, "git@") && !strings.Contains(lower, "noreply")
+}
+
+func validIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() == nil {
+		return false
+	}
+	// служебные адреса в конфигах — не персональные данные
+	return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.Equal(net.IPv4bcast)
+}
+
+// validIPv6 — не меньше трёх непустых групп или сжатая форма "::" с группами
+// по обе стороны (пустых групп регулярка не допускает, а \b отсекает Vec::default).
+func validIPv6(s string) bool {
+	groups := 0
+	for _, g := range strings.Split(s, ":") {
+		if g != "" {
+			groups++
+		}
+	}
+	if len(s) < 6 || (groups < 3 && !strings.Contains(s, "::")) {
+		return false
+	}
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() != nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsUnspecified()
+}
+
+func validPhone(s string) bool {
+	n := len(onlyDigits(s))
+	return n >= 8 && n <= 15
+}
+
+// validPAN — 13..19 цифр и контрольная сумма Луна.
+func validPAN(s string) bool {
+	d := onlyDigits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	// одинаковые цифры (0000…) проходят Луна, но это заглушки
+	if strings.Count(d, d[:1]) == len(d) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(d) - 1; i >= 0; i-- {
+		x := int(d[i] - '0')
+		if double {
+			x *= 2
+			if x > 9 {
+				x -= 9
+			}
+		}
+		sum += x
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN — проверка ISO 13616 (mod 97 == 1).
+func validIBAN(s string) bool {
+	iban := strings.ReplaceAll(s, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	rearranged := iban[4:] + iban[:4]
+	var num strings.Builder
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			num.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			num.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(num.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -10,6 +10,13 @@ const (
 	KindPassword   Kind = "PASSWORD"
 	KindJWT        Kind = "JWT"
 	KindOther      Kind = "OTHER"
+
+	// PII — персональные данные (включаются через Config.PII)
+	KindEmail      Kind = "PII_EMAIL"
+	KindIP         Kind = "PII_IP"
+	KindPhone      Kind = "PII_PHONE"
+	KindCardNumber Kind = "PII_CARD_NUMBER"
+	KindIBAN       Kind = "PII_IBAN"
 )
 
 // Severity — уровень серьёзности.
@@ -38,15 +45,20 @@ type Finding struct {
 
 // SecretReport — агрегированный отчёт.
 type SecretReport struct {
-	TotalFindings   int
-	ByKind          map[Kind]int
-	Files           []FileSummary
-	AppliedStrategy Strategy
+	TotalFindings   int           `json:"totalFindings"`
+	PIIFindings     int           `json:"piiFindings"` // сколько из TotalFindings относятся к PII
+	Suppressed      int           `json:"suppressed"`  // отключены через rep2prompt:allow / baseline (в TotalFindings не входят)
+	ByKind          map[Kind]int  `json:"byKind"`
+	Files           []FileSummary `json:"files"`
+	AppliedStrategy Strategy      `json:"appliedStrategy"`
+	DroppedFiles    []string      `json:"droppedFiles,omitempty"` // файлы-контейнеры секретов, не вошедшие в экспорт (.env, *.pem …)
 }
 
 type FileSummary struct {
-	Path   string
-	Count  int
-	Kinds  []Kind
-	Levels []Severity
+	Path   string     `json:"path"`
+	Count  int        `json:"count"`
+	Kinds  []Kind     `json:"kinds"`
+	Levels []Severity `json:"levels"`
+	// Fingerprints — отпечатки находок; их можно скопировать в baseline, чтобы принять находку.
+	Fingerprints []string `json:"fingerprints,omitempty"`
 }
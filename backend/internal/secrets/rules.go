@@ -1,6 +1,10 @@
 package secrets
 
-import "regexp"
+import (
+	"path"
+	"regexp"
+	"strings"
+)
 
 // rule — внутреннее представление правила.
 type rule struct {
@@ -49,3 +53,27 @@ func compileRules() []rule {
 		{"password_code", KindPassword, regexp.MustCompile(`(?i)password\s*[:=]\s*["'][^"']{6,}["']`), SevMed, "Password in code"},
 	}
 }
+
+// IsSecretFile — файлы, которые почти всегда содержат секреты целиком
+// (.env, приватные SSH-ключи, PEM/PKCS#12 контейнеры). Такие файлы
+// бессмысленно маскировать построчно — их проще не экспортировать вовсе.
+func IsSecretFile(p string) bool {
+	base := strings.ToLower(path.Base(p))
+	switch base {
+	case ".env", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519":
+		return true
+	}
+	// .env.local, .env.production и т.п. — да; шаблоны (.env.example) — нет
+	if strings.HasPrefix(base, ".env.") {
+		switch strings.TrimPrefix(base, ".env.") {
+		case "example", "sample", "template", "dist":
+			return false
+		}
+		return true
+	}
+	switch path.Ext(base) {
+	case ".pem", ".p12", ".pfx", ".key":
+		return true
+	}
+	return false
+}
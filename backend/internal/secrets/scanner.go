@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -13,17 +14,41 @@ type Scanner struct {
 	rules    []rule
 	report   SecretReport
 	perFile  map[string]*fileAgg // для корреляций (например, AWS пара)
+	pseudo   map[string]int      // значение секрета → номер псевдонима (только в памяти)
 }
 
 type fileAgg struct {
 	seen map[string]bool // rule ids seen
+	fps  []string        // отпечатки находок (для составления baseline)
+
+	// structured_key даёт разные типы (см. kindForKey) — храним их отдельно
+	structKinds  []Kind
+	structLevels []Severity
+}
+
+func (a *fileAgg) addStructured(kind Kind, sev Severity) {
+	for _, k := range a.structKinds {
+		if k == kind {
+			return
+		}
+	}
+	a.structKinds = append(a.structKinds, kind)
+	a.structLevels = append(a.structLevels, sev)
 }
 
 func NewScanner(cfg Config) *Scanner {
+	var rules []rule
+	if !cfg.NoSecrets {
+		rules = append(rules, compileRules()...)
+	}
+	if cfg.PII {
+		rules = append(rules, compilePIIRules()...)
+	}
 	s := &Scanner{
 		cfg:     cfg,
-		rules:   compileRules(),
+		rules:   rules,
 		perFile: make(map[string]*fileAgg),
+		pseudo:  make(map[string]int),
 		report: SecretReport{
 			ByKind:          map[Kind]int{},
 			AppliedStrategy: cfg.Strategy,
@@ -38,6 +63,7 @@ func (s *Scanner) Reset() {
 		AppliedStrategy: s.cfg.Strategy,
 	}
 	s.perFile = make(map[string]*fileAgg)
+	s.pseudo = make(map[string]int)
 }
 
 func (s *Scanner) agg(path string) *fileAgg {
@@ -62,7 +88,12 @@ func (s *Scanner) Report() SecretReport {
 				levels = append(levels, r.sev)
 			}
 		}
-		files = append(files, FileSummary{Path: p, Count: len(kinds), Kinds: kinds, Levels: levels})
+		kinds = append(kinds, a.structKinds...)
+		levels = append(levels, a.structLevels...)
+		if len(kinds) == 0 {
+			continue // файл просканирован, но чист — в отчёт не пишем
+		}
+		files = append(files, FileSummary{Path: p, Count: len(kinds), Kinds: kinds, Levels: levels, Fingerprints: a.fps})
 	}
 	// отсортируем для стабильности
 	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
@@ -73,15 +104,17 @@ func (s *Scanner) Report() SecretReport {
 
 // ScanLine — построчная проверка.
 func (s *Scanner) ScanLine(path, line string, lineno int) []Finding {
-	// быстрые пропуски — фиктивные значения
-	lowers := strings.ToLower(line)
-	if strings.Contains(lowers, "redacted") || strings.Contains(lowers, "dummy") || strings.Contains(lowers, "placeholder") {
-		return nil
-	}
-	// комментарии/примеры — понизим серьёзность
-	suspectComment := strings.Contains(lowers, "example") || strings.Contains(lowers, "sample") || strings.Contains(lowers, "fake") || strings.Contains(lowers, "test")
+	finds, held := s.scanLine(path, line, lineno)
+	s.account(finds, held)
+	return finds
+}
+
+// scanLine — находки строки и отдельно подавленные (allow/baseline); в отчёт
+// не пишет — это делает account, чтобы ScanBlob не считал одно и то же дважды.
+func (s *Scanner) scanLine(path, line string, lineno int) (finds, held []Finding) {
+	// фикстуры/примеры — понизим серьёзность
+	fixturePath := isFixturePath(path)
 
-	var finds []Finding
 	for _, r := range s.rules {
 		idxs := r.re.FindAllStringIndex(line, -1)
 		for _, span := range idxs {
@@ -97,8 +130,8 @@ func (s *Scanner) ScanLine(path, line string, lineno int) []Finding {
 			}
 			// ENV special: только «подозрительные» ключи считаем секретными
 			if r.id == "env_pair" {
-				key := strings.ToUpper(strings.TrimSpace(strings.SplitN(f.Value, "=", 2)[0]))
-				if !(strings.Contains(key, "SECRET") || strings.Contains(key, "TOKEN") || strings.Contains(key, "PASSWORD") || strings.Contains(key, "API") || strings.Contains(key, "KEY") || strings.Contains(key, "PWD") || strings.Contains(key, "PRIVATE")) {
+				key := strings.TrimSpace(strings.SplitN(f.Value, "=", 2)[0])
+				if !IsSecretKey(key) {
 					// не считаем это секретом
 					continue
 				}
@@ -107,11 +140,26 @@ func (s *Scanner) ScanLine(path, line string, lineno int) []Finding {
 			if r.id == "jwt" && !looksLikeJWT(f.Value) {
 				continue
 			}
-			// понижаем severity для комментариев/фикстур
-			if suspectComment && f.Severity == SevHigh {
-				f.Severity = SevMed
-			} else if suspectComment && f.Severity == SevMed {
-				f.Severity = SevLow
+			// PII — контрольные суммы/валидация адресов (хвост вроде " EUR" отрезаем)
+			if _, ok := piiValidators[r.id]; ok {
+				n := piiValidLen(r.id, f.Value)
+				if n == 0 {
+					continue
+				}
+				f.Value = f.Value[:n]
+				f.Span.End = f.Span.Start + n
+			}
+			// фиктивные значения (<REDACTED>, dummy, xxxx...) не считаем вовсе
+			if isPlaceholder(f) {
+				continue
+			}
+			if s.suppressed(f, line) {
+				held = append(held, f)
+				continue
+			}
+			// понижаем severity для фикстур/примеров
+			if fixturePath || isExampleValue(f.Value) {
+				f.Severity = downgrade(f.Severity)
 			}
 			finds = append(finds, f)
 		}
@@ -126,6 +174,9 @@ func (s *Scanner) ScanLine(path, line string, lineno int) []Finding {
 	for id := range seenIDs {
 		a.seen[id] = true
 	}
+	for _, f := range finds {
+		a.fps = append(a.fps, Fingerprint(f))
+	}
 	if a.seen["aws_akid"] && a.seen["aws_secret"] {
 		for i := range finds {
 			if finds[i].RuleID == "aws_akid" || finds[i].RuleID == "aws_secret" {
@@ -134,19 +185,65 @@ func (s *Scanner) ScanLine(path, line string, lineno int) []Finding {
 		}
 	}
 
-	// учёт в отчёте
+	return finds, held
+}
+
+// account — учёт находок в отчёте; единственное место, где растут счётчики.
+func (s *Scanner) account(finds, held []Finding) {
 	s.report.TotalFindings += len(finds)
+	s.report.Suppressed += len(held)
 	for _, f := range finds {
 		s.report.ByKind[f.Kind]++
+		if IsPIIKind(f.Kind) {
+			s.report.PIIFindings++
+		}
 	}
+}
 
-	return finds
+// suppressed — находка отключена inline-комментарием (rep2prompt:allow / gitleaks:allow)
+// или уже принята в baseline; такие считаем отдельно в SecretReport.Suppressed.
+func (s *Scanner) suppressed(f Finding, line string) bool {
+	return hasAllowComment(line) || s.cfg.Baseline.Has(f)
+}
+
+// isPlaceholder — значение находки само по себе заглушка, а не секрет
+// (у правил KEY=value смотрим только значение — см. secretValue).
+func isPlaceholder(f Finding) bool {
+	_, val := secretValue(f)
+	lv := strings.ToLower(val)
+	if strings.Contains(lv, "redacted") || strings.Contains(lv, "dummy") || strings.Contains(lv, "placeholder") ||
+		strings.Contains(lv, "changeme") || strings.Contains(lv, "your_") || strings.Contains(lv, "<secret") {
+		return true
+	}
+	// xxxxxxxx / ******** / 00000000
+	trimmed := strings.Trim(lv, `"' `)
+	return len(trimmed) >= 4 && strings.Trim(trimmed, string(trimmed[0])) == "" && strings.ContainsAny(trimmed[:1], "x*0.-")
 }
 
-// ScanBlob — на будущее (для двоичных/больших кусков).
-func (s *Scanner) ScanBlob(path string, b []byte) []Finding {
-	_ = path; _ = b
-	return nil
+// isFixturePath — тесты, фикстуры и примеры: находки там чаще всего фейковые.
+func isFixturePath(p string) bool {
+	lp := "/" + strings.ToLower(p)
+	for _, m := range []string{"/test/", "/tests/", "/testdata/", "/fixtures/", "/__tests__/", "/examples/", "/example/", "_test.", ".test.", ".spec."} {
+		if strings.Contains(lp, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func isExampleValue(v string) bool {
+	lv := strings.ToLower(v)
+	return strings.Contains(lv, "example") || strings.Contains(lv, "sample") || strings.Contains(lv, "fake")
+}
+
+func downgrade(sev Severity) Severity {
+	switch sev {
+	case SevHigh:
+		return SevMed
+	case SevMed:
+		return SevLow
+	}
+	return sev
 }
 
 // ApplyStrategy — применить стратегию к строке с учётом находок.
@@ -154,6 +251,7 @@ func (s *Scanner) ApplyStrategy(line string, finds []Finding) string {
 	if len(finds) == 0 {
 		return line
 	}
+	finds = dropOverlaps(finds)
 	switch s.cfg.Strategy {
 	case StrategyStrip:
 		// полностью заменить строку
@@ -172,6 +270,17 @@ func (s *Scanner) ApplyStrategy(line string, finds []Finding) string {
 			out = prefix + "<<SECRET:" + f.RuleID + ">>" + mid + "<<END>>" + suffix
 		}
 		return out
+	case StrategyPseudonymize:
+		// заменяем только само значение (ключ "PASSWORD=" оставляем для контекста)
+		out := line
+		sort.Slice(finds, func(i, j int) bool { return finds[i].Span.Start > finds[j].Span.Start })
+		for _, f := range finds {
+			off, val := secretValue(f)
+			start := f.Span.Start + off
+			end := start + len(val)
+			out = out[:start] + s.pseudonym(val, f.RuleID) + out[end:]
+		}
+		return out
 	default: // StrategyRedacted
 		out := line
 		sort.Slice(finds, func(i, j int) bool { return finds[i].Span.Start > finds[j].Span.Start })
@@ -184,6 +293,74 @@ func (s *Scanner) ApplyStrategy(line string, finds []Finding) string {
 	}
 }
 
+// pseudonym — стабильный плейсхолдер для значения: одно и то же значение
+// в любом файле экспорта получает один и тот же номер.
+func (s *Scanner) pseudonym(val, ruleID string) string {
+	n, ok := s.pseudo[val]
+	if !ok {
+		n = len(s.pseudo) + 1
+		s.pseudo[val] = n
+	}
+	return "<SECRET_" + strconv.Itoa(n) + ":" + ruleID + ">"
+}
+
+// secretValue — вырезает из совпадения собственно секрет.
+// Для правил вида KEY=value / password: "value" совпадение включает ключ,
+// а сравнивать между файлами нужно только значение.
+// Возвращает смещение значения внутри f.Value и само значение.
+func secretValue(f Finding) (int, string) {
+	switch f.RuleID {
+	case "env_pair", "aws_secret", "password_code":
+	default:
+		return 0, f.Value
+	}
+	i := strings.IndexAny(f.Value, "=:")
+	if i < 0 {
+		return 0, f.Value
+	}
+	off := i + 1
+	rest := f.Value[off:]
+	trimmed := strings.TrimLeft(rest, " \t")
+	off += len(rest) - len(trimmed)
+	// кавычки вокруг значения оставляем на месте
+	if len(trimmed) >= 2 && (trimmed[0] == '"' || trimmed[0] == '\'') && trimmed[len(trimmed)-1] == trimmed[0] {
+		off++
+		trimmed = trimmed[1 : len(trimmed)-1]
+	}
+	trimmed = strings.TrimRight(trimmed, " \t")
+	if trimmed == "" {
+		return 0, f.Value
+	}
+	return off, trimmed
+}
+
+// dropOverlaps — оставляет непересекающиеся находки (при пересечении — более длинную),
+// иначе замены справа налево портят смещения.
+func dropOverlaps(finds []Finding) []Finding {
+	if len(finds) < 2 {
+		return finds
+	}
+	sorted := append([]Finding(nil), finds...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Span.Start != sorted[j].Span.Start {
+			return sorted[i].Span.Start < sorted[j].Span.Start
+		}
+		return sorted[i].Span.End > sorted[j].Span.End
+	})
+	out := sorted[:0:0]
+	for _, f := range sorted {
+		if n := len(out); n > 0 && f.Span.Start < out[n-1].Span.End {
+			last := &out[n-1]
+			if f.Span.End-f.Span.Start > last.Span.End-last.Span.Start {
+				*last = f
+			}
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
 func looksLikeJWT(tok string) bool {
 	parts := strings.Split(tok, ".")
 	if len(parts) != 3 {
@@ -1,5 +1,7 @@
 package secrets
 
+import "strings"
+
 // Strategy — как обрабатывать найденные секреты.
 type Strategy int
 
@@ -10,10 +12,35 @@ const (
 	StrategyStrip
 	// StrategyMark — обернуть в <<SECRET:RuleID>>...<<END>> (для ручной проверки).
 	StrategyMark
+	// StrategyPseudonymize — заменить на <SECRET_N:RuleID>, где N стабилен для
+	// одного и того же значения в пределах всего экспорта (одного Scanner).
+	// Сама карта значение → N живёт только в памяти сканера.
+	StrategyPseudonymize
 )
 
 // Config — настройки сканера.
 type Config struct {
 	Strategy Strategy
+	// PII — дополнительно искать персональные данные (email, IP, телефоны, карты, IBAN).
+	PII bool
+	// NoSecrets — не применять правила секретов (например, если нужен только PII).
+	NoSecrets bool
+	// Baseline — отпечатки принятых находок (см. Fingerprint/ParseBaseline), они не маскируются.
+	Baseline Baseline
 	// При необходимости сюда можно добавить white/black-list путей, уровни логирования и т. п.
 }
+
+// ParseStrategy — разбор строкового значения из API ("redacted"|"strip"|"mark"|"pseudonymize").
+// Неизвестные/пустые значения → StrategyRedacted.
+func ParseStrategy(s string) Strategy {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "strip":
+		return StrategyStrip
+	case "mark":
+		return StrategyMark
+	case "pseudonymize", "pseudo":
+		return StrategyPseudonymize
+	default:
+		return StrategyRedacted
+	}
+}
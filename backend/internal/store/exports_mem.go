@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log/slog"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,15 +15,27 @@ import (
 
 // ExportOptions — то, что кладём в JSONB (MVP как поля).
 type ExportOptions struct {
-	IncludeGlobs    []string
+	IncludeGlobs    []string // итоговые маски (с раскрытыми пресетами)
 	ExcludeGlobs    []string
+	FilterPresets   []string // имена пресетов из запроса — для истории
 	SecretScan      bool
 	SecretStrategy  string
+	DropSecretFiles bool
+	PIIScan         bool
+	SecretBaseline  string
+	NoRepoIgnore    bool
+	SkipGenerated   bool
 	TokenModel      string
 	TTLHours        int
 	MaxBinarySizeMB int
 	Profile         string // short|full|rag
-	Format          string // zip|txt|promptpack (md legacy alias)
+	Format          string // zip|txt|promptpack|pr (md legacy alias)
+	RootPath        string // экспорт только поддерева ("" — весь репозиторий)
+	Submodules      bool   // докачивать сабмодули
+	ResolveLFS      bool   // качать объекты Git LFS вместо pointer'ов
+	PR              int    // номер PR для format=pr
+	DiffBase        string // diff-режим: только изменённое в DiffBase...Ref
+	DiffHunks       bool   // в diff-режиме — с unified diff
 	IdempotencyKey  string
 }
 
@@ -78,8 +93,7 @@ type ExportSnapshot struct {
 type ExportsMem struct {
 	mu        sync.RWMutex
 	byID      map[string]*Export
-	byIdem    map[string]string // idemKey → exportID
-	genSeq    int64
+	byIdem    map[string]string // ScopedIdemKey(userID, idemKey) → exportID
 	idPrefix  string
 	listeners map[string]map[int]chan ExportSnapshot
 	nextSubID int
@@ -103,31 +117,32 @@ func NewExportsMemWithRepo(prefix string, repo ExportsRepo) *ExportsMem {
 	return s
 }
 
+// genID — exp_<время>_<128 бит случайности>: по ID экспорта отдаём статус и
+// артефакты, так что угадываться он не должен.
 func (s *ExportsMem) genID() string {
-	s.genSeq++
-	return s.idPrefix + "_" + time.Now().UTC().Format("20060102T150405") + "_" + itoa(s.genSeq)
+	rnd := make([]byte, 16)
+	_, _ = rand.Read(rnd)
+	return s.idPrefix + "_" + time.Now().UTC().Format("20060102T150405") + "_" + hex.EncodeToString(rnd)
 }
 
-func itoa(n int64) string {
-	if n == 0 {
-		return "0"
-	}
-	var b [32]byte
-	i := len(b)
-	for n > 0 {
-		i--
-		b[i] = byte('0' + n%10)
-		n /= 10
-	}
-	return string(b[i:])
+// ScopedIdemKey — ключ идемпотентности в пределах пользователя ("" — анонимный).
+// Длина userID впереди делает склейку однозначной: никакой ключ, присланный
+// клиентом, не совпадёт с ключом другого пользователя.
+func ScopedIdemKey(userID, key string) string {
+	return strconv.Itoa(len(userID)) + ":" + userID + ":" + key
 }
 
 func (s *ExportsMem) CreateOrReuse(owner, repo, ref string, opts ExportOptions) (*Export, bool) {
+	return s.CreateOrReuseFor("", owner, repo, ref, opts)
+}
+
+// CreateOrReuseFor — то же, но экспорт принадлежит пользователю userID ("" — анонимный).
+func (s *ExportsMem) CreateOrReuseFor(userID, owner, repo, ref string, opts ExportOptions) (*Export, bool) {
 	key := strings.TrimSpace(opts.IdempotencyKey)
 
 	s.mu.Lock()
 	if key != "" {
-		if id, ok := s.byIdem[key]; ok {
+		if id, ok := s.byIdem[ScopedIdemKey(userID, key)]; ok {
 			e := s.byID[id]
 			s.mu.Unlock()
 			return e, true
@@ -143,6 +158,7 @@ func (s *ExportsMem) CreateOrReuse(owner, repo, ref string, opts ExportOptions)
 	now := time.Now().UTC()
 	e := &Export{
 		ID:        id,
+		UserID:    userID,
 		Owner:     owner,
 		Repo:      repo,
 		Ref:       ref,
@@ -152,7 +168,7 @@ func (s *ExportsMem) CreateOrReuse(owner, repo, ref string, opts ExportOptions)
 		CreatedAt: now,
 	}
 	s.byID[id] = e
-	s.byIdem[key] = id
+	s.byIdem[ScopedIdemKey(userID, key)] = id
 	s.mu.Unlock()
 
 	// write-through → PG
@@ -467,7 +483,7 @@ func (s *ExportsMem) syncFromRepo(id string) (*Export, bool) {
 			if s.byIdem == nil {
 				s.byIdem = make(map[string]string)
 			}
-			s.byIdem[cloned.Options.IdempotencyKey] = cloned.ID
+			s.byIdem[ScopedIdemKey(cloned.UserID, cloned.Options.IdempotencyKey)] = cloned.ID
 		}
 		result = cloned
 		snap = snapshotLocked(cloned)
@@ -47,3 +47,23 @@ func TestCreateOrReuseTrimsAndReusesExplicitIdempotencyKey(t *testing.T) {
 		t.Fatalf("expected same export id for reused export")
 	}
 }
+
+func TestCreateOrReuseForScopesKeyToUser(t *testing.T) {
+	s := NewExportsMem("exp")
+
+	mine, _ := s.CreateOrReuseFor("gh:1", "owner", "repo", "main", ExportOptions{IdempotencyKey: "key"})
+	// ни анонимный, ни другой пользователь не получают чужой экспорт — даже подделав «префикс»
+	for _, c := range []struct{ user, key string }{{"", "gh:1:key"}, {"", "4:gh:1:key"}, {"gh:2", "key"}} {
+		got, reused := s.CreateOrReuseFor(c.user, "owner", "repo", "main", ExportOptions{IdempotencyKey: c.key})
+		if reused || got.ID == mine.ID {
+			t.Fatalf("user %q with key %q reused another user's export", c.user, c.key)
+		}
+	}
+	again, reused := s.CreateOrReuseFor("gh:1", "owner", "repo", "main", ExportOptions{IdempotencyKey: "key"})
+	if !reused || again.ID != mine.ID {
+		t.Fatalf("owner's repeated request was not deduplicated")
+	}
+	if again.Options.IdempotencyKey != "key" {
+		t.Fatalf("stored key %q, want the client's key", again.Options.IdempotencyKey)
+	}
+}
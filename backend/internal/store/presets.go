@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/yourname/cleanhttp/internal/filters"
+)
+
+var ErrPresetNotFound = errors.New("filter preset not found")
+
+// PresetsStore — пользовательские пресеты фильтров (встроенные живут в filters).
+// Пресеты принадлежат пользователю: имя уникально в пределах userID.
+type PresetsStore interface {
+	ListPresets(ctx context.Context, userID string) ([]filters.Preset, error)
+	GetPreset(ctx context.Context, userID, name string) (filters.Preset, error)
+	PutPreset(ctx context.Context, userID string, p filters.Preset) error
+	DeletePreset(ctx context.Context, userID, name string) error
+}
+
+// PresetsMem — in-memory PresetsStore (когда Postgres не настроен).
+type PresetsMem struct {
+	mu     sync.RWMutex
+	byUser map[string]map[string]filters.Preset // userID → name → пресет
+}
+
+func NewPresetsMem() *PresetsMem {
+	return &PresetsMem{byUser: map[string]map[string]filters.Preset{}}
+}
+
+func (s *PresetsMem) ListPresets(ctx context.Context, userID string) ([]filters.Preset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	own := s.byUser[userID]
+	out := make([]filters.Preset, 0, len(own))
+	for _, p := range own {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *PresetsMem) GetPreset(ctx context.Context, userID, name string) (filters.Preset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.byUser[userID][name]
+	if !ok {
+		return filters.Preset{}, ErrPresetNotFound
+	}
+	return p, nil
+}
+
+func (s *PresetsMem) PutPreset(ctx context.Context, userID string, p filters.Preset) error {
+	p.Builtin = false
+	p.IncludeGlobs = append([]string(nil), p.IncludeGlobs...)
+	p.ExcludeGlobs = append([]string(nil), p.ExcludeGlobs...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	own := s.byUser[userID]
+	if own == nil {
+		own = map[string]filters.Preset{}
+		s.byUser[userID] = own
+	}
+	own[p.Name] = p
+	return nil
+}
+
+func (s *PresetsMem) DeletePreset(ctx context.Context, userID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byUser[userID][name]; !ok {
+		return ErrPresetNotFound
+	}
+	delete(s.byUser[userID], name)
+	return nil
+}
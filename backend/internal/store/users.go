@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// User — пользователь, вошедший через GitHub OAuth. Токен хранится только
+// зашифрованным (auth.Cipher); в очередь и логи уходит лишь ID.
+type User struct {
+	ID        string // "gh:<github id>"
+	GitHubID  int64
+	Login     string
+	Name      string
+	Email     string
+	AvatarURL string
+	TokenEnc  []byte // AES-GCM(OAuth access token)
+	Scopes    string
+	// SessionVersion — растёт при выходе; cookie со старой версией недействительны.
+	SessionVersion int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// UsersStore — пользователи и их зашифрованные токены.
+type UsersStore interface {
+	GetUser(ctx context.Context, id string) (User, error)
+	PutUser(ctx context.Context, u User) error
+	// RevokeSessions — сделать недействительными все выданные сессии пользователя.
+	RevokeSessions(ctx context.Context, id string) error
+}
+
+// UsersMem — in-memory UsersStore (когда Postgres не настроен; воркер так токен не получит).
+type UsersMem struct {
+	mu   sync.RWMutex
+	byID map[string]User
+}
+
+func NewUsersMem() *UsersMem {
+	return &UsersMem{byID: map[string]User{}}
+}
+
+func (s *UsersMem) GetUser(ctx context.Context, id string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.byID[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *UsersMem) PutUser(ctx context.Context, u User) error {
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.byID[u.ID]; ok {
+		u.CreatedAt = prev.CreatedAt
+		u.SessionVersion = prev.SessionVersion
+	} else {
+		u.CreatedAt = now
+	}
+	u.UpdatedAt = now
+	u.TokenEnc = append([]byte(nil), u.TokenEnc...)
+	s.byID[u.ID] = u
+	return nil
+}
+
+func (s *UsersMem) RevokeSessions(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.byID[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	u.SessionVersion++
+	s.byID[id] = u
+	return nil
+}
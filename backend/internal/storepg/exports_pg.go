@@ -61,16 +61,17 @@ RETURNING id, user_id, project_id, owner, repo, ref, options, status, progress,
 		statusText string
 		profile    string
 		fmtS       string
+		idemKey    string // ScopedIdemKey; сам ключ клиента — в options
 	)
 	row := r.pool.QueryRow(ctx, q,
 		exp.ID, exp.UserID, exp.ProjectID, exp.Owner, exp.Repo, exp.Ref,
 		optsJSON, string(exp.Status), exp.Progress, exp.FailureReason,
-		exp.Options.IdempotencyKey, exp.Options.Profile, exp.Options.Format,
+		store.ScopedIdemKey(exp.UserID, exp.Options.IdempotencyKey), exp.Options.Profile, exp.Options.Format,
 	)
 	if err := row.Scan(
 		&res.ID, &res.UserID, &res.ProjectID, &res.Owner, &res.Repo, &res.Ref,
 		&optionsRaw, &statusText, &res.Progress, &res.FailureReason, &res.CancelRequested,
-		&res.CreatedAt, &res.StartedAt, &res.FinishedAt, &res.Options.IdempotencyKey, &profile, &fmtS,
+		&res.CreatedAt, &res.StartedAt, &res.FinishedAt, &idemKey, &profile, &fmtS,
 	); err != nil {
 		return nil, false, err
 	}
@@ -95,12 +96,13 @@ func (r *ExportsPG) GetByID(ctx context.Context, id string) (*store.Export, bool
 		statusText string
 		profile    string
 		fmtS       string
+		idemKey    string // ScopedIdemKey; сам ключ клиента — в options
 	)
 	row := r.pool.QueryRow(ctx, q, id)
 	if err := row.Scan(
 		&res.ID, &res.UserID, &res.ProjectID, &res.Owner, &res.Repo, &res.Ref,
 		&optionsRaw, &statusText, &res.Progress, &res.FailureReason, &res.CancelRequested,
-		&res.CreatedAt, &res.StartedAt, &res.FinishedAt, &res.Options.IdempotencyKey, &profile, &fmtS,
+		&res.CreatedAt, &res.StartedAt, &res.FinishedAt, &idemKey, &profile, &fmtS,
 	); err != nil {
 		return nil, false, err
 	}
@@ -0,0 +1,104 @@
+package storepg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yourname/cleanhttp/internal/filters"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+// PresetsPG — реализация store.PresetsStore на Postgres (таблица filter_presets).
+type PresetsPG struct {
+	pool *pgxpool.Pool
+}
+
+// Presets — хранилище пресетов на том же пуле соединений.
+func (r *ExportsPG) Presets() *PresetsPG { return &PresetsPG{pool: r.pool} }
+
+func (r *PresetsPG) ListPresets(ctx context.Context, userID string) ([]filters.Preset, error) {
+	const q = `SELECT name, description, include_globs, exclude_globs FROM filter_presets WHERE user_id = $1 ORDER BY name`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []filters.Preset
+	for rows.Next() {
+		p, err := scanPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *PresetsPG) GetPreset(ctx context.Context, userID, name string) (filters.Preset, error) {
+	const q = `SELECT name, description, include_globs, exclude_globs FROM filter_presets WHERE user_id = $1 AND name = $2`
+	p, err := scanPreset(r.pool.QueryRow(ctx, q, userID, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return filters.Preset{}, store.ErrPresetNotFound
+	}
+	return p, err
+}
+
+func (r *PresetsPG) PutPreset(ctx context.Context, userID string, p filters.Preset) error {
+	const q = `
+INSERT INTO filter_presets (user_id, name, description, include_globs, exclude_globs, created_at, updated_at)
+VALUES ($1,$2,$3,$4,$5, NOW(), NOW())
+ON CONFLICT (user_id, name) DO UPDATE SET
+  description = excluded.description,
+  include_globs = excluded.include_globs,
+  exclude_globs = excluded.exclude_globs,
+  updated_at = NOW()`
+	inc, err := json.Marshal(nonNil(p.IncludeGlobs))
+	if err != nil {
+		return err
+	}
+	exc, err := json.Marshal(nonNil(p.ExcludeGlobs))
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, q, userID, p.Name, p.Description, inc, exc)
+	return err
+}
+
+func (r *PresetsPG) DeletePreset(ctx context.Context, userID, name string) error {
+	const q = `DELETE FROM filter_presets WHERE user_id = $1 AND name = $2`
+	tag, err := r.pool.Exec(ctx, q, userID, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return store.ErrPresetNotFound
+	}
+	return nil
+}
+
+func scanPreset(row pgx.Row) (filters.Preset, error) {
+	var (
+		p        filters.Preset
+		inc, exc []byte
+	)
+	if err := row.Scan(&p.Name, &p.Description, &inc, &exc); err != nil {
+		return filters.Preset{}, err
+	}
+	if err := json.Unmarshal(inc, &p.IncludeGlobs); err != nil {
+		return filters.Preset{}, err
+	}
+	if err := json.Unmarshal(exc, &p.ExcludeGlobs); err != nil {
+		return filters.Preset{}, err
+	}
+	return p, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -44,6 +44,48 @@ CREATE TABLE IF NOT EXISTS artifacts (
 );
 
 CREATE INDEX IF NOT EXISTS idx_artifacts_export ON artifacts (export_id);
+
+CREATE TABLE IF NOT EXISTS filter_presets (
+  user_id       TEXT NOT NULL DEFAULT '',
+  name          TEXT NOT NULL,
+  description   TEXT NOT NULL DEFAULT '',
+  include_globs JSONB NOT NULL DEFAULT '[]',
+  exclude_globs JSONB NOT NULL DEFAULT '[]',
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+  id          TEXT PRIMARY KEY,
+  github_id   BIGINT NOT NULL UNIQUE,
+  login       TEXT NOT NULL,
+  name        TEXT NOT NULL DEFAULT '',
+  email       TEXT NOT NULL DEFAULT '',
+  avatar_url  TEXT NOT NULL DEFAULT '',
+  token_enc   BYTEA NOT NULL,
+  scopes      TEXT NOT NULL DEFAULT '',
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exports_user ON exports (user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version BIGINT NOT NULL DEFAULT 0;
+
+-- пресеты стали пользовательскими: старые (общие) получают user_id '' и никому не видны
+ALTER TABLE filter_presets ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_index i
+    JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+    WHERE i.indrelid = 'filter_presets'::regclass AND i.indisprimary AND a.attname = 'user_id'
+  ) THEN
+    ALTER TABLE filter_presets DROP CONSTRAINT IF EXISTS filter_presets_pkey;
+    ALTER TABLE filter_presets ADD PRIMARY KEY (user_id, name);
+  END IF;
+END $$;
 `
 
 func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
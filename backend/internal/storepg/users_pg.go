@@ -0,0 +1,57 @@
+package storepg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yourname/cleanhttp/internal/store"
+)
+
+// UsersPG — реализация store.UsersStore на Postgres (таблица users).
+type UsersPG struct {
+	pool *pgxpool.Pool
+}
+
+// Users — хранилище пользователей на том же пуле соединений.
+func (r *ExportsPG) Users() *UsersPG { return &UsersPG{pool: r.pool} }
+
+func (r *UsersPG) GetUser(ctx context.Context, id string) (store.User, error) {
+	const q = `SELECT id, github_id, login, name, email, avatar_url, token_enc, scopes, session_version, created_at, updated_at
+FROM users WHERE id = $1`
+	var u store.User
+	err := r.pool.QueryRow(ctx, q, id).Scan(&u.ID, &u.GitHubID, &u.Login, &u.Name, &u.Email, &u.AvatarURL,
+		&u.TokenEnc, &u.Scopes, &u.SessionVersion, &u.CreatedAt, &u.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return store.User{}, store.ErrUserNotFound
+	}
+	return u, err
+}
+
+func (r *UsersPG) PutUser(ctx context.Context, u store.User) error {
+	const q = `
+INSERT INTO users (id, github_id, login, name, email, avatar_url, token_enc, scopes, created_at, updated_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8, NOW(), NOW())
+ON CONFLICT (id) DO UPDATE SET
+  login = excluded.login,
+  name = excluded.name,
+  email = excluded.email,
+  avatar_url = excluded.avatar_url,
+  token_enc = excluded.token_enc,
+  scopes = excluded.scopes,
+  updated_at = NOW()`
+	_, err := r.pool.Exec(ctx, q, u.ID, u.GitHubID, u.Login, u.Name, u.Email, u.AvatarURL, u.TokenEnc, u.Scopes)
+	return err
+}
+
+func (r *UsersPG) RevokeSessions(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE users SET session_version = session_version + 1, updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return store.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Package submodules — сабмодули в экспорте: tarball GitHub их не содержит
+// (gitlink — просто SHA), поэтому находим их по дереву и .gitmodules, качаем
+// архивы сабмодулей тем же клиентом и вклеиваем в поток основного архива.
+package submodules
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+// Лимиты по умолчанию (нулевые поля Options).
+const (
+	DefaultMaxDepth   = 2         // сабмодули сабмодулей — да, глубже — нет
+	DefaultMaxModules = 20        // всего сабмодулей на экспорт
+	DefaultMaxBytes   = 100 << 20 // суммарный объём файлов всех сабмодулей
+
+	// DefaultModuleTimeout — на скачивание одного сабмодуля: медленный модуль
+	// не съедает время остальных, а попадает в отчёт как download-failed
+	DefaultModuleTimeout = time.Minute
+
+	maxGitmodulesBytes = 64 << 10
+)
+
+// Причины пропуска (уходят в manifest).
+const (
+	SkipNotInGitmodules = "not-in-gitmodules" // gitlink есть, записи в .gitmodules нет
+	SkipNotGitHub       = "not-github"        // URL не на github.com — скачать нечем
+	SkipDepthLimit      = "depth-limit"
+	SkipCountLimit      = "count-limit"
+	SkipSizeLimit       = "size-limit" // упёрлись в MaxBytes: сабмодуль включён частично или не включён
+	SkipNotFound        = "not-found"  // нет доступа или коммит удалён
+	SkipDownloadFailed  = "download-failed"
+)
+
+// Source — что нужно от GitHub-клиента (*githubclient.Client).
+type Source interface {
+	GetTree(ctx context.Context, owner, repo, ref string) ([]githubclient.TreeItem, error)
+	GetRawFile(ctx context.Context, owner, repo, pth, ref string, maxBytes int64) ([]byte, bool, error)
+	GetTarball(ctx context.Context, owner, repo, ref string) (io.ReadCloser, error)
+}
+
+type Options struct {
+	MaxDepth      int
+	MaxModules    int
+	MaxBytes      int64
+	ModuleTimeout time.Duration
+}
+
+// Module — сабмодуль, который включаем в экспорт.
+type Module struct {
+	Path  string `json:"path"` // путь от корня основного репозитория (у вложенных — с путём родителя)
+	URL   string `json:"url"`
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	SHA   string `json:"sha"`
+	Files int    `json:"files"` // сколько файлов вклеено (заполняет Merge)
+	Bytes int64  `json:"bytes"`
+	// Partial — включён не целиком, и почему (SkipSizeLimit, SkipDownloadFailed);
+	// такой модуль есть только в Included, в Skipped его нет
+	Partial string `json:"partial,omitempty"`
+}
+
+// Skip — сабмодуль, который не включили, и почему.
+type Skip struct {
+	Path   string `json:"path"`
+	URL    string `json:"url,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Report — раздел manifest'а про сабмодули.
+type Report struct {
+	Included []Module `json:"included"`
+	Skipped  []Skip   `json:"skipped"`
+}
+
+type Resolver struct {
+	GH   Source
+	Opts Options
+}
+
+func (r *Resolver) opts() Options {
+	o := r.Opts
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxModules <= 0 {
+		o.MaxModules = DefaultMaxModules
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = DefaultMaxBytes
+	}
+	if o.ModuleTimeout <= 0 {
+		o.ModuleTimeout = DefaultModuleTimeout
+	}
+	return o
+}
+
+// Resolve — сабмодули репозитория на ref (и вложенные, до MaxDepth).
+// Ошибка — только если не удалось получить дерево самого репозитория.
+func (r *Resolver) Resolve(ctx context.Context, owner, repo, ref string) (*Report, error) {
+	rep := &Report{Included: []Module{}, Skipped: []Skip{}}
+	if err := r.resolve(ctx, rep, owner, repo, ref, "", 0); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, rep *Report, owner, repo, ref, prefix string, depth int) error {
+	o := r.opts()
+	tree, err := r.GH.GetTree(ctx, owner, repo, ref)
+	if err != nil {
+		return err
+	}
+	var links []githubclient.TreeItem
+	for _, it := range tree {
+		if it.Submodule {
+			links = append(links, it)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	// .gitmodules может не быть (битый репозиторий) — тогда все gitlink'и пропускаем
+	var urls map[string]string
+	if gm, _, err := r.GH.GetRawFile(ctx, owner, repo, ".gitmodules", ref, maxGitmodulesBytes); err == nil {
+		urls = ParseGitmodules(gm)
+	}
+
+	for _, l := range links {
+		full := path.Join(prefix, l.Path)
+		url, ok := urls[l.Path]
+		switch {
+		case !ok:
+			rep.Skipped = append(rep.Skipped, Skip{Path: full, Reason: SkipNotInGitmodules})
+			continue
+		case depth >= o.MaxDepth:
+			rep.Skipped = append(rep.Skipped, Skip{Path: full, URL: url, Reason: SkipDepthLimit})
+			continue
+		case len(rep.Included) >= o.MaxModules:
+			rep.Skipped = append(rep.Skipped, Skip{Path: full, URL: url, Reason: SkipCountLimit})
+			continue
+		}
+		subOwner, subRepo, ok := GitHubRepo(url, owner, repo)
+		if !ok {
+			rep.Skipped = append(rep.Skipped, Skip{Path: full, URL: url, Reason: SkipNotGitHub})
+			continue
+		}
+		rep.Included = append(rep.Included, Module{Path: full, URL: url, Owner: subOwner, Repo: subRepo, SHA: l.SHA})
+
+		// вложенные сабмодули; ошибка дерева сабмодуля — не повод ронять экспорт
+		if err := r.resolve(ctx, rep, subOwner, subRepo, l.SHA, full, depth+1); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Merge — поток .tar.gz: основной архив как есть, следом файлы сабмодулей
+// под «<корень архива>/<путь сабмодуля>/...». Не скачанные сабмодули
+// переезжают из rep.Included в rep.Skipped, скачанные частично остаются в
+// Included с Partial. Каждый сабмодуль качается со своим ModuleTimeout;
+// ctx ограничивает весь поток. Закрытие результата закрывает main.
+func (r *Resolver) Merge(ctx context.Context, main io.ReadCloser, rep *Report) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(r.merge(ctx, main, rep, pw))
+	}()
+	return &mergedReader{PipeReader: pr, main: main}
+}
+
+type mergedReader struct {
+	*io.PipeReader
+	main io.Closer
+}
+
+func (m *mergedReader) Close() error {
+	_ = m.PipeReader.Close()
+	return m.main.Close()
+}
+
+func (r *Resolver) merge(ctx context.Context, main io.Reader, rep *Report, dst io.Writer) error {
+	gzr, err := gzip.NewReader(main)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	gzw, _ := gzip.NewWriterLevel(dst, gzip.BestSpeed) // сжатие тут лишь ради формата входа билдеров
+	tw := tar.NewWriter(gzw)
+
+	// 1) основной архив без изменений; запоминаем корневой каталог ("owner-repo-sha")
+	top := ""
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if i := strings.IndexByte(hdr.Name, '/'); top == "" && i > 0 {
+			top = hdr.Name[:i]
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	if top == "" {
+		top = "repo"
+	}
+
+	// 2) сабмодули
+	o := r.opts()
+	budget := o.MaxBytes
+	included := rep.Included[:0]
+	for _, m := range rep.Included {
+		if budget <= 0 {
+			rep.Skipped = append(rep.Skipped, Skip{Path: m.Path, URL: m.URL, Reason: SkipSizeLimit})
+			continue
+		}
+		mctx, cancel := context.WithTimeout(ctx, o.ModuleTimeout)
+		files, n, full, err := r.appendModule(mctx, tw, top, m, budget)
+		cancel()
+		budget -= n
+		m.Files, m.Bytes = files, n
+		reason := ""
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, githubclient.ErrNotFound):
+			reason = SkipNotFound
+		case err != nil:
+			reason = SkipDownloadFailed
+		case !full:
+			reason = SkipSizeLimit
+		}
+		if reason != "" && files == 0 {
+			rep.Skipped = append(rep.Skipped, Skip{Path: m.Path, URL: m.URL, Reason: reason})
+			continue
+		}
+		m.Partial = reason
+		included = append(included, m)
+	}
+	rep.Included = included
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// appendModule — файлы одного сабмодуля в tw. full=false — упёрлись в budget.
+func (r *Resolver) appendModule(ctx context.Context, tw *tar.Writer, top string, m Module, budget int64) (files int, written int64, full bool, err error) {
+	rc, err := r.GH.GetTarball(ctx, m.Owner, m.Repo, m.SHA)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer rc.Close()
+	gzr, err := gzip.NewReader(rc)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, written, true, nil
+		}
+		if err != nil {
+			return files, written, false, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		_, rel, ok := strings.Cut(hdr.Name, "/")
+		if !ok || rel == "" {
+			continue
+		}
+		if written+hdr.Size > budget {
+			return files, written, false, nil
+		}
+		// тело — целиком до заголовка: оборванная на середине запись (таймаут
+		// модуля) сломала бы tar-поток для всех следующих файлов
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err == nil && int64(len(data)) != hdr.Size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return files, written, false, err
+		}
+		out := &tar.Header{
+			Name:     top + "/" + m.Path + "/" + rel,
+			Mode:     hdr.Mode,
+			Size:     hdr.Size,
+			ModTime:  hdr.ModTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(out); err != nil {
+			return files, written, false, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return files, written, false, err
+		}
+		files++
+		written += hdr.Size
+	}
+}
+
+// ParseGitmodules — путь сабмодуля → URL из .gitmodules (формат git config).
+func ParseGitmodules(b []byte) map[string]string {
+	type entry struct{ path, url string }
+	var (
+		out = map[string]string{}
+		cur *entry
+	)
+	flush := func() {
+		if cur != nil && cur.path != "" && cur.url != "" {
+			out[strings.Trim(cur.path, "/")] = cur.url
+		}
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			flush()
+			cur = nil
+			if strings.HasPrefix(line, "[submodule") {
+				cur = &entry{}
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "path":
+			cur.path = v
+		case "url":
+			cur.url = v
+		}
+	}
+	flush()
+	return out
+}
+
+// GitHubRepo — owner/repo сабмодуля, если он на github.com.
+// Относительные URL (../lib.git) — относительно репозитория-родителя.
+func GitHubRepo(url, parentOwner, parentRepo string) (string, string, bool) {
+	if strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+		p := path.Join(parentOwner, parentRepo, url)
+		owner, repo, ok := strings.Cut(p, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") || owner == ".." {
+			return "", "", false
+		}
+		return owner, strings.TrimSuffix(repo, ".git"), true
+	}
+	ref, err := githubclient.ParseRepoURL(url)
+	if err != nil || ref.Ref != "" || ref.PR != 0 {
+		return "", "", false
+	}
+	return ref.Owner, ref.Repo, true
+}
@@ -0,0 +1,192 @@
+package submodules
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func tarGz(top string, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		_ = tw.WriteHeader(&tar.Header{Name: top + "/" + n, Mode: 0644, Size: int64(len(files[n])), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(files[n]))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// fakeGH — репозитории в памяти: дерево, .gitmodules и архивы по "owner/repo@ref".
+// Архивы из hang отдаются «зависшими»: чтение ждёт отмены контекста.
+type fakeGH struct {
+	trees    map[string][]githubclient.TreeItem
+	modules  map[string]string
+	tarballs map[string][]byte
+	hang     map[string]bool
+}
+
+type hangingBody struct{ ctx context.Context }
+
+func (h hangingBody) Read([]byte) (int, error) {
+	<-h.ctx.Done()
+	return 0, h.ctx.Err()
+}
+
+func (h hangingBody) Close() error { return nil }
+
+func (f *fakeGH) GetTree(_ context.Context, owner, repo, ref string) ([]githubclient.TreeItem, error) {
+	return f.trees[owner+"/"+repo+"@"+ref], nil
+}
+
+func (f *fakeGH) GetRawFile(_ context.Context, owner, repo, _, ref string, _ int64) ([]byte, bool, error) {
+	gm, ok := f.modules[owner+"/"+repo+"@"+ref]
+	if !ok {
+		return nil, false, githubclient.ErrNotFound
+	}
+	return []byte(gm), false, nil
+}
+
+func (f *fakeGH) GetTarball(ctx context.Context, owner, repo, ref string) (io.ReadCloser, error) {
+	if f.hang[owner+"/"+repo+"@"+ref] {
+		return hangingBody{ctx}, nil
+	}
+	b, ok := f.tarballs[owner+"/"+repo+"@"+ref]
+	if !ok {
+		return nil, githubclient.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func TestResolveAndMerge(t *testing.T) {
+	gh := &fakeGH{
+		trees: map[string][]githubclient.TreeItem{
+			"o/app@main": {
+				{Path: "libs/ui", Type: "dir", Submodule: true, SHA: "s1"},
+				{Path: "libs/ext", Type: "dir", Submodule: true, SHA: "s2"},
+				{Path: "libs/gone", Type: "dir", Submodule: true, SHA: "s3"},
+			},
+		},
+		modules: map[string]string{"o/app@main": `
+[submodule "ui"]
+	path = libs/ui
+	url = ../ui.git
+[submodule "ext"]
+	path = libs/ext
+	url = https://gitlab.com/x/ext.git
+[submodule "gone"]
+	path = libs/gone
+	url = git@github.com:o/gone.git
+`},
+		tarballs: map[string][]byte{
+			"o/ui@s1": tarGz("o-ui-s1", map[string]string{"button.tsx": "export const B = 1"}),
+		},
+	}
+	r := &Resolver{GH: gh}
+	rep, err := r.Resolve(context.Background(), "o", "app", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Included) != 2 || len(rep.Skipped) != 1 || rep.Skipped[0].Reason != SkipNotGitHub {
+		t.Fatalf("report after resolve: %+v", rep)
+	}
+
+	main := tarGz("o-app-abc", map[string]string{"README.md": "app"})
+	rc := r.Merge(context.Background(), io.NopCloser(bytes.NewReader(main)), rep)
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	want := []string{"o-app-abc/README.md", "o-app-abc/libs/ui/button.tsx"}
+	if len(names) != 2 || names[0] != want[0] || names[1] != want[1] {
+		t.Fatalf("entries %v, want %v", names, want)
+	}
+	// недоступный сабмодуль переехал в skipped
+	if len(rep.Included) != 1 || rep.Included[0].Files != 1 || len(rep.Skipped) != 2 || rep.Skipped[1].Reason != SkipNotFound {
+		t.Fatalf("report after merge: %+v", rep)
+	}
+}
+
+// Частично скачанный модуль — только в Included (с Partial), зависший — не
+// держит остальных дольше своего ModuleTimeout.
+func TestMerge_PartialAndSlowModules(t *testing.T) {
+	gh := &fakeGH{
+		tarballs: map[string][]byte{
+			"o/big@s1": tarGz("o-big-s1", map[string]string{"a.txt": "0123456789", "b.txt": "0123456789"}),
+			"o/ok@s3":  tarGz("o-ok-s3", map[string]string{"x": "1"}),
+		},
+		hang: map[string]bool{"o/slow@s2": true},
+	}
+	r := &Resolver{GH: gh, Opts: Options{MaxBytes: 15, ModuleTimeout: 100 * time.Millisecond}}
+	rep := &Report{Included: []Module{
+		{Path: "libs/big", Owner: "o", Repo: "big", SHA: "s1"},
+		{Path: "libs/slow", Owner: "o", Repo: "slow", SHA: "s2"},
+		{Path: "libs/ok", Owner: "o", Repo: "ok", SHA: "s3"},
+	}, Skipped: []Skip{}}
+
+	start := time.Now()
+	rc := r.Merge(context.Background(), io.NopCloser(bytes.NewReader(tarGz("o-app-abc", map[string]string{"README.md": "app"}))), rep)
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		t.Fatal(err)
+	}
+	_ = rc.Close()
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("slow module held the merge for %v", time.Since(start))
+	}
+
+	if len(rep.Included) != 2 || rep.Included[0].Path != "libs/big" || rep.Included[0].Partial != SkipSizeLimit ||
+		rep.Included[0].Files != 1 || rep.Included[1].Path != "libs/ok" || rep.Included[1].Partial != "" {
+		t.Fatalf("included: %+v", rep.Included)
+	}
+	if len(rep.Skipped) != 1 || rep.Skipped[0].Path != "libs/slow" || rep.Skipped[0].Reason != SkipDownloadFailed {
+		t.Fatalf("skipped: %+v", rep.Skipped)
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	cases := []struct {
+		url, owner, repo string
+		ok               bool
+	}{
+		{"../lib.git", "o", "lib", true},
+		{"../../other/lib", "other", "lib", true},
+		{"https://github.com/a/b.git", "a", "b", true},
+		{"git@github.com:a/b.git", "a", "b", true},
+		{"https://gitlab.com/a/b.git", "", "", false},
+		{"../../../x", "", "", false},
+	}
+	for _, c := range cases {
+		o, r, ok := GitHubRepo(c.url, "o", "app")
+		if o != c.owner || r != c.repo || ok != c.ok {
+			t.Errorf("%s: got %q %q %v", c.url, o, r, ok)
+		}
+	}
+}
@@ -46,6 +46,15 @@ func (e *Estimator) CountForFiles(chunks []string, modelID string) int {
 	return sum
 }
 
+// CountForSize — оценка без текста, только по размеру в байтах (дерево, статистика).
+// Та же пропорция ~4 байта на токен; для не-латиницы занижает.
+func (e *Estimator) CountForSize(size int64) int64 {
+	if size <= 0 {
+		return 0
+	}
+	return (size + 3) / 4
+}
+
 func normalize(s string) string {
 	// уберём BOM
 	s = strings.TrimPrefix(s, "\uFEFF")
@@ -0,0 +1,58 @@
+// Package treecache — кэш деревьев репозиториев для /repo/tree и /repo/stats.
+//
+// Дерево кэшируется по SHA коммита (неизменяемо — можно хранить долго),
+// а ref (ветка/тег) → SHA — отдельной записью с ETag: по истечении свежести
+// её ревалидируют условным запросом к GitHub (304 не тратит rate limit).
+package treecache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+// RefEntry — ref → SHA коммита, ETag ответа GitHub и время последней проверки.
+type RefEntry struct {
+	SHA       string    `json:"sha"`
+	ETag      string    `json:"etag"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// TreeCache — общее хранилище деревьев. Реализации: Memory (LRU в процессе)
+// и Redis (общий для всех реплик API). Ошибки хранилища — это промах, не отказ.
+type TreeCache interface {
+	GetRef(ctx context.Context, key string) (RefEntry, bool)
+	SetRef(ctx context.Context, key string, e RefEntry)
+	GetTree(ctx context.Context, key string) (githubclient.TreeResult, bool)
+	SetTree(ctx context.Context, key string, t githubclient.TreeResult)
+	// GetFiles/SetFiles — мелкие файлы коммита (путь → содержимое), например
+	// правила .gitignore для экспорта; как и дерево, по SHA неизменяемы.
+	GetFiles(ctx context.Context, key string) (map[string]string, bool)
+	SetFiles(ctx context.Context, key string, files map[string]string)
+}
+
+// RefKey — ключ записи ref → SHA. owner/repo у GitHub регистронезависимы.
+// scope — githubclient.TokenScope: у запросов с токеном пользователя свои записи,
+// приватные репозитории не видны другим.
+func RefKey(scope, owner, repo, ref string) string {
+	return scopePrefix(scope) + "ref:" + strings.ToLower(owner) + "/" + strings.ToLower(repo) + "@" + ref
+}
+
+// TreeKey — ключ дерева конкретного коммита.
+func TreeKey(scope, owner, repo, sha string) string {
+	return scopePrefix(scope) + "tree:" + strings.ToLower(owner) + "/" + strings.ToLower(repo) + "@" + sha
+}
+
+// RulesKey — ключ файлов правил репозитория (.gitignore и т.п.) конкретного коммита.
+func RulesKey(scope, owner, repo, sha string) string {
+	return scopePrefix(scope) + "rules:" + strings.ToLower(owner) + "/" + strings.ToLower(repo) + "@" + sha
+}
+
+func scopePrefix(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return scope + ":"
+}
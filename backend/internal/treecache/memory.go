@@ -0,0 +1,138 @@
+package treecache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+// DefaultMaxItems — ёмкость Memory по умолчанию: суммарно элементов деревьев.
+const DefaultMaxItems = 1_000_000
+
+// Memory — LRU в памяти процесса. Ёмкость считаем в элементах деревьев
+// (запись ref стоит 1), а не в записях: одно дерево монорепо весит как тысяча мелких.
+type Memory struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxCost int
+	cost    int
+	ll      *list.List // front — самые свежие
+	byKey   map[string]*list.Element
+}
+
+type memEntry struct {
+	key   string
+	until time.Time
+	cost  int
+	ref   RefEntry
+	tree  githubclient.TreeResult
+	files map[string]string
+}
+
+// NewMemory — ttl: срок жизни записи; maxItems: ёмкость (<=0 — DefaultMaxItems).
+func NewMemory(ttl time.Duration, maxItems int) *Memory {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	}
+	return &Memory{ttl: ttl, maxCost: maxItems, ll: list.New(), byKey: map[string]*list.Element{}}
+}
+
+func (m *Memory) GetRef(_ context.Context, key string) (RefEntry, bool) {
+	e, ok := m.get(key)
+	if !ok {
+		return RefEntry{}, false
+	}
+	return e.ref, true
+}
+
+func (m *Memory) SetRef(_ context.Context, key string, r RefEntry) {
+	m.set(&memEntry{key: key, cost: 1, ref: r})
+}
+
+func (m *Memory) GetTree(_ context.Context, key string) (githubclient.TreeResult, bool) {
+	e, ok := m.get(key)
+	if !ok {
+		return githubclient.TreeResult{}, false
+	}
+	// копия среза — вызывающий не должен испортить кэш
+	out := e.tree
+	out.Items = append([]githubclient.TreeItem(nil), e.tree.Items...)
+	return out, true
+}
+
+func (m *Memory) SetTree(_ context.Context, key string, t githubclient.TreeResult) {
+	cp := t
+	cp.Items = append([]githubclient.TreeItem(nil), t.Items...)
+	m.set(&memEntry{key: key, cost: len(cp.Items) + 1, tree: cp})
+}
+
+func (m *Memory) GetFiles(_ context.Context, key string) (map[string]string, bool) {
+	e, ok := m.get(key)
+	if !ok || e.files == nil {
+		return nil, false
+	}
+	return copyFiles(e.files), true
+}
+
+func (m *Memory) SetFiles(_ context.Context, key string, files map[string]string) {
+	m.set(&memEntry{key: key, cost: len(files) + 1, files: copyFiles(files)})
+}
+
+func copyFiles(files map[string]string) map[string]string {
+	out := make(map[string]string, len(files))
+	for p, c := range files {
+		out[p] = c
+	}
+	return out
+}
+
+// Len — число записей (для метрик и тестов).
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+func (m *Memory) get(key string) (*memEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memEntry)
+	if time.Now().After(e.until) {
+		m.removeLocked(el)
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return e, true
+}
+
+func (m *Memory) set(e *memEntry) {
+	if e.cost > m.maxCost {
+		return // больше всего кэша — не храним
+	}
+	e.until = time.Now().Add(m.ttl)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.byKey[e.key]; ok {
+		m.removeLocked(el)
+	}
+	m.byKey[e.key] = m.ll.PushFront(e)
+	m.cost += e.cost
+	// вытесняем самые давние, пока не влезем
+	for m.cost > m.maxCost {
+		m.removeLocked(m.ll.Back())
+	}
+}
+
+func (m *Memory) removeLocked(el *list.Element) {
+	e := el.Value.(*memEntry)
+	m.ll.Remove(el)
+	delete(m.byKey, e.key)
+	m.cost -= e.cost
+}
@@ -0,0 +1,58 @@
+package treecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+func tree(n int) githubclient.TreeResult {
+	return githubclient.TreeResult{Items: make([]githubclient.TreeItem, n)}
+}
+
+func TestMemoryEvictsByCost(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(time.Hour, 10)
+
+	m.SetTree(ctx, "a", tree(4)) // стоимость 5
+	m.SetRef(ctx, "r", RefEntry{SHA: "x"})
+	if _, ok := m.GetTree(ctx, "a"); !ok { // a — самый свежий, r — самый давний
+		t.Fatal("a must be cached")
+	}
+	m.SetTree(ctx, "b", tree(4)) // 5+1+5 > 10 — вытесняем r
+
+	if _, ok := m.GetRef(ctx, "r"); ok {
+		t.Error("r must be evicted")
+	}
+	if _, ok := m.GetTree(ctx, "a"); !ok {
+		t.Error("a must survive")
+	}
+	if _, ok := m.GetTree(ctx, "b"); !ok {
+		t.Error("b must be cached")
+	}
+
+	m.SetTree(ctx, "huge", tree(100)) // больше всего кэша — не кладём и ничего не вытесняем
+	if m.Len() != 2 {
+		t.Errorf("len = %d, want 2", m.Len())
+	}
+}
+
+func TestMemoryTTLAndCopy(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(time.Hour, 0)
+	m.SetTree(ctx, "a", tree(1))
+
+	got, _ := m.GetTree(ctx, "a")
+	got.Items[0].Path = "changed"
+	if again, _ := m.GetTree(ctx, "a"); again.Items[0].Path != "" {
+		t.Error("cache must return a copy")
+	}
+
+	m = NewMemory(-time.Second, 0)
+	m.SetRef(ctx, "r", RefEntry{SHA: "x"})
+	if _, ok := m.GetRef(ctx, "r"); ok {
+		t.Error("expired entry must be a miss")
+	}
+}
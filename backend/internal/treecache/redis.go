@@ -0,0 +1,128 @@
+package treecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+// redisPrefix — пространство ключей кэша в общем Redis (там же живёт asynq).
+const redisPrefix = "rep2prompt:treecache:"
+
+// Redis — кэш в Redis, общий для всех реплик API. Деревья хранятся как gzip(JSON):
+// дерево монорепо в JSON — десятки мегабайт, сжимается в разы.
+type Redis struct {
+	rdb redisKV
+	ttl time.Duration
+}
+
+// redisKV — то, что Redis нужно от клиента (в тестах — фейк без сервера).
+type redisKV interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+	Close() error
+}
+
+// NewRedis — addr/password как у asynq (REDIS_ADDR, REDIS_PASSWORD); ttl — срок жизни ключей.
+func NewRedis(addr, password string, ttl time.Duration) *Redis {
+	return &Redis{
+		rdb: redis.NewClient(&redis.Options{Addr: addr, Password: password}),
+		ttl: ttl,
+	}
+}
+
+func (r *Redis) GetRef(ctx context.Context, key string) (RefEntry, bool) {
+	b, ok := r.get(ctx, key)
+	if !ok {
+		return RefEntry{}, false
+	}
+	var e RefEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return RefEntry{}, false
+	}
+	return e, true
+}
+
+func (r *Redis) SetRef(ctx context.Context, key string, e RefEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	r.set(ctx, key, b)
+}
+
+func (r *Redis) GetTree(ctx context.Context, key string) (githubclient.TreeResult, bool) {
+	b, ok := r.get(ctx, key)
+	if !ok {
+		return githubclient.TreeResult{}, false
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return githubclient.TreeResult{}, false
+	}
+	defer zr.Close()
+	var t githubclient.TreeResult
+	if err := json.NewDecoder(zr).Decode(&t); err != nil {
+		return githubclient.TreeResult{}, false
+	}
+	return t, true
+}
+
+func (r *Redis) SetTree(ctx context.Context, key string, t githubclient.TreeResult) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(t); err != nil {
+		return
+	}
+	if err := zw.Close(); err != nil {
+		return
+	}
+	r.set(ctx, key, buf.Bytes())
+}
+
+func (r *Redis) GetFiles(ctx context.Context, key string) (map[string]string, bool) {
+	b, ok := r.get(ctx, key)
+	if !ok {
+		return nil, false
+	}
+	var files map[string]string
+	if err := json.Unmarshal(b, &files); err != nil || files == nil {
+		return nil, false
+	}
+	return files, true
+}
+
+func (r *Redis) SetFiles(ctx context.Context, key string, files map[string]string) {
+	b, err := json.Marshal(files)
+	if err != nil {
+		return
+	}
+	r.set(ctx, key, b)
+}
+
+func (r *Redis) get(ctx context.Context, key string) ([]byte, bool) {
+	b, err := r.rdb.Get(ctx, redisPrefix+key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("treecache redis get failed", slog.String("key", key), slog.String("error", err.Error()))
+		}
+		return nil, false
+	}
+	return b, true
+}
+
+func (r *Redis) set(ctx context.Context, key string, b []byte) {
+	if err := r.rdb.Set(ctx, redisPrefix+key, b, r.ttl).Err(); err != nil {
+		slog.Warn("treecache redis set failed", slog.String("key", key), slog.String("error", err.Error()))
+	}
+}
+
+// Close — закрыть соединения.
+func (r *Redis) Close() error { return r.rdb.Close() }
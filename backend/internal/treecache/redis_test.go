@@ -0,0 +1,99 @@
+package treecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourname/cleanhttp/internal/githubclient"
+)
+
+// fakeKV — Redis без сервера: значения и TTL последнего SET по ключу.
+type fakeKV struct {
+	vals map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newFakeKV() *fakeKV {
+	return &fakeKV{vals: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeKV) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx, "get", key)
+	if b, ok := f.vals[key]; ok {
+		cmd.SetVal(string(b))
+	} else {
+		cmd.SetErr(redis.Nil)
+	}
+	return cmd
+}
+
+func (f *fakeKV) Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd {
+	f.vals[key] = append([]byte(nil), value.([]byte)...)
+	f.ttls[key] = expiration
+	cmd := redis.NewStatusCmd(ctx, "set", key)
+	cmd.SetVal("OK")
+	return cmd
+}
+
+func (f *fakeKV) Close() error { return nil }
+
+func TestRedisRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	kv := newFakeKV()
+	r := &Redis{rdb: kv, ttl: 6 * time.Hour}
+
+	want := githubclient.TreeResult{
+		Items: []githubclient.TreeItem{
+			{Path: "cmd", Type: "dir"},
+			{Path: "cmd/main.go", Type: "file", Size: 42, Kind: "text"},
+			{Path: "libs/ui", Type: "dir", Submodule: true, SHA: "abc"},
+		},
+		Truncated: true,
+	}
+	r.SetTree(ctx, "tree:o/r@sha", want)
+
+	raw := kv.vals[redisPrefix+"tree:o/r@sha"]
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("tree is not stored as gzip: %v", err)
+	}
+	zr.Close()
+	if got := kv.ttls[redisPrefix+"tree:o/r@sha"]; got != 6*time.Hour {
+		t.Errorf("tree ttl = %v, want 6h", got)
+	}
+	got, ok := r.GetTree(ctx, "tree:o/r@sha")
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetTree = %+v, %v", got, ok)
+	}
+
+	ref := RefEntry{SHA: "abc", ETag: `"e1"`, CheckedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	r.SetRef(ctx, "ref:o/r@main", ref)
+	if got := kv.ttls[redisPrefix+"ref:o/r@main"]; got != 6*time.Hour {
+		t.Errorf("ref ttl = %v, want 6h", got)
+	}
+	gotRef, ok := r.GetRef(ctx, "ref:o/r@main")
+	if !ok || gotRef.SHA != ref.SHA || gotRef.ETag != ref.ETag || !gotRef.CheckedAt.Equal(ref.CheckedAt) {
+		t.Fatalf("GetRef = %+v, %v", gotRef, ok)
+	}
+
+	files := map[string]string{".gitignore": "*.log\n", "web/.gitignore": ""}
+	r.SetFiles(ctx, "rules:o/r@sha", files)
+	if got, ok := r.GetFiles(ctx, "rules:o/r@sha"); !ok || !reflect.DeepEqual(got, files) {
+		t.Fatalf("GetFiles = %v, %v", got, ok)
+	}
+
+	// промах и битые данные — просто промах
+	if _, ok := r.GetTree(ctx, "tree:o/r@other"); ok {
+		t.Error("missing key must be a miss")
+	}
+	kv.vals[redisPrefix+"tree:o/r@bad"] = []byte("not gzip")
+	if _, ok := r.GetTree(ctx, "tree:o/r@bad"); ok {
+		t.Error("corrupt tree must be a miss")
+	}
+}
@@ -0,0 +1,2 @@
+-- backend/migrations/0002_filter_presets.down.sql
+DROP TABLE IF EXISTS filter_presets;
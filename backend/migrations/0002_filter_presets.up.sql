@@ -0,0 +1,9 @@
+-- backend/migrations/0002_filter_presets.up.sql
+CREATE TABLE IF NOT EXISTS filter_presets (
+  name          TEXT PRIMARY KEY,
+  description   TEXT NOT NULL DEFAULT '',
+  include_globs JSONB NOT NULL DEFAULT '[]',
+  exclude_globs JSONB NOT NULL DEFAULT '[]',
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- backend/migrations/0003_users.down.sql
+DROP INDEX IF EXISTS idx_exports_user;
+DROP TABLE IF EXISTS users;
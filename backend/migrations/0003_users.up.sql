@@ -0,0 +1,15 @@
+-- backend/migrations/0003_users.up.sql
+CREATE TABLE IF NOT EXISTS users (
+  id          TEXT PRIMARY KEY,
+  github_id   BIGINT NOT NULL UNIQUE,
+  login       TEXT NOT NULL,
+  name        TEXT NOT NULL DEFAULT '',
+  email       TEXT NOT NULL DEFAULT '',
+  avatar_url  TEXT NOT NULL DEFAULT '',
+  token_enc   BYTEA NOT NULL,
+  scopes      TEXT NOT NULL DEFAULT '',
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exports_user ON exports (user_id);
@@ -0,0 +1,6 @@
+-- backend/migrations/0004_filter_presets_user.down.sql
+-- пользовательские пресеты с одинаковыми именами в общий ключ не влезут
+DELETE FROM filter_presets WHERE user_id <> '';
+ALTER TABLE filter_presets DROP CONSTRAINT IF EXISTS filter_presets_pkey;
+ALTER TABLE filter_presets DROP COLUMN IF EXISTS user_id;
+ALTER TABLE filter_presets ADD PRIMARY KEY (name);
@@ -0,0 +1,5 @@
+-- backend/migrations/0004_filter_presets_user.up.sql
+-- пресеты стали пользовательскими: старые (общие) получают user_id '' и никому не видны
+ALTER TABLE filter_presets ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE filter_presets DROP CONSTRAINT IF EXISTS filter_presets_pkey;
+ALTER TABLE filter_presets ADD PRIMARY KEY (user_id, name);
@@ -0,0 +1,2 @@
+-- backend/migrations/0005_users_session_version.down.sql
+ALTER TABLE users DROP COLUMN IF EXISTS session_version;
@@ -0,0 +1,3 @@
+-- backend/migrations/0005_users_session_version.up.sql
+-- версия сессий пользователя: logout увеличивает её и отзывает все выданные сессии
+ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version BIGINT NOT NULL DEFAULT 0;
@@ -2,6 +2,7 @@ import React, {
   createContext,
   useCallback,
   useContext,
+  useEffect,
   useMemo,
   useState,
 } from 'react';
@@ -33,7 +34,7 @@ import { Settings } from './components/pages/Settings';
 import { Sidebar } from './components/layout/Sidebar';
 import { Topbar } from './components/organisms/Topbar';
 import { Toaster } from './components/ui/sonner';
-import { fetchRepositoryTree } from './lib/api';
+import { fetchCurrentUser, fetchRepositoryTree, logoutSession } from './lib/api';
 import {
   ArtifactFile,
   JobState,
@@ -214,9 +215,19 @@ export default function App() {
 
   const logout = useCallback(() => {
     setUser(null);
+    logoutSession().catch(() => undefined);
     navigate(pageToPath.signin);
   }, [navigate]);
 
+  // сессия GitHub OAuth (cookie) — подхватываем пользователя при загрузке
+  useEffect(() => {
+    fetchCurrentUser()
+      .then((me) =>
+        setUser({ name: me.name || me.login, email: me.email, avatar: me.avatarUrl || undefined }),
+      )
+      .catch(() => undefined);
+  }, []);
+
   const loadTree = useCallback(
     async (owner: string, repo: string, ref: string, force: boolean = false) => {
       if (
@@ -3,13 +3,14 @@ import { useAppContext } from '../../App';
 import { Card, CardContent, CardHeader, CardTitle } from '../ui/card';
 import { Button } from '../ui/button';
 import { Label } from '../ui/label';
+import { Input } from '../ui/input';
 import { RadioGroup, RadioGroupItem } from '../ui/radio-group';
 import { Switch } from '../ui/switch';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '../ui/select';
 import { Slider } from '../ui/slider';
 import { Separator } from '../ui/separator';
 import { Alert, AlertDescription } from '../ui/alert';
-import { Play, Settings, FileArchive, FileText, File, Loader2 } from 'lucide-react';
+import { Play, Settings, FileArchive, FileText, File, GitPullRequest, Loader2 } from 'lucide-react';
 import { ApiError, createExport } from '../../lib/api';
 import { ExportFormat } from '../../lib/types';
 import { getFriendlyApiError } from '../../lib/errors';
@@ -28,12 +29,17 @@ export const ExportForm: React.FC = () => {
     setArtifactsExpiresAt,
     setLastExportFormat,
   } = useAppContext();
-  const [format, setFormat] = useState<ExportFormat>('md');
+  const [format, setFormat] = useState<ExportFormat>(repoData?.pr ? 'pr' : 'md');
   const [profile, setProfile] = useState('short');
   const [secretScan, setSecretScan] = useState(true);
   const [tokenModel, setTokenModel] = useState('openai');
   const [ttl, setTtl] = useState([72]);
   const [maxBinarySize, setMaxBinarySize] = useState([25]);
+  const [rootPath, setRootPath] = useState(repoData?.path ?? '');
+  const [includeSubmodules, setIncludeSubmodules] = useState(false);
+  const [resolveLfs, setResolveLfs] = useState(false);
+  const [diffOnly, setDiffOnly] = useState(Boolean(repoData?.diffBase));
+  const [diffHunks, setDiffHunks] = useState(true);
   const [submitting, setSubmitting] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
@@ -44,6 +50,8 @@ export const ExportForm: React.FC = () => {
       formatZip: 'ZIP архив',
       formatMd: 'Markdown Prompt Pack',
       formatTxt: 'Единый текстовый файл',
+      formatPr: 'PR pack',
+      formatPrDesc: 'Описание, обсуждение и дифф PR #{n}, изменённые файлы и их импорты',
       profile: 'Профиль детализации',
       profileShort: 'Краткий',
       profileFull: 'Полный',
@@ -53,6 +61,16 @@ export const ExportForm: React.FC = () => {
       tokenModel: 'Модель токенизации',
       ttlLabel: 'Время жизни файлов (часы)',
       maxBinaryLabel: 'Макс. размер бинарных файлов (МБ)',
+      rootPath: 'Только подкаталог',
+      rootPathDesc: 'Экспортировать поддерево; пути в результате — относительно него',
+      submodules: 'Сабмодули',
+      submodulesDesc: 'Скачать git-сабмодули с GitHub и включить их по своим путям',
+      lfs: 'Git LFS',
+      lfsDesc: 'Скачать текстовые файлы из Git LFS вместо pointer-файлов (с лимитом размера)',
+      diffOnly: 'Только изменения',
+      diffOnlyDesc: 'Экспортировать только файлы, изменённые в {range} (Markdown и TXT)',
+      diffHunks: 'Unified diff',
+      diffHunksDesc: 'Добавить к каждому файлу его дифф',
       createExport: 'Создать экспорт',
       advanced: 'Дополнительные настройки',
       errors: {
@@ -66,6 +84,8 @@ export const ExportForm: React.FC = () => {
       formatZip: 'ZIP Archive',
       formatMd: 'Markdown Prompt Pack',
       formatTxt: 'Single Text File',
+      formatPr: 'PR pack',
+      formatPrDesc: 'Description, discussion and diff of PR #{n}, changed files and their imports',
       profile: 'Detail Profile',
       profileShort: 'Short',
       profileFull: 'Full',
@@ -75,6 +95,16 @@ export const ExportForm: React.FC = () => {
       tokenModel: 'Token Model',
       ttlLabel: 'File TTL (hours)',
       maxBinaryLabel: 'Max Binary File Size (MB)',
+      rootPath: 'Subdirectory only',
+      rootPathDesc: 'Export a subtree; paths in the result are relative to it',
+      submodules: 'Submodules',
+      submodulesDesc: 'Fetch git submodules from GitHub and include them at their paths',
+      lfs: 'Git LFS',
+      lfsDesc: 'Fetch text files stored in Git LFS instead of pointer files (size-capped)',
+      diffOnly: 'Changes only',
+      diffOnlyDesc: 'Export only files changed in {range} (Markdown and TXT)',
+      diffHunks: 'Unified diff',
+      diffHunksDesc: 'Append each file\'s diff hunks',
       createExport: 'Create Export',
       advanced: 'Advanced Settings',
       errors: {
@@ -97,6 +127,9 @@ export const ExportForm: React.FC = () => {
     }
   }, [tokenModel]);
 
+  // diff mode is only supported for the Markdown and text builders
+  const diffExport = Boolean(repoData?.diffBase) && diffOnly && (format === 'md' || format === 'txt');
+
   const handleSubmit = () => {
     if (!repoData) {
       setError(t.errors.noRepo);
@@ -104,9 +137,13 @@ export const ExportForm: React.FC = () => {
     }
     setSubmitting(true);
     setError(null);
+    const root = rootPath.trim().replace(/^\/+|\/+$/g, '');
     const includeGlobs = (() => {
       if (selectedPaths.length > 0) {
-        return selectedPaths;
+        // selected paths are repo-relative; masks are applied relative to rootPath
+        return root
+          ? selectedPaths.filter((p) => p.startsWith(`${root}/`)).map((p) => p.slice(root.length + 1))
+          : selectedPaths;
       }
       if (filtersEnabled) {
         return includeMasks;
@@ -128,6 +165,12 @@ export const ExportForm: React.FC = () => {
       tokenModel: tokenModelId,
       maxBinarySizeMB: maxBinarySize[0],
       ttlHours: ttl[0],
+      rootPath: root || undefined,
+      includeSubmodules,
+      resolveLfs,
+      pr: format === 'pr' ? repoData.pr : undefined,
+      diffBase: diffExport ? repoData.diffBase : undefined,
+      diffHunks: diffExport ? diffHunks : undefined,
     })
       .then((resp) => {
         setArtifacts([]);
@@ -184,6 +227,20 @@ export const ExportForm: React.FC = () => {
                   {t.formatTxt}
                 </Label>
               </div>
+              {!!repoData?.pr && (
+                <div className="flex items-start space-x-2">
+                  <RadioGroupItem value="pr" id="pr" className="mt-1" />
+                  <div>
+                    <Label htmlFor="pr" className="flex items-center gap-2">
+                      <GitPullRequest className="w-4 h-4" />
+                      {t.formatPr}
+                    </Label>
+                    <p className="text-sm text-muted-foreground">
+                      {t.formatPrDesc.replace('{n}', String(repoData.pr))}
+                    </p>
+                  </div>
+                </div>
+              )}
             </RadioGroup>
           </div>
 
@@ -247,6 +304,61 @@ export const ExportForm: React.FC = () => {
           <CardTitle className="text-lg">{t.advanced}</CardTitle>
         </CardHeader>
         <CardContent className="space-y-6">
+          {/* Root Path */}
+          <div className="space-y-3">
+            <div>
+              <Label htmlFor="rootPath" className="text-base font-medium">{t.rootPath}</Label>
+              <p className="text-sm text-muted-foreground">{t.rootPathDesc}</p>
+            </div>
+            <Input
+              id="rootPath"
+              value={rootPath}
+              onChange={(e) => setRootPath(e.target.value)}
+              placeholder="services/billing"
+              className="font-mono"
+            />
+          </div>
+
+          {/* Submodules */}
+          <div className="flex items-center justify-between">
+            <div>
+              <Label className="text-base font-medium">{t.submodules}</Label>
+              <p className="text-sm text-muted-foreground">{t.submodulesDesc}</p>
+            </div>
+            <Switch checked={includeSubmodules} onCheckedChange={setIncludeSubmodules} />
+          </div>
+
+          {/* Git LFS */}
+          <div className="flex items-center justify-between">
+            <div>
+              <Label className="text-base font-medium">{t.lfs}</Label>
+              <p className="text-sm text-muted-foreground">{t.lfsDesc}</p>
+            </div>
+            <Switch checked={resolveLfs} onCheckedChange={setResolveLfs} />
+          </div>
+
+          {/* Changed files only (from the Compare page) */}
+          {!!repoData?.diffBase && (
+            <>
+              <div className="flex items-center justify-between">
+                <div>
+                  <Label className="text-base font-medium">{t.diffOnly}</Label>
+                  <p className="text-sm text-muted-foreground">
+                    {t.diffOnlyDesc.replace('{range}', `${repoData.diffBase}...${repoData.currentRef}`)}
+                  </p>
+                </div>
+                <Switch checked={diffOnly} onCheckedChange={setDiffOnly} />
+              </div>
+              <div className="flex items-center justify-between">
+                <div>
+                  <Label className="text-base font-medium">{t.diffHunks}</Label>
+                  <p className="text-sm text-muted-foreground">{t.diffHunksDesc}</p>
+                </div>
+                <Switch checked={diffHunks} onCheckedChange={setDiffHunks} disabled={!diffExport} />
+              </div>
+            </>
+          )}
+
           {/* TTL Slider */}
           <div className="space-y-3">
             <Label className="text-base font-medium">
@@ -52,8 +52,9 @@ export const RepoInputCard: React.FC = () => {
   const t = texts[language];
 
   const validateGitHubUrl = (url: string): boolean => {
-    const githubRegex = /^https:\/\/github\.com\/[\w\-\.]+\/[\w\-\.]+\/?$/;
-    return githubRegex.test(url);
+    // owner/repo, tree/blob/commit/pull links and ssh/git remotes; the backend does the exact parsing
+    const githubRegex = /^((https?:\/\/)?(www\.)?github\.com\/|git@github\.com:|(ssh|git):\/\/(git@)?github\.com\/)[\w\-\.]+\/[\w\-\.]+(\/\S*)?$/;
+    return githubRegex.test(url.trim());
   };
 
   const resetRepositoryState = () => {
@@ -86,8 +87,10 @@ export const RepoInputCard: React.FC = () => {
         owner: resolved.Owner,
         repo: resolved.Repo,
         defaultRef: resolved.DefaultRef,
-        currentRef: resolved.DefaultRef,
+        currentRef: resolved.RepoRef?.ref || resolved.DefaultRef,
         refs,
+        path: resolved.RepoRef?.path || undefined,
+        pr: resolved.RepoRef?.pr || undefined,
       };
       setRepoData(repo);
       resetRepositoryState();
@@ -7,6 +7,7 @@ import { Checkbox } from '../ui/checkbox';
 import { Separator } from '../ui/separator';
 import { Github, Chrome } from 'lucide-react';
 import { toast } from 'sonner';
+import { githubLoginUrl } from '../../lib/api';
 
 interface AuthProps {
   onAuthSuccess: (user: { name: string; email: string; avatar?: string }) => void;
@@ -21,6 +22,12 @@ export function Auth({ onAuthSuccess }: AuthProps) {
   const handleOAuthLogin = async (provider: 'github' | 'google') => {
     setIsLoading(true);
 
+    if (provider === 'github') {
+      // настоящий OAuth: бэкенд уведёт на github.com и вернёт с сессией
+      window.location.assign(githubLoginUrl());
+      return;
+    }
+
     // Simulate OAuth login
     setTimeout(() => {
       toast.success(`Вход через ${provider === 'github' ? 'GitHub' : 'Google'} выполнен`);
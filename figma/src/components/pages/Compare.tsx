@@ -1,4 +1,5 @@
 import React, { useState } from 'react';
+import { useAppContext } from '../../App';
 import { PageHeader } from '../layout/PageHeader';
 import { Button } from '../ui/button';
 import { Input } from '../ui/input';
@@ -14,9 +15,12 @@ import {
   TableHeader,
   TableRow,
 } from '../ui/table';
-import { GitCompare, Plus, Minus, FileText } from 'lucide-react';
+import { Alert, AlertDescription } from '../ui/alert';
+import { GitCompare, Plus, Minus, FileText, Loader2 } from 'lucide-react';
 import { Separator } from '../ui/separator';
-import { Page } from '../../lib/types';
+import { ApiError, compareRefs, resolveRepository } from '../../lib/api';
+import { getFriendlyApiError } from '../../lib/errors';
+import { CompareResponse, Page } from '../../lib/types';
 
 interface CompareProps {
   onNavigate: (page: Page) => void;
@@ -26,18 +30,56 @@ export function Compare({ onNavigate }: CompareProps) {
   const [repoUrl, setRepoUrl] = useState('');
   const [refA, setRefA] = useState('');
   const [refB, setRefB] = useState('');
-  const [hasCompared, setHasCompared] = useState(false);
+  const { setRepoData, setTreeItems, setTreeSource, setSelectedPaths } = useAppContext();
+  const [result, setResult] = useState<CompareResponse | null>(null);
+  const [defaultRef, setDefaultRef] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
 
-  const handleCompare = () => {
-    setHasCompared(true);
+  const handleCompare = async () => {
+    const base = refA.trim();
+    const head = refB.trim();
+    if (!repoUrl.trim() || !base || !head) {
+      setError('Укажите репозиторий и обе версии.');
+      return;
+    }
+    setLoading(true);
+    setError(null);
+    try {
+      const resolved = await resolveRepository(repoUrl.trim());
+      setDefaultRef(resolved.DefaultRef);
+      setResult(await compareRefs(resolved.Owner, resolved.Repo, base, head));
+    } catch (err) {
+      setResult(null);
+      if (err instanceof ApiError) {
+        setError(getFriendlyApiError(err, 'ru') ?? err.message);
+      } else {
+        setError('Не удалось сравнить версии.');
+      }
+    } finally {
+      setLoading(false);
+    }
   };
 
-  const changedFiles = [
-    { path: 'src/components/Button.tsx', added: 45, removed: 12, status: 'modified' },
-    { path: 'src/pages/Dashboard.tsx', added: 120, removed: 8, status: 'modified' },
-    { path: 'src/utils/helpers.ts', added: 30, removed: 0, status: 'added' },
-    { path: 'src/old/legacy.js', added: 0, removed: 200, status: 'removed' },
-  ];
+  // export of just the changed files: head becomes the export ref, base goes to diffBase
+  const handleExportDiff = () => {
+    if (!result) {
+      return;
+    }
+    setRepoData({
+      url: repoUrl.trim(),
+      owner: result.owner,
+      repo: result.repo,
+      defaultRef: defaultRef || result.head,
+      currentRef: result.head,
+      refs: [result.head],
+      diffBase: result.base,
+    });
+    setTreeItems([]);
+    setTreeSource(null);
+    setSelectedPaths([]);
+    onNavigate('export');
+  };
 
   return (
     <div className="p-8">
@@ -84,15 +126,21 @@ export function Compare({ onNavigate }: CompareProps) {
             </div>
           </div>
 
-          <Button onClick={handleCompare} className="gap-2">
-            <GitCompare className="h-4 w-4" />
+          <Button onClick={handleCompare} className="gap-2" disabled={loading}>
+            {loading ? <Loader2 className="h-4 w-4 animate-spin" /> : <GitCompare className="h-4 w-4" />}
             Сравнить
           </Button>
+
+          {error && (
+            <Alert variant="destructive">
+              <AlertDescription>{error}</AlertDescription>
+            </Alert>
+          )}
         </div>
       </Card>
 
       {/* Results */}
-      {!hasCompared ? (
+      {!result ? (
         <Card>
           <EmptyState
             icon={<GitCompare className="h-16 w-16" />}
@@ -108,34 +156,42 @@ export function Compare({ onNavigate }: CompareProps) {
             <div className="grid grid-cols-1 md:grid-cols-4 gap-6">
               <div>
                 <p className="text-muted-foreground mb-1">Всего файлов изменено</p>
-                <p className="text-foreground">24</p>
+                <p className="text-foreground">{result.files.length}</p>
               </div>
               <div>
                 <p className="text-muted-foreground mb-1">Добавлено строк</p>
                 <div className="flex items-center gap-2">
                   <Plus className="h-4 w-4 text-green-600" />
-                  <p className="text-foreground">+485</p>
+                  <p className="text-foreground">+{result.additions}</p>
                 </div>
               </div>
               <div>
                 <p className="text-muted-foreground mb-1">Удалено строк</p>
                 <div className="flex items-center gap-2">
                   <Minus className="h-4 w-4 text-red-600" />
-                  <p className="text-foreground">-220</p>
+                  <p className="text-foreground">-{result.deletions}</p>
                 </div>
               </div>
               <div>
                 <p className="text-muted-foreground mb-1">Чистое изменение</p>
-                <p className="text-foreground">+265</p>
+                <p className="text-foreground">
+                  {result.additions - result.deletions >= 0 ? '+' : ''}
+                  {result.additions - result.deletions}
+                </p>
               </div>
             </div>
+            {result.truncated && (
+              <p className="text-muted-foreground mt-4">
+                GitHub возвращает не больше 300 изменённых файлов — показаны первые из них.
+              </p>
+            )}
           </Card>
 
           {/* Changed Files */}
           <Card className="p-6">
             <div className="flex items-center justify-between mb-4">
               <h3 className="text-foreground">Изменённые файлы</h3>
-              <Button onClick={() => onNavigate('export-new')}>
+              <Button onClick={handleExportDiff} disabled={result.files.length === 0}>
                 Создать экспорт diff
               </Button>
             </div>
@@ -151,9 +207,11 @@ export function Compare({ onNavigate }: CompareProps) {
                 </TableRow>
               </TableHeader>
               <TableBody>
-                {changedFiles.map((file) => (
+                {result.files.map((file) => (
                   <TableRow key={file.path}>
-                    <TableCell className="font-mono">{file.path}</TableCell>
+                    <TableCell className="font-mono">
+                      {file.previousPath ? `${file.previousPath} → ${file.path}` : file.path}
+                    </TableCell>
                     <TableCell>
                       <Badge
                         variant={
@@ -168,17 +226,19 @@ export function Compare({ onNavigate }: CompareProps) {
                           ? 'Добавлен'
                           : file.status === 'removed'
                           ? 'Удалён'
+                          : file.status === 'renamed'
+                          ? 'Переименован'
                           : 'Изменён'}
                       </Badge>
                     </TableCell>
                     <TableCell>
-                      {file.added > 0 && (
-                        <span className="text-green-600">+{file.added}</span>
+                      {file.additions > 0 && (
+                        <span className="text-green-600">+{file.additions}</span>
                       )}
                     </TableCell>
                     <TableCell>
-                      {file.removed > 0 && (
-                        <span className="text-red-600">-{file.removed}</span>
+                      {file.deletions > 0 && (
+                        <span className="text-red-600">-{file.deletions}</span>
                       )}
                     </TableCell>
                     <TableCell className="text-right">
@@ -3,9 +3,11 @@ import {
   ArtifactsResponse,
   ArtifactFile,
   ArtifactMeta,
+  CompareResponse,
   ExportRequest,
   ExportResponse,
   JobStatusResponse,
+  MeResponse,
   PreviewRequest,
   PreviewResponse,
   ResolveResponse,
@@ -60,6 +62,7 @@ const handleError = async (response: Response): Promise<never> => {
 
 const request = async <T>(path: string, init?: RequestInit): Promise<T> => {
   const res = await fetch(buildUrl(path), {
+    credentials: 'include',
     headers: {
       'Content-Type': 'application/json',
       ...(init?.headers ?? {}),
@@ -75,6 +78,13 @@ const request = async <T>(path: string, init?: RequestInit): Promise<T> => {
   return (await res.json()) as T;
 };
 
+export const githubLoginUrl = (): string => buildUrl('/api/auth/github/login');
+
+export const fetchCurrentUser = (): Promise<MeResponse> => request<MeResponse>('/api/auth/me');
+
+export const logoutSession = (): Promise<void> =>
+  request<void>('/api/auth/logout', { method: 'POST' }).then(() => undefined);
+
 export const resolveRepository = (url: string): Promise<ResolveResponse> =>
   request<ResolveResponse>('/api/repo/resolve', {
     method: 'POST',
@@ -84,6 +94,11 @@ export const resolveRepository = (url: string): Promise<ResolveResponse> =>
 export const fetchRepositoryTree = (owner: string, repo: string, ref: string): Promise<TreeResponse> =>
   request<TreeResponse>(`/api/repo/tree?owner=${encodeURIComponent(owner)}&repo=${encodeURIComponent(repo)}&ref=${encodeURIComponent(ref)}`);
 
+export const compareRefs = (owner: string, repo: string, base: string, head: string): Promise<CompareResponse> =>
+  request<CompareResponse>(
+    `/api/repo/compare?owner=${encodeURIComponent(owner)}&repo=${encodeURIComponent(repo)}&base=${encodeURIComponent(base)}&head=${encodeURIComponent(head)}`,
+  );
+
 export const fetchFilePreview = ({ owner, repo, ref, path, maxKB = 256 }: PreviewRequest): Promise<PreviewResponse> =>
   request<PreviewResponse>('/api/preview', {
     method: 'POST',
@@ -21,6 +21,8 @@ const friendlyMessages: Record<Language, Record<string, string>> = {
     promptpack_second_pass_failed: 'Prompt pack generation failed on the second pass. Please retry.',
     too_large: 'Export exceeds the size limit. Narrow the selection or adjust filters.',
     unknown_format: 'Unknown export format.',
+    pr_required: 'PR pack needs a pull request. Paste a pull request URL.',
+    diff_format_unsupported: 'Changed-files export works with Markdown and text formats only.',
     invalid_payload: 'Internal exporter error.',
     user_cancelled: 'Export was cancelled.',
     context_cancelled: 'Export was cancelled by the system.',
@@ -48,6 +50,8 @@ const friendlyMessages: Record<Language, Record<string, string>> = {
     promptpack_second_pass_failed: 'Сбой на втором проходе генерации prompt pack. Повторите попытку.',
     too_large: 'Экспорт превышает лимит размера. Сузьте выбор или измените фильтры.',
     unknown_format: 'Неизвестный формат экспорта.',
+    pr_required: 'Для PR pack нужен pull request. Вставьте ссылку на PR.',
+    diff_format_unsupported: 'Экспорт изменений доступен только для Markdown и текстового формата.',
     invalid_payload: 'Внутренняя ошибка экспортера.',
     user_cancelled: 'Экспорт отменён пользователем.',
     context_cancelled: 'Экспорт отменён системой.',
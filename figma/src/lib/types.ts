@@ -25,6 +25,15 @@ export interface User {
   avatar?: string;
 }
 
+// GET /api/auth/me — пользователь, вошедший через GitHub OAuth
+export interface MeResponse {
+  id: string;
+  login: string;
+  name: string;
+  email: string;
+  avatarUrl: string;
+}
+
 export interface RepoData {
   url: string;
   owner: string;
@@ -32,14 +41,31 @@ export interface RepoData {
   defaultRef: string;
   currentRef: string;
   refs: string[];
+  path?: string;
+  pr?: number;
+  diffBase?: string;
   stats?: RepoStats;
   warnings?: string[];
 }
 
+export interface StatsBucket {
+  name: string;
+  files?: number;
+  bytes?: number;
+  tokens?: number;
+  percentage: number;
+}
+
 export interface RepoStats {
   files: number;
   sizeBytes: number;
-  languages: Array<{ name: string; percentage: number }>;
+  languages: StatsBucket[];
+  dirs?: number;
+  submodules?: number;
+  tokens?: number;
+  topDirs?: StatsBucket[];
+  kinds?: StatsBucket[];
+  largestFiles?: Array<{ path: string; size: number; kind: string }>;
 }
 
 export interface ResolveResponse {
@@ -47,18 +73,57 @@ export interface ResolveResponse {
   Repo: string;
   DefaultRef: string;
   Refs: string[];
+  RepoRef?: RepoRef;
+}
+
+export interface RepoRef {
+  owner: string;
+  repo: string;
+  ref?: string;
+  path?: string;
+  pr?: number;
+}
+
+export interface CompareFile {
+  path: string;
+  previousPath?: string;
+  status: 'added' | 'removed' | 'modified' | 'renamed' | 'copied' | 'changed' | 'unchanged';
+  additions: number;
+  deletions: number;
+  patch?: string;
+}
+
+export interface CompareResponse {
+  owner: string;
+  repo: string;
+  base: string;
+  head: string;
+  status: 'ahead' | 'behind' | 'diverged' | 'identical';
+  aheadBy: number;
+  behindBy: number;
+  totalCommits: number;
+  mergeBase: string;
+  files: CompareFile[];
+  additions: number;
+  deletions: number;
+  truncated: boolean;
 }
 
 export interface TreeItem {
   path: string;
   type: 'file' | 'dir';
   size: number;
+  kind?: 'text' | 'binary' | 'image' | 'archive' | 'lfs-pointer';
   lfs: boolean;
   submodule: boolean;
+  tokens?: number;
 }
 
 export interface TreeResponse {
   items: TreeItem[];
+  total?: number;
+  truncated?: boolean;
+  nextCursor?: string;
 }
 
 export interface PreviewRequest {
@@ -74,7 +139,7 @@ export interface PreviewResponse {
   truncated: boolean;
 }
 
-export type ExportFormat = 'zip' | 'md' | 'txt';
+export type ExportFormat = 'zip' | 'md' | 'txt' | 'pr';
 
 export interface ExportRequest {
   owner: string;
@@ -84,11 +149,26 @@ export interface ExportRequest {
   profile: string;
   includeGlobs: string[];
   excludeGlobs: string[];
+  filterPresets?: string[];
   secretScan: boolean;
   secretStrategy: string;
   tokenModel: string;
   maxBinarySizeMB: number;
   ttlHours: number;
+  rootPath?: string;
+  includeSubmodules?: boolean;
+  resolveLfs?: boolean;
+  pr?: number;
+  diffBase?: string;
+  diffHunks?: boolean;
+}
+
+export interface FilterPreset {
+  name: string;
+  description: string;
+  includeGlobs: string[];
+  excludeGlobs: string[];
+  builtin: boolean;
 }
 
 export interface ExportResponse {